@@ -16,6 +16,8 @@ version=$(head ./version)
 go fmt ./cli/...
 go vet -v ./cli/...
 go test -v ./cli/...
+# the storage backends are safe for concurrent use; the vendored BoltDB predates checkptr, which -race enables
+go test -race -gcflags=all=-d=checkptr=0 ./cli/storage/... ./cli/server/...
 go install # install locally to keep up-to-date
 
 # build for Linux Docker image
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 
+	"github.com/seanawilliams/udocs/cli/config"
 	"github.com/seanawilliams/udocs/cli/storage"
 	"github.com/seanawilliams/udocs/cli/udocs"
 	"github.com/spf13/cobra"
@@ -16,6 +18,9 @@ func Build() *cobra.Command {
 		Long: `
   udocs-build is for building a docs directory for local testing. It outputs rendered content in the
   directory '_docs'. README.md and SUMMARY.md files must exist in the root of the docs directory.
+
+  With --static, '_docs' instead contains a self-contained static site (full HTML pages, static assets
+  and a client-side search index) that can be hosted on any plain file server or object store.
 	`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := udocs.Validate(dir); err != nil {
@@ -23,16 +28,52 @@ func Build() *cobra.Command {
 				return
 			}
 
-			os.RemoveAll("_docs")
-			dao, err := storage.NewFileSystemDao("_docs", 0755, udocs.SearchPath())
-			exitOnError(err)
-			if err := udocs.Build(parseRouteFromSummary(), dir, dao); err != nil {
+			report, err := buildDocs(parseRouteFromSummary())
+			if err != nil {
 				fmt.Printf("Build failed: %v\n", err)
-				return
+				os.Exit(-1)
 			}
+
+			fmt.Printf("Build successful: %s\n", report)
 		},
 	}
 
 	setFlag(build, "dir")
+	setFlag(build, "static")
+	setFlag(build, "baseURL")
 	return build
 }
+
+// buildDocs builds the docs directory of route into _docs. It returns its errors rather than exiting, so
+// that the build directory of --static is cleaned up either way.
+func buildDocs(route string) (*udocs.BuildReport, error) {
+	os.RemoveAll("_docs")
+	buildDir := "_docs"
+	if static {
+		tmp, err := ioutil.TempDir("", "udocs")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		buildDir = tmp
+	}
+
+	// the search index of the local server is left alone, since the pages built here are not served by it
+	dao, err := storage.NewFileSystemDao(buildDir, 0755, "")
+	if err != nil {
+		return nil, err
+	}
+	defer dao.Close()
+	report, err := udocs.Build(route, dir, dao)
+	if err != nil {
+		return nil, err
+	}
+
+	if static {
+		opts := udocs.ExportOptions{BaseURL: baseURL, Params: config.LoadSettings().TemplateParams()}
+		if err := udocs.Export(route, dao, "_docs", opts); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
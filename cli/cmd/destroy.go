@@ -23,7 +23,7 @@ func Destroy() *cobra.Command {
 			settings := config.LoadSettings()
 			uri := fmt.Sprintf("%s:%s/api/%s", settings.EntryPoint, settings.Port, route)
 
-			if err := destroyDocs(uri); err != nil {
+			if err := destroyDocs(uri, settings.APIToken); err != nil {
 				fmt.Printf("Destroy failed: %v\n", err)
 				os.Exit(-1)
 			}
@@ -36,11 +36,12 @@ func Destroy() *cobra.Command {
 	return destroy
 }
 
-func destroyDocs(uri string) error {
+func destroyDocs(uri, token string) error {
 	req, err := http.NewRequest(http.MethodDelete, uri, nil)
 	if err != nil {
 		return fmt.Errorf("udocs.Destroy failed create HTTP request: %v", err)
 	}
+	setAuthorization(req, token)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -3,18 +3,23 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/seanawilliams/udocs/cli/config"
+	"github.com/seanawilliams/udocs/cli/storage"
 	"github.com/seanawilliams/udocs/cli/udocs"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dir, homePath, projectDir string
-	headless, reset           bool
+	dir, homePath, projectDir, baseURL string
+	headless, reset, static            bool
+	tokenName, guideVersion, format    string
+	remote                             bool
+	tokenRoutes                        []string
 )
 
 func setFlag(cmd *cobra.Command, flag string) {
@@ -27,6 +32,20 @@ func setFlag(cmd *cobra.Command, flag string) {
 		cmd.Flags().BoolVar(&headless, "headless", false, "Run UDocs server in headless mode")
 	case "reset":
 		cmd.Flags().BoolVar(&reset, "reset", false, "Reset local UDocs database")
+	case "static":
+		cmd.Flags().BoolVar(&static, "static", false, "Build a self-contained static site that can be hosted on any file server")
+	case "baseURL":
+		cmd.Flags().StringVar(&baseURL, "baseURL", "", "Base URL for links in a static site (links are relative when empty)")
+	case "tokenName":
+		cmd.Flags().StringVarP(&tokenName, "name", "n", "", "Name describing who or what uses the token")
+	case "tokenRoutes":
+		cmd.Flags().StringSliceVarP(&tokenRoutes, "route", "r", nil, "Route the token may publish and destroy (repeatable, or \"*\" for every route)")
+	case "guideVersion":
+		cmd.Flags().StringVar(&guideVersion, "version", "", "Version of the guide (defaults to the git tag of the current commit, if any)")
+	case "remote":
+		cmd.Flags().BoolVar(&remote, "remote", false, "Also check that links to remote URLs respond without an error")
+	case "format":
+		cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format of the problems found (text or json)")
 	case "homePath":
 		cmd.Flags().StringVarP(&homePath, "homePath", "p", "", "Path where the root of your docs is served")
 	default:
@@ -61,6 +80,25 @@ func parseRoute(settings *config.Settings) string {
 	return route
 }
 
+// newDao opens the storage backend configured by settings, as registered for the scheme of its URL.
+func newDao(settings config.Settings) (storage.Dao, error) {
+	return storage.Open(settings.Storage(), udocs.SearchPath())
+}
+
+// newStorageDao opens the storage backend configured by settings without its search index, for commands
+// that only manage stored data. They may run alongside a server holding the index, except on bolt://
+// storage, which only one process can open: opening it fails until the server is stopped.
+func newStorageDao(settings config.Settings) (storage.Dao, error) {
+	return storage.Open(settings.Storage(), "")
+}
+
+// setAuthorization authenticates req to the /api endpoints with the given API token, if any.
+func setAuthorization(req *http.Request, token string) {
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 func runTestCommand(cmd *cobra.Command, input string) error {
 	cmd.SetArgs(strings.Split(input, " "))
 	if err := cmd.Execute(); err != nil {
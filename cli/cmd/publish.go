@@ -5,11 +5,15 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/mholt/archiver"
 	"github.com/seanawilliams/udocs/cli/config"
+	"github.com/seanawilliams/udocs/cli/server"
 	"github.com/seanawilliams/udocs/cli/udocs"
 	"github.com/spf13/cobra"
 )
@@ -31,7 +35,25 @@ func Publish() *cobra.Command {
 			tarball := filepath.Join(os.TempDir(), "udocs", filepath.Base(dir)+".tar.gz")
 			defer os.Remove(tarball)
 
-			if err := archiver.TarGz.Make(tarball, []string{dir}); err != nil {
+			// the git history of the pages is sent along with them, for the server has no repository to read it from
+			sources := []string{dir}
+			if history, err := udocs.ReadGitHistory(dir); err == nil {
+				historyDir, err := ioutil.TempDir("", "udocs")
+				if err != nil {
+					fmt.Printf("Publish failed: %v\n", err)
+					os.Exit(-1)
+				}
+				defer os.RemoveAll(historyDir)
+
+				filename := filepath.Join(historyDir, udocs.GIT_HISTORY_JSON)
+				if err := history.Save(filename); err != nil {
+					fmt.Printf("Publish failed: %v\n", err)
+					os.Exit(-1)
+				}
+				sources = append(sources, filename)
+			}
+
+			if err := archiver.TarGz.Make(tarball, sources); err != nil {
 				fmt.Printf("Publish failed: %v\n", err)
 				os.Exit(-1)
 			}
@@ -44,21 +66,76 @@ func Publish() *cobra.Command {
 
 			route := parseRouteFromSummary()
 			uri := fmt.Sprintf("%s:%s/api/%s", settings.EntryPoint, settings.Port, route)
-			if err := publishDocs(uri, tmp); err != nil {
+
+			version := guideVersion
+			if version == "" {
+				version = git(dir, "describe", "--tags", "--exact-match")
+			}
+			if version != "" {
+				if err := udocs.ValidateVersion(version); err != nil {
+					fmt.Printf("Publish failed: %v\n", err)
+					os.Exit(-1)
+				}
+				uri += "?version=" + url.QueryEscape(version)
+			}
+
+			header := make(http.Header)
+			header.Set(server.PUBLISHER_HEADER, publisher(dir))
+			header.Set(server.COMMIT_HEADER, git(dir, "rev-parse", "HEAD"))
+
+			if err := publishDocs(uri, settings.APIToken, header, tmp); err != nil {
 				fmt.Printf("Publish failed: %v\n", err)
 				os.Exit(-1)
 			}
-			fmt.Printf("Successfully published guide to %s\n", uri)
+			if version != "" {
+				fmt.Printf("Successfully published version %s of guide to %s\n", version, uri)
+			} else {
+				fmt.Printf("Successfully published guide to %s\n", uri)
+			}
 		},
 	}
 
 	setFlag(publish, "dir")
+	setFlag(publish, "guideVersion")
 	return publish
 }
 
+// git runs a git command in dir and returns its output, or "" if the command fails (for instance when
+// dir is not in a git repository, or the commit checked out is not tagged).
+func git(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// publisher identifies whoever publishes dir in the publish history of the server.
+func publisher(dir string) string {
+	if name := git(dir, "config", "user.name"); name != "" {
+		return name
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return os.Getenv("USERNAME")
+}
+
 // Publish sends an HTTP request to the server to publish the documentation in the build directory.
-func publishDocs(uri string, r io.Reader) error {
-	resp, err := http.Post(uri, "application/octet-stream", r)
+func publishDocs(uri, token string, header http.Header, r io.Reader) error {
+	req, err := http.NewRequest(http.MethodPost, uri, r)
+	if err != nil {
+		return fmt.Errorf("udocs.Publish failed create HTTP request: %v", err)
+	}
+	for key := range header {
+		req.Header.Set(key, header.Get(key))
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	setAuthorization(req, token)
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("udocs.Publish failed to POST to %s: %v", uri, err)
 	}
@@ -13,6 +13,7 @@ import (
 
 func TestPublish(t *testing.T) {
 	settings := config.DefaultSettings()
+	settings.AllowUnauthenticated = true
 	dao := storage.NewMockDao("")
 	server.Tmpls = udocs.DefaultTemplateFiles()
 	s := httptest.NewServer(server.New(&settings, dao))
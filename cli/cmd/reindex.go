@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/seanawilliams/udocs/cli/config"
+	"github.com/seanawilliams/udocs/cli/udocs"
+	"github.com/spf13/cobra"
+)
+
+func Reindex() *cobra.Command {
+	reindex := &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the search index of a stopped UDocs server",
+		Long: `
+  udocs-reindex discards the search index of the local UDocs server, and rebuilds it from every guide
+  in its storage. The search index persists across restarts of 'udocs serve', so this is only needed
+  to recover a corrupted index. It does not work against a running server: the server holds the index
+  while it runs, so stop it first, or reindex fails with an error telling the index is in use.
+	`,
+		Run: func(cmd *cobra.Command, args []string) {
+			dao, err := newDao(config.LoadSettings())
+			if err != nil {
+				fmt.Printf("Reindex failed: %v\n", err)
+				os.Exit(-1)
+			}
+
+			if err := dao.ResetIndex(); err != nil {
+				fmt.Printf("Reindex failed: %v\n", err)
+				os.Exit(-1)
+			}
+
+			sidebar, err := udocs.LoadSidebar(dao)
+			if err != nil {
+				fmt.Printf("Reindex failed: unable to load sidebar: %v\n", err)
+				os.Exit(-1)
+			}
+
+			indexed, _, err := udocs.ReconcileSearchIndex(sidebar, dao)
+			if err != nil {
+				fmt.Printf("Reindex failed: %v\n", err)
+				os.Exit(-1)
+			}
+
+			fmt.Printf("Successfully reindexed %d pages\n", indexed)
+		},
+	}
+
+	return reindex
+}
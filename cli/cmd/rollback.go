@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/seanawilliams/udocs/cli/config"
+	"github.com/seanawilliams/udocs/cli/udocs"
+	"github.com/spf13/cobra"
+)
+
+func Rollback() *cobra.Command {
+	rollback := &cobra.Command{
+		Use:   "rollback [snapshot id]",
+		Short: "Roll a guide on a remote UDocs server back to an earlier publish",
+		Long: `
+  udocs-rollback rebuilds a guide on a remote UDocs server from one of its previously published snapshots.
+  Without a snapshot id, it lists the snapshots the server keeps for the guide, from the most recent to
+  the oldest. The current snapshot is marked with a '*'.
+  Servers storing their guides in mongodb:// or s3:// keep no snapshots, and refuse to roll back.
+	`,
+		Run: func(cmd *cobra.Command, args []string) {
+			route := parseRouteFromSummary()
+			settings := config.LoadSettings()
+			uri := fmt.Sprintf("%s:%s/api/%s", settings.EntryPoint, settings.Port, route)
+
+			if len(args) == 0 {
+				history, err := fetchHistory(uri+"/history", settings.APIToken)
+				if err != nil {
+					fmt.Printf("Rollback failed: %v\n", err)
+					os.Exit(-1)
+				}
+				for _, snapshot := range history.Snapshots {
+					current := " "
+					if snapshot.ID == history.Current {
+						current = "*"
+					}
+					fmt.Printf("%s %s\t%s\t%s\t%s\t%d files\t%s\n", current, snapshot.ID, snapshot.Published.Local().Format("2006-01-02 15:04:05"),
+						snapshot.Publisher, snapshot.Commit, snapshot.Files, snapshot.Version)
+				}
+				return
+			}
+
+			if err := rollbackDocs(uri+"/rollback/"+args[0], settings.APIToken); err != nil {
+				fmt.Printf("Rollback failed: %v\n", err)
+				os.Exit(-1)
+			}
+			fmt.Printf("Successfully rolled back guide for %s to snapshot %s\n", route, args[0])
+		},
+	}
+
+	setFlag(rollback, "dir")
+	return rollback
+}
+
+func fetchHistory(uri, token string) (*udocs.History, error) {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("udocs.Rollback failed create HTTP request: %v", err)
+	}
+	setAuthorization(req, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("udocs.Rollback failed to GET %s: %v", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("udocs.Rollback returned HTTP response: %s", string(body))
+	}
+
+	history := new(udocs.History)
+	if err := json.NewDecoder(resp.Body).Decode(history); err != nil {
+		return nil, fmt.Errorf("udocs.Rollback was unable to decode the history: %v", err)
+	}
+	return history, nil
+}
+
+func rollbackDocs(uri, token string) error {
+	req, err := http.NewRequest(http.MethodPost, uri, nil)
+	if err != nil {
+		return fmt.Errorf("udocs.Rollback failed create HTTP request: %v", err)
+	}
+	setAuthorization(req, token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("udocs.Rollback failed to POST to %s: %v", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("udocs.Rollback was unable to read the HTTP response body: %v", err)
+		}
+		return fmt.Errorf("udocs.Rollback returned HTTP response: %s", string(body))
+	}
+
+	return nil
+}
@@ -22,19 +22,16 @@ func Serve() *cobra.Command {
 			settings := config.LoadSettings()
 			addr := settings.BindAddr + ":" + settings.Port
 
-			var dao storage.Dao
-			var err error
-			if url := settings.MongoURL; url != "" {
-				dao, err = storage.NewMongoDBDao(url, udocs.SearchPath())
-			} else {
-				dao, err = storage.NewFileSystemDao(udocs.DeployPath(), 0755, udocs.SearchPath())
-			}
+			dao, err := newDao(settings)
 			exitOnError(err)
 
 			if reset {
 				if err := dao.Drop(); err != nil {
 					exitOnError(err)
 				}
+				if err := dao.ResetIndex(); err != nil {
+					exitOnError(err)
+				}
 			}
 
 			sidebar, _ := udocs.LoadSidebar(dao)
@@ -43,13 +40,10 @@ func Serve() *cobra.Command {
 				exitOnError(err)
 			}
 
-			if _, ok := dao.(*storage.MongoDBDao); ok {
-				for _, summary := range sidebar {
-					if err := udocs.UpdateSearchIndex(summary, dao); err != nil {
-						exitOnError(err)
-					}
-				}
-			}
+			// the search index persists across restarts, so only pages that changed while we were down are indexed
+			indexed, removed, err := udocs.ReconcileSearchIndex(sidebar, dao)
+			exitOnError(err)
+			log.Printf("Reconciled search index: %d pages indexed, %d pages removed", indexed, removed)
 
 			if headless {
 				s := server.New(&settings, dao)
@@ -66,17 +60,18 @@ func Serve() *cobra.Command {
 			settings.DocsDir = dir
 			localServer := server.New(&settings, dao)
 
-			if err := udocs.Build(settings.RootRoute+"/"+settings.DocsDir, settings.DocsDir, dao); err != nil {
+			// drafts are previewed locally, but left out when publishing
+			if _, err := udocs.BuildWithOptions(settings.RootRoute+"/"+settings.DocsDir, settings.DocsDir, dao, udocs.BuildOptions{Drafts: true}); err != nil {
 				exitOnError(err)
 			}
 
 			if settings.ProjectDir != "" {
-				if err := udocs.Build(settings.RootRoute+"/"+settings.ProjectDir, settings.ProjectDir, dao); err != nil {
+				if _, err := udocs.BuildWithOptions(settings.RootRoute+"/"+settings.ProjectDir, settings.ProjectDir, dao, udocs.BuildOptions{Drafts: true}); err != nil {
 					exitOnError(err)
 				}
 			}
 
-			go watchFiles(settings.RootRoute, dir, dao)
+			go watchFiles(settings.RootRoute+"/"+settings.DocsDir, dir, dao)
 			abs, err := filepath.Abs(dir)
 			if err != nil {
 				abs = dir
@@ -103,9 +98,11 @@ func watchFiles(route, dir string, dao storage.Dao) {
 	for {
 		select {
 		case <-watch:
-			if err := udocs.Build(route, dir, dao); err != nil {
+			report, err := udocs.BuildWithOptions(route, dir, dao, udocs.BuildOptions{Drafts: true})
+			if err != nil {
 				log.Fatalf("error: command.Serve: %v\n", err)
 			}
+			log.Printf("Rebuilt %s: %s", route, report)
 		case err := <-kill:
 			log.Fatalf("error: command.Serve: %v\n", err)
 		}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/seanawilliams/udocs/cli/config"
+	"github.com/seanawilliams/udocs/cli/storage"
+	"github.com/seanawilliams/udocs/cli/udocs"
+	"github.com/spf13/cobra"
+)
+
+func Token() *cobra.Command {
+	token := &cobra.Command{
+		Use:   "token",
+		Short: "Manage the API tokens of a UDocs server",
+		Long: `
+  udocs-token mints, revokes and lists the API tokens that authorize 'udocs publish' and 'udocs destroy'
+  on the local UDocs server. Each token is scoped to one or more routes. Until the first token is minted,
+  the /api endpoints of the server reject every request, unless UDOCS_ALLOW_UNAUTHENTICATED=true opens them
+  to anyone. Clients send their token from UDOCS_API_TOKEN. With bolt:// storage, which only one process
+  can open at a time, the server must be stopped while tokens are managed.
+	`,
+	}
+
+	mint := &cobra.Command{
+		Use:   "mint",
+		Short: "Mint a new API token",
+		Run: func(cmd *cobra.Command, args []string) {
+			dao, tokens := loadTokens("Mint")
+
+			tokens, secret, err := tokens.Mint(tokenName, tokenRoutes)
+			if err != nil {
+				fmt.Printf("Mint failed: %v\n", err)
+				os.Exit(-1)
+			}
+			if err := tokens.Save(dao); err != nil {
+				fmt.Printf("Mint failed: %v\n", err)
+				os.Exit(-1)
+			}
+
+			fmt.Printf("Minted token for %s. It will not be shown again:\n\n  UDOCS_API_TOKEN=%s\n", strings.Join(tokenRoutes, ", "), secret)
+		},
+	}
+	setFlag(mint, "tokenName")
+	setFlag(mint, "tokenRoutes")
+
+	revoke := &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API token",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				fmt.Println("Revoke failed: expected the id of the token to revoke")
+				os.Exit(-1)
+			}
+			dao, tokens := loadTokens("Revoke")
+
+			tokens, err := tokens.Revoke(args[0])
+			if err != nil {
+				fmt.Printf("Revoke failed: %v\n", err)
+				os.Exit(-1)
+			}
+			if err := tokens.Save(dao); err != nil {
+				fmt.Printf("Revoke failed: %v\n", err)
+				os.Exit(-1)
+			}
+
+			fmt.Println("Successfully revoked token " + args[0])
+			if len(tokens) == 0 {
+				fmt.Println("No tokens remain, so the /api endpoints reject every request until a token is minted, unless UDOCS_ALLOW_UNAUTHENTICATED=true.")
+			}
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the API tokens",
+		Run: func(cmd *cobra.Command, args []string) {
+			_, tokens := loadTokens("List")
+			for _, t := range tokens {
+				fmt.Printf("%s\t%s\t%s\t%s\n", t.ID, t.Created.Format("2006-01-02"), strings.Join(t.Routes, ","), t.Name)
+			}
+		},
+	}
+
+	token.AddCommand(list, mint, revoke)
+	return token
+}
+
+func loadTokens(action string) (storage.Dao, udocs.Tokens) {
+	dao, err := newStorageDao(config.LoadSettings())
+	if err != nil {
+		fmt.Printf("%s failed: %v\n", action, err)
+		os.Exit(-1)
+	}
+
+	tokens, err := udocs.LoadTokens(dao)
+	if err != nil {
+		fmt.Printf("%s failed: %v\n", action, err)
+		os.Exit(-1)
+	}
+	return dao, tokens
+}
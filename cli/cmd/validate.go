@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,8 +14,15 @@ func Validate() *cobra.Command {
 	var validate = &cobra.Command{
 		Use:   "validate",
 		Short: "Validate a docs directory",
-		Long:  `udocs-validate is a quick smoke-test that verifies the required contents of a docs directory.`,
+		Long: `udocs-validate verifies the required contents of a docs directory, and checks every link, image source and
+#anchor of its pages, as well as the entries of SUMMARY.md, and that its diagrams render. Links to remote URLs are
+only checked with --remote.`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if format != "text" && format != "json" {
+				fmt.Printf("Validation failed: unsupported format %q (expected text or json)\n", format)
+				os.Exit(-1)
+			}
+
 			abs, err := filepath.Abs(dir)
 			if err != nil {
 				fmt.Printf("Validation failed: unable to determine absolute path of docs directory: %v\n", err)
@@ -37,10 +45,43 @@ func Validate() *cobra.Command {
 				os.Exit(-1)
 			}
 
-			fmt.Println("Validation successful.")
+			problems, err := udocs.CheckLinks(dir, udocs.LinkCheckOptions{Remote: remote})
+			if err != nil {
+				fmt.Printf("Validation failed: %v\n", err)
+				os.Exit(-1)
+			}
+
+			if format == "json" {
+				data, err := json.MarshalIndent(struct {
+					Valid    bool                `json:"valid"`
+					Problems []udocs.LinkProblem `json:"problems"`
+				}{len(problems) == 0, problems}, "", "  ")
+				if err != nil {
+					fmt.Printf("Validation failed: %v\n", err)
+					os.Exit(-1)
+				}
+				fmt.Println(string(data))
+			} else {
+				for _, problem := range problems {
+					fmt.Println(problem)
+				}
+			}
+
+			if len(problems) > 0 {
+				if format == "text" {
+					fmt.Printf("Validation failed: found %d problems\n", len(problems))
+				}
+				os.Exit(-1)
+			}
+
+			if format == "text" {
+				fmt.Println("Validation successful.")
+			}
 		},
 	}
 
 	setFlag(validate, "dir")
+	setFlag(validate, "remote")
+	setFlag(validate, "format")
 	return validate
 }
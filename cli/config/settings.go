@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/seanawilliams/udocs/cli/udocs"
@@ -20,11 +21,18 @@ type Settings struct {
 	Email             string
 	Routes            []string
 	MongoURL          string
+	StorageURL        string
 	QuipAccessToken   string
-	PrimaryColor      string
-	HomePath          string
-	ProjectDir        string
-	DocsDir           string
+	APIToken          string
+	// AllowUnauthenticated opens publishing and destroying guides to anyone until the first API token
+	// is minted, for trying out a server. Otherwise a server without tokens rejects every API request.
+	AllowUnauthenticated bool
+	HistorySize          int
+	PrimaryColor         string
+	HighlightTheme       string
+	HomePath             string
+	ProjectDir           string
+	DocsDir              string
 }
 
 func LoadSettings() Settings {
@@ -39,6 +47,8 @@ func DefaultSettings() Settings {
 		SearchPlaceholder: "Search",
 		Routes:            []string{},
 		PrimaryColor:      "#5ca616",
+		HighlightTheme:    udocs.DEFAULT_HIGHLIGHT_THEME,
+		HistorySize:       udocs.DEFAULT_HISTORY_SIZE,
 		HomePath:          "",
 		ProjectDir:        "",
 		DocsDir:           "",
@@ -53,25 +63,63 @@ func (s Settings) String() string {
 	buf.WriteString("\nUDOCS_ROOT_ROUTE=" + s.RootRoute)
 	buf.WriteString("\nUDOCS_ROUTES=" + sliceToString(s.Routes))
 	buf.WriteString("\nUDOCS_MONGO_URL=" + s.MongoURL)
+	buf.WriteString("\nUDOCS_STORAGE_URL=" + s.StorageURL)
 	buf.WriteString("\nUDOCS_ORGANIZATION=" + s.Organization)
 	buf.WriteString("\nUDOCS_QUIP_ACCESS_TOKEN=" + s.QuipAccessToken)
+	buf.WriteString("\nUDOCS_API_TOKEN=" + maskSecret(s.APIToken))
+	buf.WriteString("\nUDOCS_ALLOW_UNAUTHENTICATED=" + strconv.FormatBool(s.AllowUnauthenticated))
 	buf.WriteString("\nUDOCS_PRIMARY_COLOR=" + s.PrimaryColor)
+	buf.WriteString("\nUDOCS_HIGHLIGHT_THEME=" + s.HighlightTheme)
+	buf.WriteString("\nUDOCS_HISTORY_SIZE=" + strconv.Itoa(s.HistorySize))
 	return buf.String()
 }
 
+// Storage returns the URL of the storage backend: UDOCS_STORAGE_URL, or else the MongoDB URL, if any, or
+// else the local deploy directory.
+func (s Settings) Storage() string {
+	if s.StorageURL != "" {
+		return s.StorageURL
+	}
+	if s.MongoURL != "" {
+		return s.MongoURL
+	}
+	return "file://" + udocs.DeployPath()
+}
+
+// TemplateParams returns the parameters used to render the HTML templates for these settings.
+func (s Settings) TemplateParams() map[string]interface{} {
+	m := make(map[string]interface{})
+	m["entrypoint"] = s.EntryPoint
+	m["organization"] = s.Organization
+	m["email"] = s.Email
+	m["search_placeholder"] = s.SearchPlaceholder
+	m["color"] = s.PrimaryColor
+	m["highlight_theme"] = udocs.DEFAULT_HIGHLIGHT_THEME
+	if udocs.IsHighlightTheme(s.HighlightTheme) {
+		m["highlight_theme"] = s.HighlightTheme
+	}
+	m["homePath"] = s.HomePath
+	return m
+}
+
 func EnvVars(settings Settings) Settings {
 	return Settings{
-		EntryPoint:        loadEnvVar("UDOCS_ENTRY_POINT", settings.EntryPoint),
-		BindAddr:          loadEnvVar("UDOCS_BIND_ADDR", settings.BindAddr),
-		Port:              loadEnvVar("UDOCS_PORT", settings.Port),
-		RootRoute:         loadEnvVar("UDOCS_ROOT_ROUTE", settings.RootRoute),
-		Organization:      loadEnvVar("UDOCS_ORGANIZATION", settings.Organization),
-		Email:             loadEnvVar("UDOCS_EMAIL", settings.Email),
-		SearchPlaceholder: loadEnvVar("UDOCS_SEARCH_PLACEHOLDER", settings.SearchPlaceholder),
-		Routes:            strings.Split(loadEnvVar("UDOCS_ROUTES", sliceToString(settings.Routes)), ","),
-		MongoURL:          loadEnvVar("UDOCS_MONGO_URL", settings.MongoURL),
-		QuipAccessToken:   loadEnvVar("UDOCS_QUIP_ACCESS_TOKEN", settings.QuipAccessToken),
-		PrimaryColor:      loadEnvVar("UDOCS_PRIMARY_COLOR", settings.PrimaryColor),
+		EntryPoint:           loadEnvVar("UDOCS_ENTRY_POINT", settings.EntryPoint),
+		BindAddr:             loadEnvVar("UDOCS_BIND_ADDR", settings.BindAddr),
+		Port:                 loadEnvVar("UDOCS_PORT", settings.Port),
+		RootRoute:            loadEnvVar("UDOCS_ROOT_ROUTE", settings.RootRoute),
+		Organization:         loadEnvVar("UDOCS_ORGANIZATION", settings.Organization),
+		Email:                loadEnvVar("UDOCS_EMAIL", settings.Email),
+		SearchPlaceholder:    loadEnvVar("UDOCS_SEARCH_PLACEHOLDER", settings.SearchPlaceholder),
+		Routes:               strings.Split(loadEnvVar("UDOCS_ROUTES", sliceToString(settings.Routes)), ","),
+		MongoURL:             loadEnvVar("UDOCS_MONGO_URL", settings.MongoURL),
+		StorageURL:           loadEnvVar("UDOCS_STORAGE_URL", settings.StorageURL),
+		QuipAccessToken:      loadEnvVar("UDOCS_QUIP_ACCESS_TOKEN", settings.QuipAccessToken),
+		APIToken:             loadEnvVar("UDOCS_API_TOKEN", settings.APIToken),
+		AllowUnauthenticated: loadEnvBool("UDOCS_ALLOW_UNAUTHENTICATED", settings.AllowUnauthenticated),
+		PrimaryColor:         loadEnvVar("UDOCS_PRIMARY_COLOR", settings.PrimaryColor),
+		HighlightTheme:       loadEnvVar("UDOCS_HIGHLIGHT_THEME", settings.HighlightTheme),
+		HistorySize:          loadEnvInt("UDOCS_HISTORY_SIZE", settings.HistorySize),
 	}
 }
 
@@ -83,6 +131,28 @@ func loadEnvVar(key, defaultValue string) string {
 	return value
 }
 
+func loadEnvInt(key string, defaultValue int) int {
+	return parseInt(os.Getenv(key), defaultValue)
+}
+
+func loadEnvBool(key string, defaultValue bool) bool {
+	return parseBool(os.Getenv(key), defaultValue)
+}
+
+func parseBool(value string, defaultValue bool) bool {
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return defaultValue
+}
+
+func parseInt(value string, defaultValue int) int {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
+
 func Conf() Settings {
 	f, err := os.Open(udocs.ConfPath())
 	if err != nil {
@@ -94,17 +164,22 @@ func Conf() Settings {
 
 func loadFromMap(m map[string]string) Settings {
 	return Settings{
-		EntryPoint:        m["UDOCS_ENTRY_POINT"],
-		BindAddr:          m["UDOCS_BIND_ADDR"],
-		Port:              m["UDOCS_PORT"],
-		RootRoute:         m["UDOCS_ROOT_ROUTE"],
-		Organization:      m["UDOCS_ORGANIZATION"],
-		Email:             m["UDOCS_EMAIL"],
-		SearchPlaceholder: m["UDOCS_SEARCH_PLACEHOLDER"],
-		Routes:            strings.Split(m["UDOCS_ROUTES"], ","),
-		MongoURL:          m["UDOCS_MONGO_URL"],
-		QuipAccessToken:   m["UDOCS_QUIP_ACCESS_TOKEN"],
-		PrimaryColor:      m["UDOCS_PRIMARY_COLOR"],
+		EntryPoint:           m["UDOCS_ENTRY_POINT"],
+		BindAddr:             m["UDOCS_BIND_ADDR"],
+		Port:                 m["UDOCS_PORT"],
+		RootRoute:            m["UDOCS_ROOT_ROUTE"],
+		Organization:         m["UDOCS_ORGANIZATION"],
+		Email:                m["UDOCS_EMAIL"],
+		SearchPlaceholder:    m["UDOCS_SEARCH_PLACEHOLDER"],
+		Routes:               strings.Split(m["UDOCS_ROUTES"], ","),
+		MongoURL:             m["UDOCS_MONGO_URL"],
+		StorageURL:           m["UDOCS_STORAGE_URL"],
+		QuipAccessToken:      m["UDOCS_QUIP_ACCESS_TOKEN"],
+		APIToken:             m["UDOCS_API_TOKEN"],
+		AllowUnauthenticated: parseBool(m["UDOCS_ALLOW_UNAUTHENTICATED"], false),
+		PrimaryColor:         m["UDOCS_PRIMARY_COLOR"],
+		HighlightTheme:       m["UDOCS_HIGHLIGHT_THEME"],
+		HistorySize:          parseInt(m["UDOCS_HISTORY_SIZE"], udocs.DEFAULT_HISTORY_SIZE),
 	}
 }
 
@@ -120,6 +195,17 @@ func toMap(r io.Reader) map[string]string {
 	return conf
 }
 
+// maskSecret hides all but the id of an API token, so that settings can be printed safely.
+func maskSecret(secret string) string {
+	if i := strings.Index(secret, "."); i >= 0 {
+		return secret[:i+1] + "********"
+	}
+	if secret != "" {
+		return "********"
+	}
+	return ""
+}
+
 func sliceToString(slice []string) string {
 	buf := new(bytes.Buffer)
 	for i, v := range slice {
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,29 +49,93 @@ func (s *Server) rootDomainRedirect(ctx context.Context, w http.ResponseWriter,
 }
 
 func (s *Server) pageHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	data, err := s.dao.Fetch(r.URL.Path)
+	if strings.Trim(r.URL.Path, "/") == udocs.TOKENS_JSON {
+		logAndWriteError(w, r, http.StatusNotFound, "unable to fetch data", fmt.Errorf("%s is not public", udocs.TOKENS_JSON))
+		return
+	}
+
+	// pages of versioned guides are served from /:route/:version/*, where the version may be "latest"
+	sidebar, sidebarErr := udocs.LoadSidebar(s.dao)
+	path, route, version := sidebar.ResolveVersion(r.URL.Path)
+
+	// assets are streamed, with support for Range and conditional requests
+	ext := filepath.Ext(r.URL.Path)
+	isAsset := ext != "" && ext != ".html" && ext != ".quip"
+	var data []byte
+	var err error
+	if isAsset {
+		err = serveObject(w, r, s.dao, path)
+	} else {
+		data, err = s.dao.Fetch(path)
+	}
 	if err != nil {
+		// pages may list the paths they were moved from in their front matter
+		if target, ok := udocs.FindMetadata(path, s.dao).Redirect(path); ok {
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			logResponse(http.StatusMovedPermanently, r)
+			return
+		}
 		logAndWriteError(w, r, http.StatusNotFound, "unable to fetch data", err)
 		return
 	}
+	if isAsset {
+		return
+	}
 
 	if r.URL.Query().Get("ajax") == "true" {
+		// pages are wrapped as they are in the document, footer included, since they replace its content
+		if filepath.Ext(r.URL.Path) == ".html" {
+			buf := new(bytes.Buffer)
+			if err := s.tmpl.WithParameter("meta", udocs.FindMetadata(path, s.dao)[path]).Execute(buf, "inner", data); err != nil {
+				logAndWriteError(w, r, http.StatusInternalServerError, "failed to execute html template", err)
+				return
+			}
+			data = buf.Bytes()
+		}
 		logAndWriteBinaryResponse(w, r, http.StatusOK, data)
 		return
 	}
 
-	if ext := filepath.Ext(r.URL.Path); ext != "" && ext != ".html" && ext != ".quip" {
-		logAndWriteBinaryResponse(w, r, http.StatusOK, data)
+	isPage := ext == ".html" || ext == ".quip"
+
+	// the table of contents of the page, for the "On this page" panel of ajax navigation
+	if r.URL.Query().Get("toc") == "json" && isPage {
+		toc, err := udocs.ExtractTOC(data)
+		if err != nil {
+			logAndWriteError(w, r, http.StatusInternalServerError, "failed to extract table of contents", err)
+			return
+		}
+		logAndWriteJSON(w, r, http.StatusOK, toc)
 		return
 	}
 
-	sidebar, err := udocs.LoadSidebar(s.dao)
+	if sidebarErr != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "failed to load sidebar", sidebarErr)
+		return
+	}
+
+	if sidebar, err = sidebar.WithVersion(route, version, s.dao); err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "failed to load sidebar for version "+version, err)
+		return
+	}
+
+	name, meta := "document", udocs.FindMetadata(path, s.dao)[path]
+	if meta != nil && meta.Template != "" {
+		if s.tmpl.Has(meta.Template) {
+			name = meta.Template
+		} else {
+			log.Printf("server.pageHandler: %s asks for undefined template %q, using %q", path, meta.Template, name)
+		}
+	}
+
+	toc, err := udocs.ExtractTOC(data)
 	if err != nil {
-		logAndWriteError(w, r, http.StatusInternalServerError, "failed to load sidebar", err)
+		logAndWriteError(w, r, http.StatusInternalServerError, "failed to extract table of contents", err)
 		return
 	}
 
-	if err := s.tmpl.WithParameter("sidebar", sidebar).Execute(w, "document", data); err != nil {
+	tmpl := s.tmpl.WithParameter("sidebar", sidebar).WithParameter("guide", sidebar.Guide(route)).WithParameter("meta", meta).WithParameter("toc", toc)
+	if err := tmpl.Execute(w, name, data); err != nil {
 		logAndWriteError(w, r, http.StatusInternalServerError, "failed to execute html template", err)
 		return
 	}
@@ -95,38 +161,145 @@ func (s *Server) quipBlobHandler(ctx context.Context, w http.ResponseWriter, r *
 
 func (s *Server) updateHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	route := ctx.Value("route").(string)
-	dest := filepath.Join(udocs.BuildPath(), fmt.Sprintf("%s_%d", route, time.Now().Unix()))
+	if !validRoute(w, r, route) || !s.authorize(w, r, route) {
+		return
+	}
 
-	docs, err := extractTarball(r.Body, dest)
+	version := r.URL.Query().Get("version")
+	if version != "" {
+		if err := udocs.ValidateVersion(version); err != nil {
+			logAndWriteError(w, r, http.StatusBadRequest, "server.updateHandler invalid version", err)
+			return
+		}
+	}
+
+	snapshot, err := udocs.NewSnapshot(route)
 	if err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.updateHandler unable to create snapshot", err)
+		return
+	}
+	snapshot.Version = version
+	snapshot.Publisher = r.Header.Get(PUBLISHER_HEADER)
+	if snapshot.Publisher == "" {
+		snapshot.Publisher = r.RemoteAddr
+	}
+	snapshot.Commit = r.Header.Get(COMMIT_HEADER)
+
+	// the tarball is kept as a snapshot of the route once it is built, and discarded otherwise
+	tarball := udocs.SnapshotPath(route, snapshot.ID)
+	dest := filepath.Join(udocs.BuildPath(), fmt.Sprintf("%s_%s", route, snapshot.ID))
+	defer os.RemoveAll(dest)
+
+	docs, err := extractTarball(r.Body, tarball, dest)
+	if err != nil {
+		os.Remove(tarball)
 		logAndWriteError(w, r, http.StatusBadRequest, "server.updateHandler unable to extract tarball", err)
 		return
 	}
 
 	if err := udocs.Validate(docs); err != nil {
+		os.Remove(tarball)
 		logAndWriteError(w, r, http.StatusBadRequest, "server.updateHandler failed to validate docs directory", err)
 		return
 	}
 
-	if err := udocs.Build(route, docs, s.dao); err != nil {
+	report, err := buildTarball(route, version, docs, s.dao)
+	if err != nil {
+		os.Remove(tarball)
 		logAndWriteError(w, r, http.StatusBadRequest, "server.updateHandler unable to build docs", err)
 		return
 	}
+	log.Printf("server.updateHandler built route %s: %s", filepath.Join(route, version), report)
 
-	href := fmt.Sprintf("%s:%s/%s", s.settings.EntryPoint, s.settings.Port, route)
+	// snapshots are only kept when the storage is local to the server, as they are
+	if !storage.IsLocal(s.settings.Storage()) {
+		os.Remove(tarball)
+	} else {
+		snapshot.Files = udocs.CountFiles(docs)
+		if err := udocs.UpdateHistory(route, func(history *udocs.History) error {
+			history.Record(snapshot, s.settings.HistorySize)
+			return nil
+		}); err != nil {
+			log.Printf("error: server.updateHandler failed to record snapshot %s of route %s: %v", snapshot.ID, route, err)
+		}
+	}
+
+	href := fmt.Sprintf("%s:%s/%s", s.settings.EntryPoint, s.settings.Port, filepath.Join(route, version))
 	logAndWriteJSONResponse(w, r, http.StatusCreated, http.StatusText(http.StatusCreated), href)
 }
 
-func (s *Server) destroyHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+func (s *Server) historyHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	route := ctx.Value("route").(string)
+	if !validRoute(w, r, route) || !s.authorize(w, r, route) || !s.keepsHistory(w, r) {
+		return
+	}
 
-	sidebar, err := udocs.LoadSidebar(s.dao)
+	history, err := udocs.LoadHistory(route)
+	if err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.historyHandler failed to load history", err)
+		return
+	}
+
+	logAndWriteJSON(w, r, http.StatusOK, history)
+}
+
+// rollbackHandler rebuilds a route from one of its snapshots. The snapshot becomes the current snapshot
+// of the route, but the history is otherwise unchanged, so a rollback can itself be rolled back.
+func (s *Server) rollbackHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	route := ctx.Value("route").(string)
+	id := ctx.Value("id").(string)
+	if !validRoute(w, r, route) || !s.authorize(w, r, route) || !s.keepsHistory(w, r) {
+		return
+	}
+
+	history, err := udocs.LoadHistory(route)
+	if err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.rollbackHandler failed to load history", err)
+		return
+	}
+	snapshot, ok := history.Find(id)
+	if !ok {
+		logAndWriteError(w, r, http.StatusNotFound, "server.rollbackHandler unknown snapshot", fmt.Errorf("route %s has no snapshot %s", route, id))
+		return
+	}
+
+	dest := filepath.Join(udocs.BuildPath(), fmt.Sprintf("%s_%s_rollback_%d", route, id, time.Now().UnixNano()))
+	defer os.RemoveAll(dest)
+
+	docs, err := openTarball(udocs.SnapshotPath(route, id), dest)
 	if err != nil {
-		logAndWriteError(w, r, http.StatusInternalServerError, "server.destroyHandler failed to load sidebar", err)
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.rollbackHandler unable to extract snapshot", err)
+		return
+	}
+
+	report, err := buildTarball(route, snapshot.Version, docs, s.dao)
+	if err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.rollbackHandler unable to build docs", err)
+		return
+	}
+	log.Printf("server.rollbackHandler rolled route %s back to snapshot %s: %s", route, id, report)
+
+	if err := udocs.UpdateHistory(route, func(history *udocs.History) error {
+		history.Current = id
+		return nil
+	}); err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.rollbackHandler failed to update history", err)
 		return
 	}
 
-	if err := updateSidebar(sidebar, udocs.Summary{Route: route, Header: ""}, s.dao); err != nil {
+	logAndWriteJSON(w, r, http.StatusOK, snapshot)
+}
+
+func (s *Server) destroyHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	route := ctx.Value("route").(string)
+	if !validRoute(w, r, route) || !s.authorize(w, r, route) {
+		return
+	}
+	defer udocs.LockRoute(route)()
+
+	if err := udocs.UpdateSidebar(s.dao, func(sidebar udocs.Sidebar) (udocs.Sidebar, error) {
+		return replaceSummary(sidebar, udocs.Summary{Route: route, Header: ""}), nil
+	}); err != nil {
 		logAndWriteError(w, r, http.StatusBadRequest, "server.destroyHandler failed remove resource from sidebar", err)
 		return
 	}
@@ -140,11 +313,11 @@ func (s *Server) destroyHandler(ctx context.Context, w http.ResponseWriter, r *h
 }
 
 func (s *Server) searchHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query().Get("q")
-	queryResult, err := s.dao.Query(q)
+	req := parseQueryRequest(r)
+	queryResult, err := s.dao.Query(req)
 	if err != nil {
-		queryResult = &storage.QueryResult{}
-		return
+		log.Printf("error: server.searchHandler failed to query %q: %v", req.Phrase, err)
+		queryResult = &storage.QueryResult{Phrase: req.Phrase, Routes: req.Routes}
 	}
 
 	sidebar, err := udocs.LoadSidebar(s.dao)
@@ -153,7 +326,7 @@ func (s *Server) searchHandler(ctx context.Context, w http.ResponseWriter, r *ht
 		return
 	}
 
-	tmpl := s.tmpl.WithParameter("query_result", queryResult).WithParameter("sidebar", sidebar)
+	tmpl := s.tmpl.WithParameter("query_result", queryResult).WithParameter("sidebar", sidebar).WithParameter("guide", nil).WithParameter("meta", nil).WithParameter("toc", nil)
 	if err := tmpl.Execute(w, "search", nil); err != nil {
 		logAndWriteError(w, r, http.StatusInternalServerError, "server.pageHandler failed to execute template", err)
 		return
@@ -162,11 +335,107 @@ func (s *Server) searchHandler(ctx context.Context, w http.ResponseWriter, r *ht
 	logResponse(http.StatusOK, r)
 }
 
-func extractTarball(rc io.ReadCloser, dest string) (string, error) {
-	dir := filepath.Join(udocs.ArchivePath(), filepath.Base(dest))
-	os.MkdirAll(dir, 0755)
-	tarball := filepath.Join(udocs.ArchivePath(), filepath.Base(dest), "docs.tar.gz")
-	defer os.Remove(tarball)
+func (s *Server) apiSearchHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	queryResult, err := s.dao.Query(parseQueryRequest(r))
+	if err != nil {
+		logAndWriteError(w, r, http.StatusBadRequest, "server.apiSearchHandler failed to execute query", err)
+		return
+	}
+
+	logAndWriteJSON(w, r, http.StatusOK, queryResult)
+}
+
+// validRoute checks the route of an /api request, which the router unescapes, and writes an error
+// response when it is not the name of a guide, as with ../alpha sent as %2e%2e%2falpha.
+func validRoute(w http.ResponseWriter, r *http.Request, route string) bool {
+	if err := udocs.ValidateRoute(route); err != nil {
+		logAndWriteError(w, r, http.StatusBadRequest, "server.validRoute invalid route", err)
+		return false
+	}
+	return true
+}
+
+// keepsHistory checks that the server keeps the publish history of its routes, and writes an error
+// response otherwise. Snapshots are kept on the local disk of the server, which servers sharing a
+// mongodb:// or s3:// storage do not share, so there is no history with those backends.
+func (s *Server) keepsHistory(w http.ResponseWriter, r *http.Request) bool {
+	if !storage.IsLocal(s.settings.Storage()) {
+		err := fmt.Errorf("the publish history is only kept with the file:// and bolt:// storage backends, since snapshots are stored on the local disk of the server")
+		logAndWriteError(w, r, http.StatusNotImplemented, "server.keepsHistory history unavailable", err)
+		return false
+	}
+	return true
+}
+
+// authorize checks that the request carries a bearer token scoped to route, and writes an error response
+// when it does not. Until the first token is minted, every request is rejected, or authorized when the
+// server allows unauthenticated requests.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, route string) bool {
+	tokens, err := udocs.LoadTokens(s.dao)
+	if err != nil {
+		logAndWriteError(w, r, http.StatusInternalServerError, "server.authorize failed to load API tokens", err)
+		return false
+	}
+	if len(tokens) == 0 {
+		if s.settings.AllowUnauthenticated {
+			log.Printf("warning: no API tokens have been minted, %s %s is unauthenticated", r.Method, r.URL.Path)
+			return true
+		}
+		logAndWriteError(w, r, http.StatusUnauthorized, "server.authorize no API tokens have been minted, mint one with `udocs token mint`", fmt.Errorf("no API tokens for route %s", route))
+		return false
+	}
+
+	secret := bearerToken(r)
+	if secret == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="udocs"`)
+		logAndWriteError(w, r, http.StatusUnauthorized, "server.authorize missing API token", fmt.Errorf("no bearer token for route %s", route))
+		return false
+	}
+	if !tokens.Authorize(secret, route) {
+		logAndWriteError(w, r, http.StatusForbidden, "server.authorize API token is not valid for this route", fmt.Errorf("token rejected for route %s", route))
+		return false
+	}
+	return true
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return ""
+}
+
+// parseQueryRequest reads a search from the query parameters q, from, size, route and version. The route
+// and version parameters may be repeated, or hold a comma-separated list of values.
+func parseQueryRequest(r *http.Request) storage.QueryRequest {
+	params := r.URL.Query()
+	req := storage.QueryRequest{Phrase: params.Get("q")}
+	req.From, _ = strconv.Atoi(params.Get("from"))
+	req.Size, _ = strconv.Atoi(params.Get("size"))
+	req.Routes = splitParams(params["route"])
+	req.Versions = splitParams(params["version"])
+	req.Tags = splitParams(params["tag"])
+	req.Owners = splitParams(params["owner"])
+	return req
+}
+
+func splitParams(values []string) []string {
+	var params []string
+	for _, value := range values {
+		for _, param := range strings.Split(value, ",") {
+			if param = strings.TrimSpace(param); param != "" {
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
+
+// extractTarball saves the tarball read from rc to the given path, and extracts it into dest. It returns
+// the path of the extracted docs directory.
+func extractTarball(rc io.ReadCloser, tarball, dest string) (string, error) {
+	defer rc.Close()
 
 	tmp, err := os.Create(tarball)
 	if err != nil {
@@ -174,10 +443,17 @@ func extractTarball(rc io.ReadCloser, dest string) (string, error) {
 	}
 
 	if _, err := io.Copy(tmp, rc); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("api.extractTarball failed to copy tarball: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
 		return "", fmt.Errorf("api.extractTarball failed to copy tarball: %v", err)
 	}
-	rc.Close()
 
+	return openTarball(tarball, dest)
+}
+
+func openTarball(tarball, dest string) (string, error) {
 	if err := os.MkdirAll(dest, 0755); err != nil {
 		return "", fmt.Errorf("api.extractTarball failed to make dest directory: %v", err)
 	}
@@ -189,6 +465,16 @@ func extractTarball(rc io.ReadCloser, dest string) (string, error) {
 	return filepath.Join(dest, "docs"), nil
 }
 
+// buildTarball builds the docs directory extracted by openTarball, with the git history published next to
+// it, if any. There is no repository to read the history from on the server.
+func buildTarball(route, version, docs string, dao storage.Dao) (*udocs.BuildReport, error) {
+	history, err := udocs.LoadGitHistory(filepath.Join(filepath.Dir(docs), udocs.GIT_HISTORY_JSON))
+	if err != nil {
+		return nil, err
+	}
+	return udocs.BuildWithOptions(route, docs, dao, udocs.BuildOptions{Version: version, History: history})
+}
+
 func generalizeStringMap(m map[string]string) map[string]interface{} {
 	generalized := make(map[string]interface{})
 	for k, v := range m {
@@ -198,19 +484,42 @@ func generalizeStringMap(m map[string]string) map[string]interface{} {
 }
 
 func writeBinaryResponse(w http.ResponseWriter, r *http.Request, code int, data []byte) {
-	if strings.HasSuffix(r.URL.Path, "css") {
-		w.Header().Set("content-type", "text/css")
-	} else if strings.HasSuffix(r.URL.Path, "js") {
-		w.Header().Set("content-type", "text/javascript")
-	} else if strings.HasSuffix(r.URL.Path, "html") {
-		w.Header().Set("content-type", "text/html")
-	} else if strings.HasSuffix(r.URL.Path, ".css.map") {
-		w.Header().Set("content-type", "text/css")
-	}
+	w.Header().Set("content-type", storage.ContentType(r.URL.Path, data))
 	w.WriteHeader(code)
 	w.Write(data)
 }
 
+// serveObject streams the entry id of dao, which http.ServeContent answers Range and conditional requests
+// from, using the modification time and ETag stored with it.
+func serveObject(w http.ResponseWriter, r *http.Request, dao storage.Dao, id string) error {
+	object, err := storage.Objects(dao).Get(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	info := object.Info()
+	w.Header().Set("content-type", info.ContentType)
+	if info.ETag != "" {
+		w.Header().Set("etag", info.ETag)
+	}
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	http.ServeContent(rec, r, id, info.ModTime, object)
+	logResponse(rec.code, r)
+	return nil
+}
+
+// statusRecorder records the status code of a response, for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	rec.code = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
 func logAndWriteBinaryResponse(w http.ResponseWriter, r *http.Request, code int, data []byte) {
 	writeBinaryResponse(w, r, code, data)
 	logResponse(code, r)
@@ -233,6 +542,15 @@ func logAndWriteJSONResponse(w http.ResponseWriter, r *http.Request, code int, m
 	logResponse(code, r)
 }
 
+func logAndWriteJSON(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("error: logAndWriteJSON failed writing data: %v", err)
+	}
+	logResponse(code, r)
+}
+
 func logAndWriteError(w http.ResponseWriter, r *http.Request, code int, msg string, err error) {
 	log.Printf("error: %s: %v", msg, err)
 	http.Error(w, fmt.Sprintf("%d %s\n%s\n", code, http.StatusText(code), msg), code)
@@ -1,7 +1,6 @@
 package server
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,6 +14,12 @@ import (
 	"golang.org/x/net/context"
 )
 
+// headers describing a publication, recorded in the publish history of the route
+const (
+	PUBLISHER_HEADER = "X-UDocs-Publisher"
+	COMMIT_HEADER    = "X-UDocs-Commit"
+)
+
 type Server struct {
 	treeMux  *httptreemux.TreeMux
 	settings config.Settings
@@ -91,7 +96,10 @@ func (s *Server) registerEndpoints() {
 	s.Handle(http.MethodGet, "/:route/*", s.pageHandler)
 	s.Handle(http.MethodPost, "/api/:route", s.updateHandler)
 	s.Handle(http.MethodDelete, "/api/:route", s.destroyHandler)
+	s.Handle(http.MethodGet, "/api/:route/history", s.historyHandler)
+	s.Handle(http.MethodPost, "/api/:route/rollback/:id", s.rollbackHandler)
 	s.Handle(http.MethodGet, "/search", s.searchHandler)
+	s.Handle(http.MethodGet, "/api/search", s.apiSearchHandler)
 	s.Handle(http.MethodGet, "/blob/:route/:thread/:id", s.quipBlobHandler)
 }
 
@@ -106,7 +114,8 @@ func parseHostURL(url string) (string, string) {
 	return "", url
 }
 
-func updateSidebar(sidebar []udocs.Summary, summary udocs.Summary, dao storage.Dao) error {
+// replaceSummary replaces the summary of the route of summary in sidebar.
+func replaceSummary(sidebar udocs.Sidebar, summary udocs.Summary) udocs.Sidebar {
 	var found bool
 	for i, item := range sidebar {
 		if item.Route == summary.Route {
@@ -119,17 +128,7 @@ func updateSidebar(sidebar []udocs.Summary, summary udocs.Summary, dao storage.D
 	if !found {
 		sidebar = append(sidebar, summary)
 	}
-
-	globalSummaryData, err := json.Marshal(sidebar)
-	if err != nil {
-		return fmt.Errorf("api.UpdateSummary failed to marshal global SUMMARY.json: %v", err)
-	}
-
-	if err := dao.Insert(udocs.SIDEBAR_JSON, globalSummaryData); err != nil {
-		return fmt.Errorf("api.UpdateSummary failed to insert global SUMMARY.json: %v", err)
-	}
-
-	return nil
+	return sidebar
 }
 
 func createBaseDirs() error {
@@ -142,12 +141,5 @@ func createBaseDirs() error {
 }
 
 func defaultTemplateParams(settings config.Settings) map[string]interface{} {
-	m := make(map[string]interface{})
-	m["entrypoint"] = settings.EntryPoint
-	m["organization"] = settings.Organization
-	m["email"] = settings.Email
-	m["search_placeholder"] = settings.SearchPlaceholder
-	m["color"] = settings.PrimaryColor
-	m["homePath"] = settings.HomePath
-	return m
+	return settings.TemplateParams()
 }
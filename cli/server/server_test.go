@@ -2,13 +2,19 @@ package server
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mholt/archiver"
 	"github.com/seanawilliams/udocs/cli/config"
 	"github.com/seanawilliams/udocs/cli/storage"
 	"github.com/seanawilliams/udocs/cli/udocs"
@@ -65,3 +71,713 @@ func TestHandle(t *testing.T) {
 		t.Errorf("GET %s\tExpected %s, Got: %s", resp.Request.URL, expected, string(data))
 	}
 }
+
+func TestAPISearch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings := config.DefaultSettings()
+	dao, err := storage.NewFileSystemDao(filepath.Join(dir, "deploy"), 0755, filepath.Join(dir, "search", "index"))
+	if err != nil {
+		t.Fatalf("Terminating test due to failed dao creation: %v", err)
+	}
+	Tmpls = udocs.DefaultTemplateFiles()
+	server := New(&settings, dao)
+
+	pages := map[string]string{
+		"/alpha/index.html": "Alpha gopher overview",
+		"/alpha/more.html":  "More about the gopher",
+		"/beta/index.html":  "Beta gopher overview",
+	}
+	for id, body := range pages {
+		if err := dao.Index(id, storage.SearchDocument{Title: body, Body: body}); err != nil {
+			t.Fatalf("Terminating test due to failed index: %v", err)
+		}
+	}
+	sectioned := storage.SearchDocument{
+		Title:      "Gamma",
+		Route:      "gamma",
+		Header:     "Gamma Guide",
+		Breadcrumb: []string{"Gamma Guide", "Gamma"},
+		Body:       "Overview of the guide. Installing the zebra.",
+		Sections: []storage.Section{
+			storage.Section{ID: "overview", Heading: "Overview", Body: "Overview of the guide."},
+			storage.Section{ID: "installing", Heading: "Installing", Body: "Installing the zebra."},
+		},
+	}
+	if err := dao.Index("/gamma/index.html", sectioned); err != nil {
+		t.Fatalf("Terminating test due to failed index: %v", err)
+	}
+
+	if err := make(udocs.Sidebar, 0).Save(dao); err != nil {
+		t.Fatalf("Terminating test due to failed sidebar save: %v", err)
+	}
+
+	testServer := httptest.NewServer(server)
+	defer testServer.Close()
+
+	testCases := []struct {
+		query   string
+		total   uint64
+		matches int
+		facets  int
+		url     string
+	}{
+		{query: "q=gopher", total: 3, matches: 3, facets: 2},
+		{query: "q=gopher&size=2", total: 3, matches: 2, facets: 2},
+		{query: "q=gopher&size=2&from=2", total: 3, matches: 1, facets: 2},
+		{query: "q=gopher&route=alpha", total: 2, matches: 2, facets: 1},
+		{query: "q=gopher&route=alpha,beta", total: 3, matches: 3, facets: 2},
+		{query: "q=nothing", total: 0, matches: 0, facets: 0},
+		{query: "q=zebra", total: 1, matches: 1, facets: 1, url: "/gamma/index.html#installing"},
+	}
+
+	for _, tc := range testCases {
+		resp, err := http.Get(testServer.URL + "/api/search?" + tc.query)
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+
+		var result storage.QueryResult
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("GET /api/search?%s\tfailed to decode response: %v", tc.query, err)
+		}
+
+		if result.Total != tc.total || len(result.QueryMatches) != tc.matches || len(result.Facets) != tc.facets {
+			t.Errorf("GET /api/search?%s\tExpected: %d total, %d matches, %d facets, Got: %d total, %d matches, %d facets",
+				tc.query, tc.total, tc.matches, tc.facets, result.Total, len(result.QueryMatches), len(result.Facets))
+		}
+
+		if tc.url != "" && len(result.QueryMatches) > 0 && result.QueryMatches[0].URL != tc.url {
+			t.Errorf("GET /api/search?%s\tExpected URL: %s, Got: %s", tc.query, tc.url, result.QueryMatches[0].URL)
+		}
+	}
+	resp, err := http.Get(testServer.URL + "/search?q=gopher&size=2")
+	if err != nil {
+		t.Fatalf("failed to execute GET: %v", err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(data, []byte("from=2")) {
+		t.Errorf("GET /search?q=gopher&size=2\tExpected: %d with a next page link, Got: %d %s", http.StatusOK, resp.StatusCode, data)
+	}
+}
+
+func TestAPIAuthorization(t *testing.T) {
+	settings := config.DefaultSettings()
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	server := New(&settings, dao)
+
+	if err := make(udocs.Sidebar, 0).Save(dao); err != nil {
+		t.Fatalf("Terminating test due to failed sidebar save: %v", err)
+	}
+	tokens, secret, err := make(udocs.Tokens, 0).Mint("alpha team", []string{"alpha"})
+	if err != nil {
+		t.Fatalf("Terminating test due to failed mint: %v", err)
+	}
+	if err := tokens.Save(dao); err != nil {
+		t.Fatalf("Terminating test due to failed token save: %v", err)
+	}
+
+	testServer := httptest.NewServer(server)
+	defer testServer.Close()
+
+	testCases := []struct {
+		route string
+		token string
+		code  int
+	}{
+		{route: "alpha", token: "", code: http.StatusUnauthorized},
+		{route: "alpha", token: "invalid", code: http.StatusForbidden},
+		{route: "beta", token: secret, code: http.StatusForbidden},
+		{route: "alpha", token: secret, code: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(http.MethodDelete, testServer.URL+"/api/"+tc.route, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute DELETE: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.code {
+			t.Errorf("DELETE /api/%s with token %q\tExpected: %d, Got: %d", tc.route, tc.token, tc.code, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(testServer.URL + "/" + udocs.TOKENS_JSON)
+	if err != nil {
+		t.Fatalf("failed to execute GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /%s\tExpected: %d, Got: %d", udocs.TOKENS_JSON, http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestAPIWithoutTokens(t *testing.T) {
+	Tmpls = udocs.DefaultTemplateFiles()
+	dao := storage.NewMockDao("")
+	if err := make(udocs.Sidebar, 0).Save(dao); err != nil {
+		t.Fatalf("Terminating test due to failed sidebar save: %v", err)
+	}
+
+	testCases := []struct {
+		dao                  storage.Dao
+		allowUnauthenticated bool
+		code                 int
+	}{
+		{dao: dao, allowUnauthenticated: false, code: http.StatusUnauthorized},
+		{dao: dao, allowUnauthenticated: true, code: http.StatusOK},
+		{dao: unreachableTokensDao{dao}, allowUnauthenticated: true, code: http.StatusInternalServerError},
+	}
+	for _, tc := range testCases {
+		settings := config.DefaultSettings()
+		settings.AllowUnauthenticated = tc.allowUnauthenticated
+		testServer := httptest.NewServer(New(&settings, tc.dao))
+
+		req, err := http.NewRequest(http.MethodDelete, testServer.URL+"/api/alpha", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute DELETE: %v", err)
+		}
+		resp.Body.Close()
+		testServer.Close()
+
+		if resp.StatusCode != tc.code {
+			t.Errorf("DELETE /api/alpha without tokens (%T, allow unauthenticated: %t)\tExpected: %d, Got: %d", tc.dao, tc.allowUnauthenticated, tc.code, resp.StatusCode)
+		}
+	}
+}
+
+func TestAPIRouteValidation(t *testing.T) {
+	settings := config.DefaultSettings()
+	settings.AllowUnauthenticated = true
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	if err := make(udocs.Sidebar, 0).Save(dao); err != nil {
+		t.Fatalf("Terminating test due to failed sidebar save: %v", err)
+	}
+	if err := dao.Insert("/beta/index.html", []byte("beta")); err != nil {
+		t.Fatalf("Terminating test due to failed insert: %v", err)
+	}
+
+	testServer := httptest.NewServer(New(&settings, dao))
+	defer testServer.Close()
+
+	// the router unescapes routes, so these would reach outside of the deploy root, or every route
+	testCases := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodDelete, path: "/api/%2e%2e%2fbeta"},
+		{method: http.MethodDelete, path: "/api/*"},
+		{method: http.MethodDelete, path: "/api/%2A"},
+		{method: http.MethodPost, path: "/api/%2e%2e%2f%2e%2e%2fetc"},
+		{method: http.MethodGet, path: "/api/%2e%2e/history"},
+		{method: http.MethodPost, path: "/api/%2e%2e%2fbeta/rollback/1"},
+	}
+	for _, tc := range testCases {
+		req, err := http.NewRequest(tc.method, testServer.URL+tc.path, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute %s: %v", tc.method, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s %s\tExpected: %d, Got: %d", tc.method, tc.path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+	if data, err := dao.Fetch("/beta/index.html"); err != nil || string(data) != "beta" {
+		t.Errorf("GET /beta/index.html after invalid requests\tExpected: beta, Got: %q (%v)", data, err)
+	}
+}
+
+// unreachableTokensDao fails to fetch the API tokens, as a backend that is down does.
+type unreachableTokensDao struct {
+	storage.Dao
+}
+
+func (d unreachableTokensDao) Fetch(id string) ([]byte, error) {
+	if strings.Trim(id, "/") == udocs.TOKENS_JSON {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return d.Dao.Fetch(id)
+}
+
+func TestVersionedPage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings := config.DefaultSettings()
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	server := New(&settings, dao)
+
+	for _, version := range []string{"v1", "v2"} {
+		files := map[string]string{
+			udocs.README_MD:  "# Alpha\nThis is version " + version,
+			udocs.SUMMARY_MD: "# Alpha\n* [Overview](README.md)",
+		}
+		for name, content := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Terminating test due to failed file write: %v", err)
+			}
+		}
+		if _, err := udocs.BuildVersion("alpha", version, dir, dao); err != nil {
+			t.Fatalf("Terminating test due to failed build: %v", err)
+		}
+	}
+
+	testServer := httptest.NewServer(server)
+	defer testServer.Close()
+
+	testCases := map[string]string{
+		"/alpha/latest/index.html": "This is version v2",
+		"/alpha/v1/index.html":     "This is version v1",
+	}
+	for path, expected := range testCases {
+		resp, err := http.Get(testServer.URL + path)
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("GET %s\tExpected: %d with %q, Got: %d %s", path, http.StatusOK, expected, resp.StatusCode, data)
+		}
+		if !bytes.Contains(data, []byte(`href="/alpha/v1/"`)) {
+			t.Errorf("GET %s\tExpected a version switcher linking to v1", path)
+		}
+	}
+}
+
+func TestPublishHistoryAndRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	defer os.Setenv("HOME", home)
+
+	settings := config.DefaultSettings()
+	settings.AllowUnauthenticated = true
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	testServer := httptest.NewServer(New(&settings, dao))
+	defer testServer.Close()
+
+	docs := filepath.Join(dir, "src", "docs")
+	if err := os.MkdirAll(docs, 0755); err != nil {
+		t.Fatalf("Terminating test due to failed dir creation: %v", err)
+	}
+	for i, content := range []string{"First publish", "Second publish"} {
+		files := map[string]string{
+			udocs.README_MD:  "# Alpha\n" + content,
+			udocs.SUMMARY_MD: "# Alpha\n* [Overview](README.md)",
+		}
+		for name, content := range files {
+			if err := ioutil.WriteFile(filepath.Join(docs, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Terminating test due to failed file write: %v", err)
+			}
+		}
+		tarball := filepath.Join(dir, fmt.Sprintf("docs%d.tar.gz", i))
+		if err := archiver.TarGz.Make(tarball, []string{docs}); err != nil {
+			t.Fatalf("Terminating test due to failed tarball creation: %v", err)
+		}
+		f, err := os.Open(tarball)
+		if err != nil {
+			t.Fatalf("Terminating test due to failed tarball open: %v", err)
+		}
+		req, _ := http.NewRequest(http.MethodPost, testServer.URL+"/api/alpha", f)
+		req.Header.Set(PUBLISHER_HEADER, "gopher")
+		req.Header.Set(COMMIT_HEADER, fmt.Sprintf("commit%d", i))
+		resp, err := http.DefaultClient.Do(req)
+		f.Close()
+		if err != nil || resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Terminating test due to failed publish: %v %v", err, resp)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(testServer.URL + "/api/alpha/history")
+	if err != nil {
+		t.Fatalf("failed to execute GET: %v", err)
+	}
+	var history udocs.History
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("GET /api/alpha/history\tfailed to decode response: %v", err)
+	}
+	if len(history.Snapshots) != 2 || history.Current != history.Snapshots[0].ID {
+		t.Fatalf("GET /api/alpha/history\tExpected: 2 snapshots, the latest current, Got: %+v", history)
+	}
+	first := history.Snapshots[1]
+	if first.Publisher != "gopher" || first.Commit != "commit0" || first.Files != 2 {
+		t.Errorf("GET /api/alpha/history\tExpected: snapshot by gopher of commit0 with 2 files, Got: %+v", first)
+	}
+
+	resp, err = http.Post(testServer.URL+"/api/alpha/rollback/"+first.ID, "", nil)
+	if err != nil {
+		t.Fatalf("failed to execute POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /api/alpha/rollback/%s\tExpected: %d, Got: %d", first.ID, http.StatusOK, resp.StatusCode)
+	}
+
+	data, err := dao.Fetch("/alpha/index.html")
+	if err != nil || !bytes.Contains(data, []byte("First publish")) {
+		t.Errorf("expected /alpha/index.html to be rolled back to the first publish, got: %s", data)
+	}
+	if history, _ = udocs.LoadHistory("alpha"); history.Current != first.ID {
+		t.Errorf("Expected current snapshot: %s, Got: %s", first.ID, history.Current)
+	}
+
+	resp, err = http.Post(testServer.URL+"/api/alpha/rollback/missing", "", nil)
+	if err != nil {
+		t.Fatalf("failed to execute POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST /api/alpha/rollback/missing\tExpected: %d, Got: %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestHistoryWithSharedStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	defer os.Setenv("HOME", home)
+
+	// servers sharing their storage do not share the snapshots on their local disks
+	settings := config.DefaultSettings()
+	settings.AllowUnauthenticated = true
+	settings.StorageURL = "s3://bucket/udocs"
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	testServer := httptest.NewServer(New(&settings, dao))
+	defer testServer.Close()
+
+	docs := filepath.Join(dir, "src", "docs")
+	if err := os.MkdirAll(docs, 0755); err != nil {
+		t.Fatalf("Terminating test due to failed dir creation: %v", err)
+	}
+	files := map[string]string{
+		udocs.README_MD:  "# Alpha\nHello, world!",
+		udocs.SUMMARY_MD: "# Alpha\n* [Overview](README.md)",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(docs, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+	tarball := filepath.Join(dir, "docs.tar.gz")
+	if err := archiver.TarGz.Make(tarball, []string{docs}); err != nil {
+		t.Fatalf("Terminating test due to failed tarball creation: %v", err)
+	}
+	f, err := os.Open(tarball)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed tarball open: %v", err)
+	}
+	resp, err := http.Post(testServer.URL+"/api/alpha", "", f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("failed to execute POST: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /api/alpha\tExpected: %d, Got: %d", http.StatusCreated, resp.StatusCode)
+	}
+	if snapshots, _ := filepath.Glob(udocs.SnapshotPath("alpha", "*")); len(snapshots) != 0 {
+		t.Errorf("POST /api/alpha\tExpected: no snapshot kept, Got: %v", snapshots)
+	}
+
+	testCases := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/api/alpha/history"},
+		{method: http.MethodPost, path: "/api/alpha/rollback/1"},
+	}
+	for _, tc := range testCases {
+		req, err := http.NewRequest(tc.method, testServer.URL+tc.path, nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute %s: %v", tc.method, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotImplemented {
+			t.Errorf("%s %s\tExpected: %d, Got: %d", tc.method, tc.path, http.StatusNotImplemented, resp.StatusCode)
+		}
+	}
+}
+
+func TestPageFrontMatter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		udocs.README_MD:  "---\ntitle: Alpha Overview\ndescription: All about alpha\nredirect_from: [old.md]\n---\n# Alpha\n",
+		udocs.SUMMARY_MD: "# Alpha\n* [Overview](README.md)",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	settings := config.DefaultSettings()
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	server := New(&settings, dao)
+	if _, err := udocs.Build("alpha", dir, dao); err != nil {
+		t.Fatalf("Terminating test due to failed build: %v", err)
+	}
+
+	testServer := httptest.NewServer(server)
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/alpha/old.html")
+	if err != nil {
+		t.Fatalf("failed to execute GET: %v", err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Request.URL.Path != "/alpha/index.html" {
+		t.Fatalf("GET /alpha/old.html\tExpected: redirect to /alpha/index.html, Got: %d %s", resp.StatusCode, resp.Request.URL.Path)
+	}
+	for _, expected := range []string{`<title>Alpha Overview - UDocs</title>`, `<meta name="description" content="All about alpha">`} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("GET /alpha/index.html\tExpected: %s, Got: %s", expected, data)
+		}
+	}
+}
+
+func TestPageTOC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		udocs.README_MD:  "# Alpha\n## Install\n### Linux\n## Usage\n",
+		udocs.SUMMARY_MD: "# Alpha\n* [Overview](README.md)",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	settings := config.DefaultSettings()
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	server := New(&settings, dao)
+	if _, err := udocs.Build("alpha", dir, dao); err != nil {
+		t.Fatalf("Terminating test due to failed build: %v", err)
+	}
+
+	testServer := httptest.NewServer(server)
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/alpha/index.html?toc=json")
+	if err != nil {
+		t.Fatalf("failed to execute GET: %v", err)
+	}
+	var toc []udocs.TOCEntry
+	err = json.NewDecoder(resp.Body).Decode(&toc)
+	resp.Body.Close()
+	if err != nil || len(toc) != 3 || toc[1].ID != "linux" || toc[1].Level != 3 {
+		t.Errorf("GET /alpha/index.html?toc=json\tExpected: 3 entries, Got: %v (%v)", toc, err)
+	}
+
+	resp, err = http.Get(testServer.URL + "/alpha/index.html")
+	if err != nil {
+		t.Fatalf("failed to execute GET: %v", err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Contains(data, []byte(`<li class="toc-level-2"><a href="#install" title="Install">Install</a></li>`)) {
+		t.Errorf("GET /alpha/index.html\tExpected an \"On this page\" panel, Got: %s", data)
+	}
+}
+
+func TestPageHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	defer os.Setenv("HOME", home)
+
+	settings := config.DefaultSettings()
+	settings.AllowUnauthenticated = true
+	dao := storage.NewMockDao("")
+	Tmpls = udocs.DefaultTemplateFiles()
+	testServer := httptest.NewServer(New(&settings, dao))
+	defer testServer.Close()
+
+	// the git history is published next to the docs directory, as udocs publish sends it
+	docs := filepath.Join(dir, "src", "docs")
+	if err := os.MkdirAll(docs, 0755); err != nil {
+		t.Fatalf("Terminating test due to failed dir creation: %v", err)
+	}
+	files := map[string]string{
+		udocs.README_MD:    "# Alpha\nHello, world!",
+		udocs.SUMMARY_MD:   "# Alpha\n* [Overview](README.md)",
+		udocs.GUIDE_CONFIG: "edit_url: https://example.com/edit/master/docs/{path}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(docs, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+	history := udocs.GitHistory{"/README.md": {Updated: time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC), Author: "Ada", Commit: "c1", Contributors: []string{"Ada"}}}
+	historyFile := filepath.Join(dir, "src", udocs.GIT_HISTORY_JSON)
+	if err := history.Save(historyFile); err != nil {
+		t.Fatalf("Terminating test due to failed history save: %v", err)
+	}
+	tarball := filepath.Join(dir, "docs.tar.gz")
+	if err := archiver.TarGz.Make(tarball, []string{docs, historyFile}); err != nil {
+		t.Fatalf("Terminating test due to failed tarball creation: %v", err)
+	}
+	f, err := os.Open(tarball)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed tarball open: %v", err)
+	}
+	resp, err := http.Post(testServer.URL+"/api/alpha", "application/octet-stream", f)
+	f.Close()
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Terminating test due to failed publish: %v %v", err, resp)
+	}
+	resp.Body.Close()
+
+	expected := []string{
+		`Last updated by Ada on <time datetime="2024-03-04T12:00:00Z">March 4, 2024</time>`,
+		`<a class="page-edit" href="https://example.com/edit/master/docs/README.md" rel="nofollow">`,
+	}
+	for _, uri := range []string{"/alpha/index.html", "/alpha/index.html?ajax=true"} {
+		resp, err := http.Get(testServer.URL + uri)
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		for _, e := range expected {
+			if !bytes.Contains(data, []byte(e)) {
+				t.Errorf("GET %s\tExpected: %s, Got: %s", uri, e, data)
+			}
+		}
+	}
+}
+
+func TestPageAssets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fsDao, err := storage.NewFileSystemDao(filepath.Join(dir, "deploy"), 0755, "")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed dao creation: %v", err)
+	}
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+	for _, dao := range []storage.Dao{fsDao, storage.NewMockDao("")} {
+		if err := dao.Insert("/alpha/images/logo.png", png); err != nil {
+			t.Fatalf("Terminating test due to failed insert: %v", err)
+		}
+		settings := config.DefaultSettings()
+		Tmpls = udocs.DefaultTemplateFiles()
+		testServer := httptest.NewServer(New(&settings, dao))
+
+		resp, err := http.Get(testServer.URL + "/alpha/images/logo.png")
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		etag := resp.Header.Get("Etag")
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" || etag == "" || !bytes.Equal(data, png) {
+			t.Errorf("GET /alpha/images/logo.png (%T)\tExpected: 200 image/png with an ETag, Got: %d %s %q", dao, resp.StatusCode, resp.Header.Get("Content-Type"), etag)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, testServer.URL+"/alpha/images/logo.png", nil)
+		req.Header.Set("Range", "bytes=1-3")
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+		data, _ = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusPartialContent || string(data) != "PNG" {
+			t.Errorf("GET /alpha/images/logo.png with Range: bytes=1-3 (%T)\tExpected: 206 %q, Got: %d %q", dao, "PNG", resp.StatusCode, data)
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, testServer.URL+"/alpha/images/logo.png", nil)
+		req.Header.Set("If-None-Match", etag)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("GET /alpha/images/logo.png with If-None-Match (%T)\tExpected: 304, Got: %d", dao, resp.StatusCode)
+		}
+
+		resp, err = http.Get(testServer.URL + "/alpha/images/missing.png")
+		if err != nil {
+			t.Fatalf("failed to execute GET: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET /alpha/images/missing.png (%T)\tExpected: 404, Got: %d", dao, resp.StatusCode)
+		}
+		testServer.Close()
+	}
+}
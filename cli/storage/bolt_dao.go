@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// PAGES_BUCKET is the bucket of a BoltDao that holds its entries, keyed by their ID.
+const PAGES_BUCKET = "pages"
+
+// BoltDao stores every entry in a single BoltDB file, for servers that need durable storage without
+// running MongoDB or keeping a directory tree of HTML. The file is locked while the Dao is open, so a
+// second process opening it, such as an admin command run while the server is up, waits for a second, and
+// fails.
+type BoltDao struct {
+	db *bolt.DB
+	*SearchDB
+}
+
+func NewBoltDao(filename string, searchDir string) (*BoltDao, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, fmt.Errorf("storage.NewBoltDao: %v", err)
+	}
+	db, err := bolt.Open(filename, 0644, &bolt.Options{Timeout: time.Second})
+	if err == bolt.ErrTimeout {
+		return nil, fmt.Errorf("storage.NewBoltDao: %s is in use by another process, such as a running `udocs serve`, which must be stopped first", filename)
+	} else if err != nil {
+		return nil, fmt.Errorf("storage.NewBoltDao: failed to open %s: %v", filename, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(PAGES_BUCKET))
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage.NewBoltDao: %v", err)
+	}
+
+	searchDB, err := NewSearchDB(searchDir)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltDao{db: db, SearchDB: searchDB}, nil
+}
+
+// Close closes the BoltDB file, which is then unlocked for other processes.
+func (b *BoltDao) Close() error {
+	return b.db.Close()
+}
+
+func boltKey(id string) []byte {
+	return []byte(path.Join("/", id))
+}
+
+func (b *BoltDao) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+
+	var data []byte
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		// values are only valid for the life of the transaction
+		if value := tx.Bucket([]byte(PAGES_BUCKET)).Get(boltKey(id)); value != nil {
+			data = append([]byte{}, value...)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %v", err)
+	}
+	if data == nil {
+		return nil, &NotFoundError{Op: "storage.Fetch", ID: id}
+	}
+	return data, nil
+}
+
+func (b *BoltDao) FetchGlob(pattern string) []string {
+	ids := make([]string, 0)
+	b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(PAGES_BUCKET)).ForEach(func(k, v []byte) error {
+			if id := string(k); filepath.Ext(id) != "" && globMatch(pattern, id, false) {
+				ids = append(ids, id)
+			}
+			return nil
+		})
+	})
+	return ids
+}
+
+func (b *BoltDao) Insert(id string, data []byte) error {
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(PAGES_BUCKET)).Put(boltKey(id), data)
+	}); err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	return nil
+}
+
+func (b *BoltDao) Delete(id string) error {
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(PAGES_BUCKET))
+		if bucket.Get(boltKey(id)) == nil {
+			return fmt.Errorf("%s not found", id)
+		}
+		return bucket.Delete(boltKey(id))
+	}); err != nil {
+		return fmt.Errorf("storage.Delete: %v", err)
+	}
+
+	if err := b.Unindex(id); err != nil {
+		return fmt.Errorf("storage.Delete: %v", err)
+	}
+	return nil
+}
+
+// DeleteGlob deletes the entries matching pattern, and the entries under the directories matching it.
+func (b *BoltDao) DeleteGlob(pattern string) error {
+	var deleted []string
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(PAGES_BUCKET))
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil; {
+			if !globMatch(pattern, string(k), true) {
+				k, _ = c.Next()
+				continue
+			}
+			deleted = append(deleted, string(k))
+			key := append([]byte(nil), k...) // k is invalid once deleted
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			k, _ = c.Seek(key)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("storage.DeleteGlob: %v", err)
+	}
+
+	for _, id := range deleted {
+		if err := b.Unindex(id); err != nil {
+			return fmt.Errorf("storage.DeleteGlob: %v", err)
+		}
+	}
+	return nil
+}
+
+func (b *BoltDao) Query(req QueryRequest) (*QueryResult, error) {
+	return b.SearchDB.Query(req)
+}
+
+func (b *BoltDao) Index(id string, doc SearchDocument) error {
+	return b.SearchDB.IndexPage(id, doc)
+}
+
+func (b *BoltDao) IndexedHashes() (map[string]string, error) {
+	return b.SearchDB.IndexedHashes()
+}
+
+func (b *BoltDao) Unindex(id string) error {
+	return b.SearchDB.Unindex(id)
+}
+
+func (b *BoltDao) ResetIndex() error {
+	return b.SearchDB.Reset()
+}
+
+func (b *BoltDao) Drop() error {
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(PAGES_BUCKET)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket([]byte(PAGES_BUCKET))
+		return err
+	}); err != nil {
+		return fmt.Errorf("storage.Drop: %v", err)
+	}
+	return nil
+}
+
+type boltStaging struct {
+	b      *BoltDao
+	prefix string
+	pages  map[string][]byte
+}
+
+// Stage creates a staging area for the entries under prefix. Staged entries are kept in memory until
+// Commit replaces the entries of the prefix with them in a single transaction.
+func (b *BoltDao) Stage(prefix string) (Staging, error) {
+	if strings.Trim(prefix, "/") == "" {
+		return nil, fmt.Errorf("storage.Stage: cannot stage the root of the dao")
+	}
+	return &boltStaging{b: b, prefix: path.Join("/", prefix), pages: make(map[string][]byte)}, nil
+}
+
+func (st *boltStaging) staged(key string) bool {
+	return key == st.prefix || strings.HasPrefix(key, st.prefix+"/")
+}
+
+func (st *boltStaging) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	data, ok := st.pages[string(boltKey(id))]
+	if !ok {
+		return nil, fmt.Errorf("storage.Fetch: %s is not staged under %s", id, st.prefix)
+	}
+	return data, nil
+}
+
+func (st *boltStaging) Insert(id string, data []byte) error {
+	key := string(boltKey(id))
+	if !st.staged(key) {
+		return fmt.Errorf("storage.Insert: %s is not staged under %s", id, st.prefix)
+	}
+	st.pages[key] = data
+	return nil
+}
+
+// Commit deletes the entries of the prefix and inserts the staged entries in one transaction, so readers
+// see either every entry of the prefix as it was, or every staged entry.
+func (st *boltStaging) Commit() error {
+	if err := st.b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(PAGES_BUCKET))
+		c := bucket.Cursor()
+		for k, _ := c.Seek([]byte(st.prefix)); k != nil && bytes.HasPrefix(k, []byte(st.prefix)); {
+			if !st.staged(string(k)) {
+				k, _ = c.Next()
+				continue
+			}
+			key := append([]byte(nil), k...) // k is invalid once deleted
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			k, _ = c.Seek(key)
+		}
+		for key, data := range st.pages {
+			if err := bucket.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+	st.pages = nil
+	return nil
+}
+
+func (st *boltStaging) Abort() error {
+	st.pages = nil
+	return nil
+}
+
+// globMatch reports whether id matches the glob pattern, as in /route/*.html, or when dirs is set, whether
+// it is under a directory that does.
+func globMatch(pattern, id string, dirs bool) bool {
+	pattern = path.Join("/", pattern)
+	for id != "/" && id != "." {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+		if !dirs {
+			return false
+		}
+		id = path.Dir(id)
+	}
+	return false
+}
@@ -1,12 +1,48 @@
 package storage
 
 type Dao interface {
-	Fetch(id string) ([]byte, error)
+	Store
 	FetchGlob(pattern string) []string
-	Insert(id string, data []byte) error
 	Delete(id string) error
 	DeleteGlob(pattern string) error
-	Index(id, title string, data []byte) error
-	Query(query string) (*QueryResult, error)
+	Index(id string, doc SearchDocument) error
+	Query(req QueryRequest) (*QueryResult, error)
+	IndexedHashes() (map[string]string, error)
+	Unindex(id string) error
+	ResetIndex() error
+	Stage(prefix string) (Staging, error)
 	Drop() error
 }
+
+// Store is the subset of a Dao needed to read and write pages.
+type Store interface {
+	Fetch(id string) ([]byte, error)
+	Insert(id string, data []byte) error
+}
+
+// Staging is a private area in which the entries under a prefix of a Dao (such as a route) are built,
+// before Commit swaps them into the Dao in a single step. Readers of the Dao see either every entry of
+// the prefix as it was before the commit, or every staged entry, and entries of the prefix that were not
+// staged are gone once committed. Abort discards the staging area, leaving the Dao untouched.
+type Staging interface {
+	Store
+	Commit() error
+	Abort() error
+}
+
+// NotFoundError is the error of an operation on an entry that does not exist. Other errors, as when the
+// backend is unreachable, say nothing of whether the entry exists.
+type NotFoundError struct {
+	Op string
+	ID string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Op + ": " + e.ID + " not found"
+}
+
+// IsNotFound reports whether err is a NotFoundError.
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testDao runs the conformance suite every Dao must pass against dao, which must be empty and have a
+// search index.
+func testDao(t *testing.T, dao Dao) {
+	pages := map[string]string{
+		"/alpha/index.html":        "alpha",
+		"/alpha/page.html":         "page",
+		"/alpha/images/logo.png":   "logo",
+		"/alpha/v1/index.html":     "v1",
+		"/alpha-beta/index.html":   "alpha-beta",
+		"/gamma/index.html":        "gamma",
+		"/gamma/nested/index.html": "nested",
+	}
+	for id, data := range pages {
+		if err := dao.Insert(id, []byte(data)); err != nil {
+			t.Fatalf("Insert(%s) => %v", id, err)
+		}
+	}
+
+	testCases := []struct {
+		id       string
+		expected string
+	}{
+		{id: "/alpha/page.html", expected: "page"},
+		{id: "alpha/page.html", expected: "page"},
+		{id: "/alpha", expected: "alpha"},
+		{id: "/alpha/v1", expected: "v1"},
+		{id: "/alpha/missing.html", expected: ""},
+	}
+	for _, tc := range testCases {
+		data, err := dao.Fetch(tc.id)
+		if tc.expected == "" && !IsNotFound(err) {
+			t.Errorf("Fetch(%s) -> expected a NotFoundError, got: %q (%v)", tc.id, data, err)
+		} else if tc.expected != "" && string(data) != tc.expected {
+			t.Errorf("Fetch(%s) -> expected: %q got: %q (%v)", tc.id, tc.expected, data, err)
+		}
+	}
+
+	// entries are streamed with their metadata
+	ctx := context.Background()
+	objects := Objects(dao)
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+	info, err := objects.Put(ctx, "/alpha/images/diagram.svg", bytes.NewReader(svg), "")
+	if err != nil {
+		t.Fatalf("Put(/alpha/images/diagram.svg) => %v", err)
+	}
+	if info.Size != int64(len(svg)) || info.ContentType != "image/svg+xml" || info.ETag == "" {
+		t.Errorf("Put(/alpha/images/diagram.svg) -> expected: %d bytes of image/svg+xml with an ETag got: %+v", len(svg), info)
+	}
+	object, err := objects.Get(ctx, "/alpha/images/diagram.svg")
+	if err != nil {
+		t.Fatalf("Get(/alpha/images/diagram.svg) => %v", err)
+	}
+	if _, err := object.Seek(5, io.SeekStart); err != nil {
+		t.Fatalf("Seek => %v", err)
+	}
+	data, err := ioutil.ReadAll(object)
+	object.Close()
+	if !bytes.Equal(data, svg[5:]) || object.Info().ETag != info.ETag {
+		t.Errorf("Get(/alpha/images/diagram.svg) -> expected: %q with ETag %s got: %q with %+v (%v)", svg[5:], info.ETag, data, object.Info(), err)
+	}
+	if info, err := objects.Stat(ctx, "/alpha"); err != nil || info.ContentType != "text/html; charset=utf-8" || info.Size != int64(len("alpha")) {
+		t.Errorf("Stat(/alpha) -> expected: %d bytes of text/html got: %+v (%v)", len("alpha"), info, err)
+	}
+	if updated, err := objects.Put(ctx, "/alpha/images/diagram.svg", bytes.NewReader(append(svg, '\n')), ""); err != nil || updated.ETag == info.ETag {
+		t.Errorf("Put(/alpha/images/diagram.svg) again -> expected a new ETag, got: %+v (%v)", updated, err)
+	}
+	if _, err := objects.Stat(ctx, "/alpha/missing.html"); err == nil {
+		t.Error("Stat(/alpha/missing.html) -> expected an error")
+	}
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := objects.Get(canceled, "/alpha"); err == nil {
+		t.Error("Get(/alpha) with a canceled context -> expected an error")
+	}
+
+	if err := dao.Insert("/alpha/page.html", []byte("updated")); err != nil {
+		t.Fatalf("Insert(/alpha/page.html) => %v", err)
+	}
+	if data, _ := dao.Fetch("/alpha/page.html"); string(data) != "updated" {
+		t.Errorf("Fetch(/alpha/page.html) after Insert -> expected: updated got: %q", data)
+	}
+
+	ids := dao.FetchGlob("/alpha/*.html")
+	sort.Strings(ids)
+	if expected := []string{"/alpha/index.html", "/alpha/page.html"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("FetchGlob(/alpha/*.html) -> expected: %v got: %v", expected, ids)
+	}
+
+	// deleting an entry unindexes it
+	for _, id := range []string{"/alpha/page.html", "/alpha/index.html", "/gamma/index.html", "/gamma/nested/index.html"} {
+		if err := dao.Index(id, SearchDocument{Title: id, Hash: "hash" + id}); err != nil {
+			t.Fatalf("Index(%s) => %v", id, err)
+		}
+	}
+	if err := dao.Delete("/alpha/page.html"); err != nil {
+		t.Fatalf("Delete(/alpha/page.html) => %v", err)
+	}
+	if _, err := dao.Fetch("/alpha/page.html"); err == nil {
+		t.Error("Fetch(/alpha/page.html) after Delete -> expected an error")
+	}
+	if err := dao.Delete("/alpha/page.html"); err == nil {
+		t.Error("Delete(/alpha/page.html) twice -> expected an error")
+	}
+	if err := dao.Unindex("/alpha/index.html"); err != nil {
+		t.Fatalf("Unindex(/alpha/index.html) => %v", err)
+	}
+	hashes, err := dao.IndexedHashes()
+	if err != nil {
+		t.Fatalf("IndexedHashes => %v", err)
+	}
+	expectedHashes := map[string]string{"/gamma/index.html": "hash/gamma/index.html", "/gamma/nested/index.html": "hash/gamma/nested/index.html"}
+	if !reflect.DeepEqual(hashes, expectedHashes) {
+		t.Errorf("IndexedHashes -> expected: %v got: %v", expectedHashes, hashes)
+	}
+
+	// staging
+	if _, err := dao.Stage("/"); err == nil {
+		t.Error("Stage(/) -> expected an error for the root of the dao")
+	}
+	stage, err := dao.Stage("alpha")
+	if err != nil {
+		t.Fatalf("Stage(alpha) => %v", err)
+	}
+	if err := stage.Insert("/alpha/index.html", []byte("aborted")); err != nil {
+		t.Fatalf("Insert => %v", err)
+	}
+	if err := stage.Abort(); err != nil {
+		t.Fatalf("Abort => %v", err)
+	}
+	if data, _ := dao.Fetch("/alpha/index.html"); string(data) != "alpha" {
+		t.Errorf("Fetch(/alpha/index.html) after Abort -> expected: alpha got: %q", data)
+	}
+
+	stage, err = dao.Stage("alpha")
+	if err != nil {
+		t.Fatalf("Stage(alpha) => %v", err)
+	}
+	for _, id := range []string{"/alpha-beta/index.html", "/gamma/index.html"} {
+		if err := stage.Insert(id, []byte("outside")); err == nil {
+			t.Errorf("Insert(%s) -> expected an error for an entry outside of the staged prefix", id)
+		}
+	}
+	for id, data := range map[string]string{"/alpha/index.html": "new", "/alpha/v1/index.html": "v1"} {
+		if err := stage.Insert(id, []byte(data)); err != nil {
+			t.Fatalf("Insert(%s) => %v", id, err)
+		}
+	}
+	if data, _ := stage.Fetch("/alpha"); string(data) != "new" {
+		t.Errorf("staged Fetch(/alpha) -> expected: new got: %q", data)
+	}
+	if data, _ := dao.Fetch("/alpha/index.html"); string(data) != "alpha" {
+		t.Errorf("Fetch(/alpha/index.html) before Commit -> expected: alpha got: %q", data)
+	}
+	if err := stage.Commit(); err != nil {
+		t.Fatalf("Commit => %v", err)
+	}
+	expected := map[string]string{
+		"/alpha/index.html":      "new",
+		"/alpha/v1/index.html":   "v1",
+		"/alpha/images/logo.png": "",
+		"/alpha-beta/index.html": "alpha-beta",
+		"/gamma/index.html":      "gamma",
+	}
+	for id, want := range expected {
+		if data, _ := dao.Fetch(id); string(data) != want {
+			t.Errorf("Fetch(%s) after Commit -> expected: %q got: %q", id, want, data)
+		}
+	}
+
+	// a version of a route is staged on its own, and carried over when the route is staged again
+	stage, err = dao.Stage("alpha/v1")
+	if err != nil {
+		t.Fatalf("Stage(alpha/v1) => %v", err)
+	}
+	if err := stage.Insert("/alpha/v1/index.html", []byte("v1 again")); err != nil {
+		t.Fatalf("Insert => %v", err)
+	}
+	if err := stage.Commit(); err != nil {
+		t.Fatalf("Commit => %v", err)
+	}
+	for id, want := range map[string]string{"/alpha/index.html": "new", "/alpha/v1/index.html": "v1 again"} {
+		if data, _ := dao.Fetch(id); string(data) != want {
+			t.Errorf("Fetch(%s) after Commit of alpha/v1 -> expected: %q got: %q", id, want, data)
+		}
+	}
+	stage, err = dao.Stage("alpha")
+	if err != nil {
+		t.Fatalf("Stage(alpha) => %v", err)
+	}
+	for id, data := range map[string]string{"/alpha/index.html": "newer", "/alpha/v1/index.html": "v1 again"} {
+		if err := stage.Insert(id, []byte(data)); err != nil {
+			t.Fatalf("Insert(%s) => %v", id, err)
+		}
+	}
+	if err := stage.Commit(); err != nil {
+		t.Fatalf("Commit => %v", err)
+	}
+	ids = dao.FetchGlob("/alpha/*/*.html")
+	if expected := []string{"/alpha/v1/index.html"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("FetchGlob(/alpha/*/*.html) after Commit -> expected: %v got: %v", expected, ids)
+	}
+
+	// deleting a route deletes, and unindexes, everything under it
+	if err := dao.DeleteGlob("gamma"); err != nil {
+		t.Fatalf("DeleteGlob(gamma) => %v", err)
+	}
+	for id, want := range map[string]string{"/gamma/index.html": "", "/gamma/nested/index.html": "", "/alpha/index.html": "newer"} {
+		if data, _ := dao.Fetch(id); string(data) != want {
+			t.Errorf("Fetch(%s) after DeleteGlob -> expected: %q got: %q", id, want, data)
+		}
+	}
+	if hashes, err := dao.IndexedHashes(); err != nil || len(hashes) != 0 {
+		t.Errorf("IndexedHashes after DeleteGlob -> expected none, got: %v (%v)", hashes, err)
+	}
+
+	if err := dao.Index("/alpha/index.html", SearchDocument{Title: "Alpha", Hash: "hash"}); err != nil {
+		t.Fatalf("Index(/alpha/index.html) => %v", err)
+	}
+	if err := dao.ResetIndex(); err != nil {
+		t.Fatalf("ResetIndex => %v", err)
+	}
+	if hashes, err := dao.IndexedHashes(); err != nil || len(hashes) != 0 {
+		t.Errorf("IndexedHashes after ResetIndex -> expected none, got: %v (%v)", hashes, err)
+	}
+
+	if err := dao.Drop(); err != nil {
+		t.Fatalf("Drop => %v", err)
+	}
+	for id := range expected {
+		if _, err := dao.Fetch(id); err == nil {
+			t.Errorf("Fetch(%s) after Drop -> expected an error", id)
+		}
+	}
+}
+
+func TestMockDao(t *testing.T) {
+	testDao(t, NewMockDao("/tmp"))
+}
+
+func TestFileSystemDao(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dao, err := Open("file://"+filepath.Join(dir, "deploy"), filepath.Join(dir, "search"))
+	if err != nil {
+		t.Fatalf("Open => %v", err)
+	}
+	testDao(t, dao)
+}
+
+func TestBoltDao(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "data", "udocs.db")
+	dao, err := Open("bolt://"+filename, filepath.Join(dir, "search"))
+	if err != nil {
+		t.Fatalf("Open => %v", err)
+	}
+	testDao(t, dao)
+
+	// entries persist across restarts
+	if err := dao.Insert("/alpha/index.html", []byte("durable")); err != nil {
+		t.Fatalf("Insert => %v", err)
+	}
+	if err := dao.(*BoltDao).Close(); err != nil {
+		t.Fatalf("Close => %v", err)
+	}
+	reopened, err := NewBoltDao(filename, "")
+	if err != nil {
+		t.Fatalf("NewBoltDao => %v", err)
+	}
+	defer reopened.Close()
+
+	// the file cannot be opened twice, as by an admin command while the server is up
+	if second, err := NewBoltDao(filename, ""); err == nil || !strings.Contains(err.Error(), "in use by another process") {
+		if second != nil {
+			second.Close()
+		}
+		t.Errorf("NewBoltDao of a file in use -> expected an error telling it is in use, got: %v", err)
+	}
+	if data, _ := reopened.Fetch("/alpha"); string(data) != "durable" {
+		t.Errorf("Fetch(/alpha) after reopening -> expected: durable got: %q", data)
+	}
+}
+
+func TestMongoDBDao(t *testing.T) {
+	url := os.Getenv("UDOCS_TEST_MONGO_URL")
+	if url == "" {
+		t.Skip("UDOCS_TEST_MONGO_URL is not set")
+	}
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dao, err := Open(url, filepath.Join(dir, "search"))
+	if err != nil {
+		t.Fatalf("Open => %v", err)
+	}
+	if err := dao.Drop(); err != nil {
+		t.Fatalf("Drop => %v", err)
+	}
+	testDao(t, dao)
+}
+
+func TestOpen(t *testing.T) {
+	if expected := []string{"bolt", "file", "mongodb", "s3"}; !reflect.DeepEqual(Schemes(), expected) {
+		t.Errorf("Schemes() -> expected: %v got: %v", expected, Schemes())
+	}
+	for _, rawurl := range []string{"ftp://example.com/docs", "/var/udocs", "file://"} {
+		if _, err := Open(rawurl, ""); err == nil {
+			t.Errorf("Open(%s) -> expected an error", rawurl)
+		}
+	}
+	if _, err := Open("sqlite:///var/udocs/udocs.db", ""); err == nil || !strings.Contains(err.Error(), "bolt://") {
+		t.Errorf("Open(sqlite:///var/udocs/udocs.db) -> expected: an error suggesting bolt:// got: %v", err)
+	}
+}
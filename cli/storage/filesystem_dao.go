@@ -1,20 +1,23 @@
 package storage
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"sync"
-	"time"
+	"strings"
 )
 
-var globalData *sync.RWMutex = new(sync.RWMutex)
-
+// FileSystemDao stores entries as files under root. Files are replaced by renaming complete copies into
+// place, and routes by swapping their directories, under the lock of their route only.
 type FileSystemDao struct {
-	root string
-	mode os.FileMode
+	root   string
+	mode   os.FileMode
+	routes routeLocks
 	*SearchDB
 }
 
@@ -31,8 +34,9 @@ func NewFileSystemDao(root string, mode os.FileMode, searchDir string) (*FileSys
 }
 
 func (fs *FileSystemDao) Fetch(pageID string) ([]byte, error) {
-	globalData.RLock()
-	defer globalData.RUnlock()
+	lock := fs.routes.of(pageID)
+	lock.RLock()
+	defer lock.RUnlock()
 
 	if filepath.Ext(pageID) == "" {
 		pageID = filepath.Join(pageID, "index.html")
@@ -40,20 +44,168 @@ func (fs *FileSystemDao) Fetch(pageID string) ([]byte, error) {
 
 	filename := filepath.Join(fs.root, pageID)
 	data, err := ioutil.ReadFile(filename)
-	if err != nil {
+	if os.IsNotExist(err) {
+		return nil, &NotFoundError{Op: "storage.Fetch", ID: pageID}
+	} else if err != nil {
 		return nil, fmt.Errorf("storage.Fetch: %v", err)
 	}
 	return data, nil
 }
 
+type fileObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+func (o *fileObject) Info() ObjectInfo { return o.info }
+
+// Get opens the file of an entry, which is streamed from disk. It stays readable until closed, even when
+// a Commit of its route swaps the file out meanwhile.
+func (fs *FileSystemDao) Get(ctx context.Context, pageID string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	if filepath.Ext(pageID) == "" {
+		pageID = filepath.Join(pageID, "index.html")
+	}
+
+	filename := filepath.Join(fs.root, pageID)
+	lock := fs.routes.of(pageID)
+	lock.RLock()
+	f, err := os.Open(filename)
+	contentType, _ := ioutil.ReadFile(contentTypeFilename(filename))
+	lock.RUnlock()
+	if os.IsNotExist(err) {
+		return nil, &NotFoundError{Op: "storage.Get", ID: pageID}
+	} else if err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	info, err := fileInfo(f, pageID, string(contentType))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	return &fileObject{File: f, info: info}, nil
+}
+
+func (fs *FileSystemDao) Stat(ctx context.Context, pageID string) (ObjectInfo, error) {
+	object, err := fs.Get(ctx, pageID)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer object.Close()
+	return object.Info(), nil
+}
+
+// Put writes the data read from r to the file of the entry. Files keep no content type, so contentType is
+// written to a hidden file next to it, when given, and guessed as the entry is read otherwise.
+func (fs *FileSystemDao) Put(ctx context.Context, pageID string, r io.Reader, contentType string) (ObjectInfo, error) {
+	if err := fs.write(ctx, pageID, r, contentType); err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	return fs.Stat(ctx, pageID)
+}
+
+// write copies r to a temporary file next to the file of the entry, which is renamed into place once
+// complete, so that readers never see a partial entry. The directory of the route is not swapped by a
+// Commit meanwhile.
+func (fs *FileSystemDao) write(ctx context.Context, pageID string, r io.Reader, contentType string) error {
+	lock := fs.routes.of(pageID)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	filename := filepath.Join(fs.root, pageID)
+	if err := os.MkdirAll(filepath.Dir(filename), fs.mode); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, contextReader{ctx, r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), fs.mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the content type of the previous data of the entry does not carry over to the new one
+	if contentType == "" {
+		err = os.Remove(contentTypeFilename(filename))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		err = ioutil.WriteFile(contentTypeFilename(filename), []byte(contentType), fs.mode)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// CONTENT_TYPE_SUFFIX ends the name of the hidden file that keeps the content type an entry was put with,
+// as in .diagram.content-type for diagram.
+const CONTENT_TYPE_SUFFIX = ".content-type"
+
+func contentTypeFilename(filename string) string {
+	return filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+CONTENT_TYPE_SUFFIX)
+}
+
+func isContentTypeFile(filename string) bool {
+	base := filepath.Base(filename)
+	return strings.HasPrefix(base, ".") && strings.HasSuffix(base, CONTENT_TYPE_SUFFIX)
+}
+
+// fileInfo returns the metadata of the entry id stored in f. Its content type, unless given, is guessed
+// from its extension, or else sniffed from its first bytes.
+func fileInfo(f *os.File, id, contentType string) (ObjectInfo, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	if fi.IsDir() {
+		return ObjectInfo{}, fmt.Errorf("%s is a directory", id)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ObjectInfo{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	if contentType == "" {
+		contentType = ContentType(id, head[:n])
+	}
+	return ObjectInfo{
+		ID:          id,
+		Size:        fi.Size(),
+		ContentType: contentType,
+		ModTime:     fi.ModTime(),
+		ETag:        fmt.Sprintf(`"%x-%x"`, fi.ModTime().UnixNano(), fi.Size()),
+	}, nil
+}
+
+// FetchGlob locks the route of pattern, when it names one, as in /alpha/*.html. Patterns matching several
+// routes may miss the entries of a route while it is swapped by a Commit.
 func (fs *FileSystemDao) FetchGlob(pattern string) []string {
-	globalData.RLock()
-	defer globalData.RUnlock()
+	lock := fs.routes.of(pattern)
+	lock.RLock()
+	defer lock.RUnlock()
 
 	ids := make([]string, 0)
 	files, _ := filepath.Glob(filepath.Join(fs.root, pattern))
 	for i, file := range files {
-		if fi, err := os.Stat(file); err != nil || fi.IsDir() {
+		if fi, err := os.Stat(file); err != nil || fi.IsDir() || isContentTypeFile(file) {
 			continue
 		}
 
@@ -68,14 +220,7 @@ func (fs *FileSystemDao) FetchGlob(pattern string) []string {
 }
 
 func (fs *FileSystemDao) Insert(pageID string, pageData []byte) error {
-	globalData.Lock()
-	defer globalData.Unlock()
-
-	filename := filepath.Join(fs.root, pageID)
-	if err := os.MkdirAll(filepath.Dir(filename), fs.mode); err != nil {
-		return fmt.Errorf("storage.Insert: %v", err)
-	}
-	if err := ioutil.WriteFile(filename, pageData, fs.mode); err != nil {
+	if err := fs.write(context.Background(), pageID, bytes.NewReader(pageData), ""); err != nil {
 		return fmt.Errorf("storage.Insert: %v", err)
 	}
 
@@ -83,63 +228,184 @@ func (fs *FileSystemDao) Insert(pageID string, pageData []byte) error {
 }
 
 func (fs *FileSystemDao) Delete(pageID string) error {
-	globalData.Lock()
-	defer globalData.Unlock()
-
 	filename := filepath.Join(fs.root, pageID)
-	if err := os.Remove(filename); err != nil {
+	lock := fs.routes.of(pageID)
+	lock.RLock()
+	err := os.Remove(filename)
+	os.Remove(contentTypeFilename(filename))
+	lock.RUnlock()
+	if err != nil {
 		return fmt.Errorf("storage.Delete: %v", err)
 	}
 
-	if err := fs.SearchDB.Index.Delete(pageID); err != nil {
+	if err := fs.SearchDB.Unindex(pageID); err != nil {
 		return fmt.Errorf("storage.Delete: %v", err)
 	}
 
 	return nil
 }
 
+// DeleteGlob deletes each match of pattern under the write lock of its route.
 func (fs *FileSystemDao) DeleteGlob(pattern string) error {
-	globalData.Lock()
-	defer globalData.Unlock()
-
 	files, err := filepath.Glob(filepath.Join(fs.root, pattern))
 	if err != nil {
 		return fmt.Errorf("storage.Delete: %v", err)
 	}
 
 	for _, f := range files {
+		// the pages under a directory are unindexed along with it
+		var ids []string
+		filepath.Walk(f, func(path string, fi os.FileInfo, err error) error {
+			if fi != nil && fi.Mode().IsRegular() && !isContentTypeFile(path) {
+				ids = append(ids, path[len(fs.root):])
+			}
+			return nil
+		})
+		lock := fs.routes.of(f[len(fs.root):])
+		lock.Lock()
 		if err := os.RemoveAll(f); err != nil {
 			log.Println(err.Error())
 		}
-		if err := fs.SearchDB.Index.Delete(f); err != nil {
-			log.Println(err.Error())
+		os.Remove(contentTypeFilename(f))
+		lock.Unlock()
+		for _, id := range ids {
+			if err := fs.SearchDB.Unindex(id); err != nil {
+				log.Println(err.Error())
+			}
 		}
 	}
 
 	return nil
 }
 
-func (fs *FileSystemDao) Query(query string) (*QueryResult, error) {
-	globalData.RLock()
-	defer globalData.RUnlock()
-	return fs.SearchDB.Query(query)
+func (fs *FileSystemDao) Query(req QueryRequest) (*QueryResult, error) {
+	return fs.SearchDB.Query(req)
+}
+
+func (fs *FileSystemDao) Index(pageID string, doc SearchDocument) error {
+	return fs.SearchDB.IndexPage(pageID, doc)
+}
+
+func (fs *FileSystemDao) IndexedHashes() (map[string]string, error) {
+	return fs.SearchDB.IndexedHashes()
+}
+
+func (fs *FileSystemDao) Unindex(pageID string) error {
+	return fs.SearchDB.Unindex(pageID)
+}
+
+func (fs *FileSystemDao) ResetIndex() error {
+	return fs.SearchDB.Reset()
+}
+
+// STAGING_DIR holds the staging areas of a FileSystemDao. It lives under the root of the Dao, so that
+// staged routes can be renamed into place.
+const STAGING_DIR = ".staging"
+
+type fileSystemStaging struct {
+	fs     *FileSystemDao
+	prefix string
+	dir    string
+}
+
+// Stage creates a staging area for the entries under prefix. Commit swaps the directory of the staging
+// area with the directory of the prefix.
+func (fs *FileSystemDao) Stage(prefix string) (Staging, error) {
+	if strings.Trim(prefix, "/") == "" {
+		return nil, fmt.Errorf("storage.Stage: cannot stage the root of the dao")
+	}
+
+	parent := filepath.Join(fs.root, STAGING_DIR)
+	if err := os.MkdirAll(parent, fs.mode); err != nil {
+		return nil, fmt.Errorf("storage.Stage: %v", err)
+	}
+	dir, err := ioutil.TempDir(parent, strings.Replace(strings.Trim(prefix, "/"), "/", "_", -1)+"_")
+	if err != nil {
+		return nil, fmt.Errorf("storage.Stage: %v", err)
+	}
+	if err := os.Chmod(dir, fs.mode); err != nil {
+		return nil, fmt.Errorf("storage.Stage: %v", err)
+	}
+	return &fileSystemStaging{fs: fs, prefix: prefix, dir: dir}, nil
+}
+
+func (st *fileSystemStaging) filename(pageID string) (string, error) {
+	rel, err := filepath.Rel(filepath.Join("/", st.prefix), filepath.Join("/", pageID))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is not staged under %s", pageID, st.prefix)
+	}
+	return filepath.Join(st.dir, rel), nil
+}
+
+func (st *fileSystemStaging) Fetch(pageID string) ([]byte, error) {
+	if filepath.Ext(pageID) == "" {
+		pageID = filepath.Join(pageID, "index.html")
+	}
+
+	filename, err := st.filename(pageID)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %v", err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %v", err)
+	}
+	return data, nil
+}
+
+func (st *fileSystemStaging) Insert(pageID string, pageData []byte) error {
+	filename, err := st.filename(pageID)
+	if err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), st.fs.mode); err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	if err := ioutil.WriteFile(filename, pageData, st.fs.mode); err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	return nil
+}
+
+// Commit renames the directory of the prefix out of the way, and the staging area into its place. Both
+// renames happen while holding the write lock of the route, so readers never see the prefix missing.
+func (st *fileSystemStaging) Commit() error {
+	live := filepath.Join(st.fs.root, st.prefix)
+	old := st.dir + ".old"
+	if err := st.swap(live, old); err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+
+	if err := os.RemoveAll(old); err != nil {
+		log.Printf("storage.Commit: failed to remove the previous copy of %s: %v", st.prefix, err)
+	}
+	return nil
 }
 
-func (fs *FileSystemDao) Index(pageID, pageTitle string, pageData []byte) error {
-	globalData.Lock()
-	defer globalData.Unlock()
+func (st *fileSystemStaging) swap(live, old string) error {
+	lock := st.fs.routes.of(st.prefix)
+	lock.Lock()
+	defer lock.Unlock()
 
-	indexData := struct {
-		Title    string    `json:"title"`
-		Body     string    `json:"body"`
-		Modified time.Time `json:"modified"`
-	}{
-		Title:    pageTitle,
-		Body:     string(filterHTMLTags(pageData)),
-		Modified: time.Now(),
+	if err := os.Rename(live, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(live), st.fs.mode); err != nil {
+		os.Rename(old, live)
+		return err
 	}
+	if err := os.Rename(st.dir, live); err != nil {
+		os.Rename(old, live)
+		return err
+	}
+	return nil
+}
 
-	return fs.SearchDB.Index.Index(pageID, indexData)
+func (st *fileSystemStaging) Abort() error {
+	if err := os.RemoveAll(st.dir); err != nil {
+		return fmt.Errorf("storage.Abort: %v", err)
+	}
+	return nil
 }
 
 func (fs *FileSystemDao) Drop() error {
@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileSystemStaging(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs, err := NewFileSystemDao(filepath.Join(dir, "deploy"), 0755, "")
+	if err != nil {
+		t.Fatalf("NewFileSystemDao => %v", err)
+	}
+	for id, data := range map[string]string{"/test/index.html": "old", "/test/removed.html": "old", "/other/index.html": "other"} {
+		if err := fs.Insert(id, []byte(data)); err != nil {
+			t.Fatalf("Insert(%s) => %v", id, err)
+		}
+	}
+
+	// an aborted staging area leaves the route untouched
+	stage, err := fs.Stage("test")
+	if err != nil {
+		t.Fatalf("Stage(test) => %v", err)
+	}
+	if err := stage.Insert("/test/index.html", []byte("aborted")); err != nil {
+		t.Fatalf("Insert => %v", err)
+	}
+	if err := stage.Abort(); err != nil {
+		t.Fatalf("Abort => %v", err)
+	}
+	if data, _ := fs.Fetch("/test/index.html"); string(data) != "old" {
+		t.Errorf("Fetch(/test/index.html) after Abort -> expected: old got: %s", data)
+	}
+
+	stage, err = fs.Stage("test")
+	if err != nil {
+		t.Fatalf("Stage(test) => %v", err)
+	}
+	if err := stage.Insert("/other/index.html", []byte("outside")); err == nil {
+		t.Error("Insert(/other/index.html) -> expected an error for an entry outside of the staged route")
+	}
+	if err := stage.Insert("/test/index.html", []byte("new")); err != nil {
+		t.Fatalf("Insert => %v", err)
+	}
+	if data, _ := fs.Fetch("/test/index.html"); string(data) != "old" {
+		t.Errorf("Fetch(/test/index.html) before Commit -> expected: old got: %s", data)
+	}
+
+	if err := stage.Commit(); err != nil {
+		t.Fatalf("Commit => %v", err)
+	}
+	expected := map[string]string{"/test/index.html": "new", "/test/removed.html": "", "/other/index.html": "other"}
+	for id, want := range expected {
+		if data, _ := fs.Fetch(id); string(data) != want {
+			t.Errorf("Fetch(%s) after Commit -> expected: %q got: %q", id, want, data)
+		}
+	}
+	if staged, _ := ioutil.ReadDir(filepath.Join(dir, "deploy", STAGING_DIR)); len(staged) != 0 {
+		t.Errorf("expected the staging directory to be empty after Commit, got %d entries", len(staged))
+	}
+}
+
+// TestFileSystemDaoConcurrency hammers a FileSystemDao from many goroutines, and is meant to be run with
+// the race detector, as in bin/build.sh. Readers of a route must see every entry while it is committed
+// over and over, and inserts into other routes must not be lost.
+func TestFileSystemDaoConcurrency(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs, err := NewFileSystemDao(filepath.Join(dir, "deploy"), 0755, filepath.Join(dir, "search"))
+	if err != nil {
+		t.Fatalf("NewFileSystemDao => %v", err)
+	}
+	if err := fs.Insert("/busy/index.html", []byte("0")); err != nil {
+		t.Fatalf("Insert => %v", err)
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 100)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	// a route is published over and over
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= n; i++ {
+			stage, err := fs.Stage("busy")
+			if err != nil {
+				report(err)
+				return
+			}
+			if err := stage.Insert("/busy/index.html", []byte(fmt.Sprint(i))); err != nil {
+				report(err)
+			}
+			if err := stage.Commit(); err != nil {
+				report(err)
+			}
+		}
+	}()
+
+	// while its pages are read, and searched
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, err := fs.Fetch("/busy"); err != nil {
+					report(fmt.Errorf("Fetch(/busy) during Commit => %v", err))
+				}
+				if _, err := fs.Query(QueryRequest{Phrase: "page"}); err != nil {
+					report(fmt.Errorf("Query => %v", err))
+				}
+			}
+		}()
+	}
+
+	// and other routes are written, and indexed
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				id := fmt.Sprintf("/route%d/page%d.html", w, i)
+				if err := fs.Insert(id, []byte(id)); err != nil {
+					report(err)
+				}
+				if err := fs.Index(id, SearchDocument{Title: "page", Body: id, Hash: id}); err != nil {
+					report(err)
+				}
+				if data, err := fs.Fetch(id); err != nil || string(data) != id {
+					report(fmt.Errorf("Fetch(%s) -> expected: %s got: %q (%v)", id, id, data, err))
+				}
+			}
+		}(w)
+	}
+
+	time.AfterFunc(time.Second, func() { close(done) })
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if data, _ := fs.Fetch("/busy"); string(data) != fmt.Sprint(n) {
+		t.Errorf("Fetch(/busy) -> expected: %d got: %q", n, data)
+	}
+	if hashes, err := fs.IndexedHashes(); err != nil || len(hashes) != 4*n {
+		t.Errorf("IndexedHashes -> expected: %d pages got: %d (%v)", 4*n, len(hashes), err)
+	}
+}
+
+func TestFileSystemDaoRouteLocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs, err := NewFileSystemDao(filepath.Join(dir, "deploy"), 0755, filepath.Join(dir, "search"))
+	if err != nil {
+		t.Fatalf("NewFileSystemDao => %v", err)
+	}
+	for _, id := range []string{"/alpha/index.html", "/beta/index.html"} {
+		if err := fs.Insert(id, []byte(id)); err != nil {
+			t.Fatalf("Insert(%s) => %v", id, err)
+		}
+	}
+
+	if fs.routes.of("/alpha/v1/index.html") != fs.routes.of("alpha") {
+		t.Error("routes.of(/alpha/v1/index.html) -> expected the lock of alpha")
+	}
+	if fs.routes.of("alpha") == fs.routes.of("beta") {
+		t.Fatal("Terminating test due to alpha and beta sharing a lock stripe")
+	}
+
+	// a route held by a Commit blocks its own readers only
+	lock := fs.routes.of("alpha")
+	lock.Lock()
+	finished := make(chan string, 4)
+	go func() {
+		fs.Fetch("/alpha/index.html")
+		finished <- "Fetch(/alpha/index.html)"
+	}()
+	go func() {
+		fs.Fetch("/beta/index.html")
+		fs.Insert("/beta/page.html", []byte("page"))
+		fs.Index("/beta/page.html", SearchDocument{Title: "page", Hash: "hash"})
+		fs.Query(QueryRequest{Phrase: "page"})
+		finished <- "beta"
+	}()
+
+	select {
+	case op := <-finished:
+		if op != "beta" {
+			t.Errorf("%s -> expected to wait for the lock of alpha", op)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reads and writes of beta -> expected not to wait for the lock of alpha")
+	}
+	lock.Unlock()
+	if op := <-finished; op != "Fetch(/alpha/index.html)" {
+		t.Errorf("expected Fetch(/alpha/index.html) to finish once alpha is unlocked, got: %s", op)
+	}
+}
+
+func TestFileSystemDaoContentType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs, err := NewFileSystemDao(filepath.Join(dir, "deploy"), 0755, "")
+	if err != nil {
+		t.Fatalf("NewFileSystemDao => %v", err)
+	}
+
+	// the extension tells nothing of the type, and the data would be sniffed as text
+	ctx := context.Background()
+	id := "/alpha/files/schema.blob"
+	if _, err := fs.Put(ctx, id, strings.NewReader(`{"type": "object"}`), "application/schema+json"); err != nil {
+		t.Fatalf("Put(%s) => %v", id, err)
+	}
+	if info, err := fs.Stat(ctx, id); err != nil || info.ContentType != "application/schema+json" {
+		t.Errorf("Stat(%s) -> expected: application/schema+json got: %+v (%v)", id, info, err)
+	}
+	object, err := fs.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get(%s) => %v", id, err)
+	}
+	object.Close()
+	if contentType := object.Info().ContentType; contentType != "application/schema+json" {
+		t.Errorf("Get(%s) -> expected: application/schema+json got: %s", id, contentType)
+	}
+	if ids := fs.FetchGlob("/alpha/files/*"); !reflect.DeepEqual(ids, []string{id}) {
+		t.Errorf("FetchGlob(/alpha/files/*) -> expected: %v got: %v", []string{id}, ids)
+	}
+
+	// data inserted without a content type has its own guessed
+	if err := fs.Insert(id, []byte("plain")); err != nil {
+		t.Fatalf("Insert(%s) => %v", id, err)
+	}
+	if info, err := fs.Stat(ctx, id); err != nil || info.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("Stat(%s) after Insert -> expected: text/plain; charset=utf-8 got: %+v (%v)", id, info, err)
+	}
+
+	if _, err := fs.Put(ctx, id, strings.NewReader(`{}`), "application/json"); err != nil {
+		t.Fatalf("Put(%s) => %v", id, err)
+	}
+	if err := fs.Delete(id); err != nil {
+		t.Fatalf("Delete(%s) => %v", id, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "deploy", "alpha", "files", "*")); len(files) != 0 {
+		t.Errorf("Delete(%s) -> expected no files left, got: %v", id, files)
+	}
+}
@@ -9,12 +9,13 @@ import (
 // MockDao should only be used for testing purposes
 type MockDao struct {
 	Dao
-	root  string
-	pages map[string][]byte
+	root    string
+	pages   map[string][]byte
+	indexed map[string]SearchDocument
 }
 
 func NewMockDao(root string) *MockDao {
-	return &MockDao{root: root, pages: make(map[string][]byte)}
+	return &MockDao{root: root, pages: make(map[string][]byte), indexed: make(map[string]SearchDocument)}
 }
 
 func (m *MockDao) Insert(id string, data []byte) error {
@@ -22,25 +23,129 @@ func (m *MockDao) Insert(id string, data []byte) error {
 	return nil
 }
 
-func (m *MockDao) Index(id, title string, data []byte) error {
+func (m *MockDao) Index(id string, doc SearchDocument) error {
+	m.indexed[id] = doc
+	return nil
+}
+
+func (m *MockDao) IndexedHashes() (map[string]string, error) {
+	hashes := make(map[string]string, len(m.indexed))
+	for id, doc := range m.indexed {
+		hashes[id] = doc.Hash
+	}
+	return hashes, nil
+}
+
+func (m *MockDao) Unindex(id string) error {
+	delete(m.indexed, id)
+	return nil
+}
+
+func (m *MockDao) ResetIndex() error {
+	m.indexed = make(map[string]SearchDocument)
 	return nil
 }
 
 func (m *MockDao) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
 	data, ok := m.pages[filepath.Join(m.root, id)]
 	if !ok {
-		return nil, errors.New(id + " not found")
+		return nil, &NotFoundError{Op: "storage.Fetch", ID: id}
 	}
 	return data, nil
 }
 
+func (m *MockDao) Delete(id string) error {
+	if _, ok := m.pages[filepath.Join(m.root, id)]; !ok {
+		return errors.New(id + " not found")
+	}
+	delete(m.pages, filepath.Join(m.root, id))
+	delete(m.indexed, id)
+	return nil
+}
+
+// id returns the ID of the entry stored under key.
+func (m *MockDao) id(key string) string {
+	return filepath.Join("/", strings.TrimPrefix(key, m.root))
+}
+
 func (m *MockDao) FetchGlob(pattern string) []string {
-	var pages []string
-	for id := range m.pages {
-		if strings.HasPrefix(id, m.
-			root+pattern) {
+	pages := make([]string, 0)
+	for key := range m.pages {
+		if id := m.id(key); filepath.Ext(id) != "" && globMatch(pattern, id, false) {
 			pages = append(pages, id)
 		}
 	}
 	return pages
 }
+
+func (m *MockDao) DeleteGlob(pattern string) error {
+	for key := range m.pages {
+		if id := m.id(key); globMatch(pattern, id, true) {
+			delete(m.pages, key)
+			delete(m.indexed, id)
+		}
+	}
+	return nil
+}
+
+func (m *MockDao) Drop() error {
+	m.pages = make(map[string][]byte)
+	return nil
+}
+
+type mockStaging struct {
+	dao    *MockDao
+	prefix string
+	pages  map[string][]byte
+}
+
+func (m *MockDao) Stage(prefix string) (Staging, error) {
+	if strings.Trim(prefix, "/") == "" {
+		return nil, errors.New("cannot stage the root of the dao")
+	}
+	return &mockStaging{dao: m, prefix: filepath.Join(m.root, "/", prefix), pages: make(map[string][]byte)}, nil
+}
+
+func (st *mockStaging) staged(key string) bool {
+	return key == st.prefix || strings.HasPrefix(key, st.prefix+"/")
+}
+
+func (st *mockStaging) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	data, ok := st.pages[filepath.Join(st.dao.root, id)]
+	if !ok {
+		return nil, errors.New(id + " not found")
+	}
+	return data, nil
+}
+
+func (st *mockStaging) Insert(id string, data []byte) error {
+	key := filepath.Join(st.dao.root, id)
+	if !st.staged(key) {
+		return errors.New(id + " is not staged under " + st.prefix)
+	}
+	st.pages[key] = data
+	return nil
+}
+
+func (st *mockStaging) Commit() error {
+	for key := range st.dao.pages {
+		if st.staged(key) {
+			delete(st.dao.pages, key)
+		}
+	}
+	for key, data := range st.pages {
+		st.dao.pages[key] = data
+	}
+	return nil
+}
+
+func (st *mockStaging) Abort() error {
+	st.pages = nil
+	return nil
+}
@@ -1,10 +1,13 @@
 package storage
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"html"
+	"io"
+	"io/ioutil"
 	"log"
 	"net"
 	"path/filepath"
@@ -19,6 +22,7 @@ type MongoDBDao struct {
 	Session           *mgo.Session
 	Idx               mgo.Index
 	defaultCollection string
+	routes            routeLocks
 	*SearchDB
 }
 
@@ -84,15 +88,86 @@ func (mongo *MongoDBDao) Fetch(id string) ([]byte, error) {
 		return nil, fmt.Errorf("storage.Fetch: %v", err)
 	}
 
+	return fetchPage(collection, id)
+}
+
+func fetchPage(collection *mgo.Collection, id string) ([]byte, error) {
+	_, data, err := findPage(collection, id)
+	if err == mgo.ErrNotFound {
+		return nil, &NotFoundError{Op: "storage.Fetch", ID: id}
+	} else if err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %v", err)
+	}
+	return data, nil
+}
+
+func findPage(collection *mgo.Collection, id string) (page, []byte, error) {
 	var p page
 	if err := collection.Find(bson.M{"page_id": id}).One(&p); err != nil {
-		return nil, fmt.Errorf("storage.Fetch: %v", err)
+		return p, nil, err
 	}
 
 	if filepath.Ext(p.ID) == ".html" {
-		return []byte(html.UnescapeString(p.Data)), nil
+		return p, []byte(html.UnescapeString(p.Data)), nil
+	}
+	return p, []byte(p.Data), nil
+}
+
+// Get reads an entry, with the metadata stored along with it. Entries inserted before metadata was
+// stored have theirs computed from their data, without a modification time.
+func (mongo *MongoDBDao) Get(ctx context.Context, id string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+
+	collection, err := mongo.getCollection(parseCollection(id))
+	if err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
 	}
-	return []byte(p.Data), nil
+	defer collection.Database.Session.Close()
+	p, data, err := findPage(collection, id)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	return newBytesObject(ObjectInfo{ID: id, ContentType: p.ContentType, ModTime: p.ModTime, ETag: p.ETag}, data), nil
+}
+
+func (mongo *MongoDBDao) Stat(ctx context.Context, id string) (ObjectInfo, error) {
+	object, err := mongo.Get(ctx, id)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer object.Close()
+	return object.Info(), nil
+}
+
+// Put reads r into memory, since entries are stored as single documents, and stores it with its metadata.
+func (mongo *MongoDBDao) Put(ctx context.Context, id string, r io.Reader, contentType string) (ObjectInfo, error) {
+	data, err := ioutil.ReadAll(contextReader{ctx, r})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	lock := mongo.routes.of(id)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	collection, err := mongo.getCollection(parseCollection(id))
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	defer collection.Database.Session.Close()
+
+	p := NewPage(id, data)
+	if contentType != "" {
+		p.ContentType = contentType
+	}
+	if err := upsertPage(collection, p); err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	return ObjectInfo{ID: id, Size: p.Size, ContentType: p.ContentType, ModTime: p.ModTime, ETag: p.ETag}, nil
 }
 
 func (mongo *MongoDBDao) FetchGlob(pattern string) []string {
@@ -116,25 +191,38 @@ func (mongo *MongoDBDao) FetchGlob(pattern string) []string {
 }
 
 func (mongo *MongoDBDao) Insert(id string, data []byte) error {
+	lock := mongo.routes.of(id)
+	lock.RLock()
+	defer lock.RUnlock()
+
 	collection, err := mongo.getCollection(parseCollection(id))
 	if err != nil {
 		return fmt.Errorf("storage.Insert: %v", err)
 	}
 
-	if _, err := collection.Upsert(bson.M{"page_id": id}, bson.M{"$set": NewPage(id, data)}); err != nil {
+	return insertPage(collection, id, data)
+}
+
+func insertPage(collection *mgo.Collection, id string, data []byte) error {
+	if err := upsertPage(collection, NewPage(id, data)); err != nil {
 		return fmt.Errorf("storage.Insert: %v", err)
 	}
 
 	return nil
 }
 
+func upsertPage(collection *mgo.Collection, p page) error {
+	_, err := collection.Upsert(bson.M{"page_id": p.ID}, bson.M{"$set": p})
+	return err
+}
+
 func (mongo *MongoDBDao) Delete(id string) error {
 	collection, err := mongo.getCollection(parseCollection(id))
 	if err != nil {
 		return fmt.Errorf("storage.Delete: %v", err)
 	}
 
-	if err := mongo.SearchDB.Index.Delete(id); err != nil {
+	if err := mongo.SearchDB.Unindex(id); err != nil {
 		return fmt.Errorf("storage.Delete: %v", err)
 	}
 
@@ -146,6 +234,10 @@ func (mongo *MongoDBDao) Delete(id string) error {
 }
 
 func (mongo *MongoDBDao) DeleteGlob(pattern string) error {
+	lock := mongo.routes.of(pattern)
+	lock.Lock()
+	defer lock.Unlock()
+
 	collection, err := mongo.getCollection(parseCollection(pattern))
 	if err != nil {
 		return fmt.Errorf("storage.DeleteGlob: %v", err)
@@ -157,7 +249,7 @@ func (mongo *MongoDBDao) DeleteGlob(pattern string) error {
 	}
 
 	for _, p := range pages {
-		if err := mongo.SearchDB.Index.Delete(p.ID); err != nil {
+		if err := mongo.SearchDB.Unindex(p.ID); err != nil {
 			return fmt.Errorf("storage.Delete: %v", err)
 		}
 	}
@@ -169,41 +261,147 @@ func (mongo *MongoDBDao) DeleteGlob(pattern string) error {
 	return nil
 }
 
-func (mongo *MongoDBDao) Index(id, title string, data []byte) error {
-	globalData.Lock()
-	defer globalData.Unlock()
+func (mongo *MongoDBDao) Index(id string, doc SearchDocument) error {
+	if err := mongo.SearchDB.IndexPage(id, doc); err != nil {
+		return fmt.Errorf("storage.Index: %v", err)
+	}
+
+	return nil
+}
+
+func (mongo *MongoDBDao) Query(req QueryRequest) (*QueryResult, error) {
+	return mongo.SearchDB.Query(req)
+}
+
+func (mongo *MongoDBDao) IndexedHashes() (map[string]string, error) {
+	return mongo.SearchDB.IndexedHashes()
+}
 
-	indexData := struct {
-		Title    string    `json:"title"`
-		Body     string    `json:"body"`
-		Modified time.Time `json:"modified"`
-	}{
-		Title:    title,
-		Body:     string(filterHTMLTags(data)),
-		Modified: time.Now(),
+func (mongo *MongoDBDao) Unindex(id string) error {
+	if err := mongo.SearchDB.Unindex(id); err != nil {
+		return fmt.Errorf("storage.Unindex: %v", err)
 	}
+	return nil
+}
 
-	if err := mongo.SearchDB.Index.Index(id, indexData); err != nil {
-		return fmt.Errorf("storage.Index: %v", err)
+func (mongo *MongoDBDao) ResetIndex() error {
+	if err := mongo.SearchDB.Reset(); err != nil {
+		return fmt.Errorf("storage.ResetIndex: %v", err)
+	}
+	return nil
+}
+
+type mongoStaging struct {
+	mongo   *MongoDBDao
+	prefix  string
+	live    string
+	staging *mgo.Collection
+}
+
+// Stage creates a staging collection for the entries under prefix. Commit copies the entries of the live
+// collection outside of prefix into the staging collection, and renames it over the live collection.
+func (mongo *MongoDBDao) Stage(prefix string) (Staging, error) {
+	prefix = filepath.Join("/", prefix)
+	live := parseCollection(prefix)
+	if live == "" || live == "root" {
+		return nil, fmt.Errorf("storage.Stage: cannot stage %s", prefix)
 	}
 
+	staging, err := mongo.getCollection(fmt.Sprintf("staging_%s_%d", live, time.Now().UnixNano()))
+	if err != nil {
+		return nil, fmt.Errorf("storage.Stage: %v", err)
+	}
+	return &mongoStaging{mongo: mongo, prefix: prefix, live: live, staging: staging}, nil
+}
+
+func (st *mongoStaging) staged(id string) bool {
+	return id == st.prefix || strings.HasPrefix(id, st.prefix+"/")
+}
+
+func (st *mongoStaging) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	if !st.staged(id) {
+		return nil, fmt.Errorf("storage.Fetch: %s is not staged under %s", id, st.prefix)
+	}
+	return fetchPage(st.staging, id)
+}
+
+func (st *mongoStaging) Insert(id string, data []byte) error {
+	if !st.staged(id) {
+		return fmt.Errorf("storage.Insert: %s is not staged under %s", id, st.prefix)
+	}
+	return insertPage(st.staging, id, data)
+}
+
+// Commit holds the write lock of the route, so that entries inserted into the live collection meanwhile
+// are not lost.
+func (st *mongoStaging) Commit() error {
+	lock := st.mongo.routes.of(st.prefix)
+	lock.Lock()
+	defer lock.Unlock()
+
+	live, err := st.mongo.getCollection(st.live)
+	if err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+
+	// other versions of a route share its collection, so they are carried over into the staging collection
+	var doc bson.M
+	iter := live.Find(nil).Iter()
+	for iter.Next(&doc) {
+		if id, _ := doc["page_id"].(string); !st.staged(id) {
+			delete(doc, "_id")
+			if err := st.staging.Insert(doc); err != nil {
+				iter.Close()
+				return fmt.Errorf("storage.Commit: %v", err)
+			}
+		}
+		doc = nil
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+
+	db := st.staging.Database.Name
+	rename := bson.D{
+		{Name: "renameCollection", Value: db + "." + st.staging.Name},
+		{Name: "to", Value: db + "." + st.live},
+		{Name: "dropTarget", Value: true},
+	}
+	if err := st.staging.Database.Session.DB("admin").Run(rename, nil); err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
 	return nil
 }
 
-func (mongo *MongoDBDao) Query(query string) (*QueryResult, error) {
-	globalData.RLock()
-	defer globalData.RUnlock()
-	return mongo.SearchDB.Query(query)
+func (st *mongoStaging) Abort() error {
+	if err := st.staging.DropCollection(); err != nil {
+		return fmt.Errorf("storage.Abort: %v", err)
+	}
+	return nil
 }
 
 type page struct {
-	ID    string `json:"page_id"`
-	Route string `json:"page_route"`
-	Data  string `json:"page_data"`
+	ID          string    `json:"page_id"`
+	Route       string    `json:"page_route"`
+	Data        string    `json:"page_data"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ModTime     time.Time `json:"mod_time"`
+	ETag        string    `json:"etag"`
 }
 
 func NewPage(id string, data []byte) page {
-	p := page{ID: id, Route: parseCollection(id)}
+	p := page{
+		ID:          id,
+		Route:       parseCollection(id),
+		Size:        int64(len(data)),
+		ContentType: ContentType(id, data),
+		ModTime:     time.Now().UTC().Truncate(time.Millisecond), // the precision of BSON dates
+		ETag:        dataETag(data),
+	}
 	if filepath.Ext(id) == ".html" {
 		p.Data = html.EscapeString(string(json.RawMessage(data)))
 	} else {
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+)
+
+// ObjectInfo is the metadata of an entry of a Dao.
+type ObjectInfo struct {
+	ID          string    `json:"id"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ModTime     time.Time `json:"mod_time"` // zero when the backend does not keep it
+	ETag        string    `json:"etag"`     // quoted, as in an ETag header
+}
+
+// Object is an entry of a Dao opened for reading. Backends that can stream its data from storage do so,
+// rather than reading it into memory first.
+type Object interface {
+	io.ReadSeeker
+	io.Closer
+	Info() ObjectInfo
+}
+
+// ObjectDao is the streaming API of a Dao, whose methods carry the metadata of entries and give up once
+// their context is done. Get, like Fetch, opens the index.html of an ID without an extension.
+type ObjectDao interface {
+	Dao
+	Get(ctx context.Context, id string) (Object, error)
+	Stat(ctx context.Context, id string) (ObjectInfo, error)
+	// Put stores the data read from r under id. The content type is guessed from id and the data when
+	// contentType is "".
+	Put(ctx context.Context, id string, r io.Reader, contentType string) (ObjectInfo, error)
+}
+
+// Objects returns the streaming API of dao. Backends that do not implement it natively are adapted
+// through Fetch and Insert, which buffer every entry in memory.
+func Objects(dao Dao) ObjectDao {
+	if objects, ok := dao.(ObjectDao); ok {
+		return objects
+	}
+	return objectAdapter{dao}
+}
+
+type objectAdapter struct {
+	Dao
+}
+
+func (a objectAdapter) Get(ctx context.Context, id string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	data, err := a.Fetch(id)
+	if err != nil {
+		return nil, err
+	}
+	if path.Ext(id) == "" {
+		id = path.Join(id, "index.html")
+	}
+	return newBytesObject(ObjectInfo{ID: id}, data), nil
+}
+
+func (a objectAdapter) Stat(ctx context.Context, id string) (ObjectInfo, error) {
+	object, err := a.Get(ctx, id)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer object.Close()
+	return object.Info(), nil
+}
+
+func (a objectAdapter) Put(ctx context.Context, id string, r io.Reader, contentType string) (ObjectInfo, error) {
+	data, err := ioutil.ReadAll(contextReader{ctx, r})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	if err := a.Insert(id, data); err != nil {
+		return ObjectInfo{}, err
+	}
+	return newBytesObject(ObjectInfo{ID: id, ContentType: contentType}, data).Info(), nil
+}
+
+type bytesObject struct {
+	*bytes.Reader
+	info ObjectInfo
+}
+
+// newBytesObject returns an Object reading data, whose size, content type and ETag are filled in from
+// data when info lacks them.
+func newBytesObject(info ObjectInfo, data []byte) Object {
+	info.Size = int64(len(data))
+	if info.ContentType == "" {
+		info.ContentType = ContentType(info.ID, data)
+	}
+	if info.ETag == "" {
+		info.ETag = dataETag(data)
+	}
+	return &bytesObject{Reader: bytes.NewReader(data), info: info}
+}
+
+func (o *bytesObject) Info() ObjectInfo { return o.info }
+func (o *bytesObject) Close() error     { return nil }
+
+// ContentType returns the content type of the entry id, from its extension, or else by sniffing head,
+// the first bytes of its data.
+func ContentType(id string, head []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(id)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(head)
+}
+
+func dataETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// contextReader fails reads once its context is done, so that copying a large upload stops early.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Opener opens the Dao at the given URL of its backend, with its search index stored in searchDir. The
+// Dao has no search index when searchDir is "".
+type Opener func(rawurl, searchDir string) (Dao, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]Opener)
+)
+
+func init() {
+	Register("file", openFileSystemDao)
+	Register("mongodb", openMongoDBDao)
+	Register("bolt", openBoltDao)
+	Register("s3", openS3Dao)
+}
+
+// Register makes a backend available to Open under the scheme of its URLs, as in bolt for
+// bolt:///var/udocs/udocs.db. Registering a scheme twice replaces its backend.
+func Register(scheme string, open Opener) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[strings.ToLower(scheme)] = open
+}
+
+// Schemes returns the schemes of the registered backends, sorted.
+func Schemes() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	schemes := make([]string, 0, len(backends))
+	for scheme := range backends {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open opens the Dao at the given URL with the backend registered for its scheme.
+func Open(rawurl, searchDir string) (Dao, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Open: invalid storage URL %q: %v", rawurl, err)
+	}
+
+	backendsMu.RLock()
+	open, ok := backends[strings.ToLower(u.Scheme)]
+	backendsMu.RUnlock()
+	if !ok {
+		// bolt is the embedded backend, which needs neither cgo nor a server, as sqlite would
+		if strings.HasPrefix(strings.ToLower(u.Scheme), "sqlite") {
+			return nil, fmt.Errorf("storage.Open: there is no sqlite backend for %q, use bolt:// for a single file of embedded storage, as in bolt:///var/lib/udocs/udocs.db", rawurl)
+		}
+		return nil, fmt.Errorf("storage.Open: no backend for %q, expected one of %s", rawurl, strings.Join(schemeURLs(), ", "))
+	}
+	return open(rawurl, searchDir)
+}
+
+// IsLocal reports whether the backend of the storage URL keeps its entries on the disk of the server that
+// opens it, as file:// and bolt:// do, rather than sharing them between servers, as mongodb:// and s3:// do.
+func IsLocal(rawurl string) bool {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "file", "bolt":
+		return true
+	}
+	return false
+}
+
+// urlPath returns the path of a file URL, as in /var/udocs for file:///var/udocs. Relative paths, as in
+// file://deploy, are kept relative.
+func urlPath(rawurl string) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	path := u.Host + u.Path
+	if path == "" {
+		return "", fmt.Errorf("%q has no path", rawurl)
+	}
+	return path, nil
+}
+
+func schemeURLs() []string {
+	schemes := Schemes()
+	for i, scheme := range schemes {
+		schemes[i] = scheme + "://"
+	}
+	return schemes
+}
+
+func openFileSystemDao(rawurl, searchDir string) (Dao, error) {
+	root, err := urlPath(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Open: %v", err)
+	}
+	dao, err := NewFileSystemDao(root, 0755, searchDir)
+	if err != nil {
+		return nil, err
+	}
+	return dao, nil
+}
+
+func openMongoDBDao(rawurl, searchDir string) (Dao, error) {
+	dao, err := NewMongoDBDao(rawurl, searchDir)
+	if err != nil {
+		return nil, err
+	}
+	return dao, nil
+}
+
+func openBoltDao(rawurl, searchDir string) (Dao, error) {
+	filename, err := urlPath(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Open: %v", err)
+	}
+	dao, err := NewBoltDao(filename, searchDir)
+	if err != nil {
+		return nil, err
+	}
+	return dao, nil
+}
+
+func openS3Dao(rawurl, searchDir string) (Dao, error) {
+	dao, err := NewS3Dao(rawurl, searchDir)
+	if err != nil {
+		return nil, err
+	}
+	return dao, nil
+}
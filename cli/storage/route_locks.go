@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"hash/fnv"
+	"path"
+	"sync"
+)
+
+// ROUTE_LOCK_STRIPES is the number of locks a Dao spreads its routes over. Routes sharing a stripe block
+// each other's commits, which is rare enough with more stripes than routes being published at once.
+const ROUTE_LOCK_STRIPES = 64
+
+// routeLocks holds the locks of the routes of a Dao, hashed into a fixed number of stripes so that
+// requests for arbitrary routes cost no memory. Entries of a route are read and written under its read
+// lock, since single files are replaced atomically, while swapping or deleting the directory of the route
+// takes its write lock. Publishing one route thus rarely stalls readers of another.
+type routeLocks struct {
+	stripes [ROUTE_LOCK_STRIPES]sync.RWMutex
+}
+
+// of returns the lock of the route of id, as in alpha for /alpha/v1/index.html.
+func (rl *routeLocks) of(id string) *sync.RWMutex {
+	h := fnv.New32a()
+	h.Write([]byte(parseCollection(path.Join("/", id))))
+	return &rl.stripes[h.Sum32()%ROUTE_LOCK_STRIPES]
+}
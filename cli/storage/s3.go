@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3_BATCH_SIZE is the largest number of objects S3 deletes in one request.
+const S3_BATCH_SIZE = 1000
+
+// S3_TIMEOUT bounds waiting for the response to a request, and the whole of the requests whose response is
+// read at once. Object bodies streamed to readers are only bounded by the context of their request.
+const S3_TIMEOUT = time.Minute
+
+// errS3NotFound is returned for objects that do not exist.
+var errS3NotFound = errors.New("not found")
+
+// s3Client is a client of the subset of the S3 API a S3Dao needs, signed with AWS Signature Version 4.
+// Buckets are addressed by path, as in https://endpoint/bucket/key, which S3-compatible stores support.
+type s3Client struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+	http      *http.Client
+	timeout   time.Duration
+	now       func() time.Time
+}
+
+// newS3HTTPClient returns a client with no overall timeout, which would cut off object bodies streamed to
+// slow readers, but with bounds on connecting and on waiting for the headers of a response.
+func newS3HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConnsPerHost:   16,
+	}}
+}
+
+func (c *s3Client) get(key string) ([]byte, error) {
+	return c.do(http.MethodGet, key, nil, nil, nil)
+}
+
+// put stores data as the object key, with the given content type unless it is "".
+func (c *s3Client) put(key string, data []byte, contentType string) error {
+	var header http.Header
+	if contentType != "" {
+		header = http.Header{"Content-Type": {contentType}}
+	}
+	_, err := c.do(http.MethodPut, key, nil, header, data)
+	return err
+}
+
+func (c *s3Client) exists(key string) (bool, error) {
+	_, err := c.do(http.MethodHead, key, nil, nil, nil)
+	if err == errS3NotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *s3Client) delete(key string) error {
+	_, err := c.do(http.MethodDelete, key, nil, nil, nil)
+	return err
+}
+
+// list returns the keys of the objects under prefix, sorted.
+func (c *s3Client) list(prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		data, err := c.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("invalid listing of %s: %v", prefix, err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	return keys, nil
+}
+
+// deleteBatch deletes the objects of keys, S3_BATCH_SIZE objects per request.
+func (c *s3Client) deleteBatch(keys []string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > S3_BATCH_SIZE {
+			n = S3_BATCH_SIZE
+		}
+
+		var body struct {
+			XMLName xml.Name `xml:"Delete"`
+			Quiet   bool     `xml:"Quiet"`
+			Objects []struct {
+				Key string `xml:"Key"`
+			} `xml:"Object"`
+		}
+		body.Quiet = true
+		for _, key := range keys[:n] {
+			body.Objects = append(body.Objects, struct {
+				Key string `xml:"Key"`
+			}{key})
+		}
+		data, err := xml.Marshal(body)
+		if err != nil {
+			return err
+		}
+		sum := md5.Sum(data)
+		header := http.Header{"Content-Md5": {base64.StdEncoding.EncodeToString(sum[:])}}
+
+		resp, err := c.do(http.MethodPost, "", url.Values{"delete": {""}}, header, data)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			Errors []struct {
+				Key     string
+				Message string
+			} `xml:"Error"`
+		}
+		if err := xml.Unmarshal(resp, &result); err == nil && len(result.Errors) > 0 {
+			return fmt.Errorf("failed to delete %s: %s", result.Errors[0].Key, result.Errors[0].Message)
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+// do sends a signed request for the object key, or the bucket when key is "", and returns the body of the
+// response, within the timeout of the client. It returns errS3NotFound for a 404.
+func (c *s3Client) do(method, key string, query url.Values, header http.Header, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	resp, err := c.send(ctx, method, key, query, header, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// send is like do, but returns the response, whose body the caller streams and closes.
+func (c *s3Client) send(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = "/" + c.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.sign(req, body)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errS3NotFound
+	}
+	var s3Err struct {
+		Code    string
+		Message string
+	}
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, u.Path, s3Err.Code, s3Err.Message)
+	}
+	return nil, fmt.Errorf("%s %s: %s", method, u.Path, resp.Status)
+}
+
+// sign adds the headers of Signature Version 4 to req, whose body is body.
+func (c *s3Client) sign(req *http.Request, body []byte) {
+	now := c.now().UTC()
+	sum := sha256.Sum256(body)
+	payload := hex.EncodeToString(sum[:])
+
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+	if c.token != "" {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	names = append(names, "host")
+	sort.Strings(names)
+
+	canonical := s3CanonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, req.URL.Host, req.Header, names, payload)
+	req.Header.Set("Authorization", c.authorization(now, names, canonical))
+}
+
+// authorization returns the Authorization header of the canonical request, whose signed headers are names.
+func (c *s3Client) authorization(now time.Time, names []string, canonical string) string {
+	date := now.Format("20060102")
+	scope := date + "/" + c.region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := []byte("AWS4" + c.secretKey)
+	for _, part := range []string{date, c.region, "s3", "aws4_request"} {
+		key = s3HMAC(key, part)
+	}
+	signature := hex.EncodeToString(s3HMAC(key, toSign))
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s", c.accessKey, scope, strings.Join(names, ";"), signature)
+}
+
+// s3CanonicalRequest returns the canonical form of a request that Signature Version 4 signs, which
+// S3 computes again from the request it receives.
+func s3CanonicalRequest(method, path, query, host string, header http.Header, names []string, payload string) string {
+	var buf bytes.Buffer
+	buf.WriteString(method + "\n" + path + "\n" + query + "\n")
+	for _, name := range names {
+		value := host
+		if name != "host" {
+			value = strings.Join(header[http.CanonicalHeaderKey(name)], ",")
+		}
+		buf.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	buf.WriteString("\n" + strings.Join(names, ";") + "\n" + payload)
+	return buf.String()
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	var params []string
+	for k, vs := range query {
+		for _, v := range vs {
+			params = append(params, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+func s3EscapePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes every byte of s but the unreserved characters of RFC 3986, and slashes unless
+// escapeSlash is set.
+func s3Escape(s string, escapeSlash bool) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			buf.WriteByte(b)
+		case b == '/' && !escapeSlash:
+			buf.WriteByte(b)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", b)
+		}
+	}
+	return buf.String()
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
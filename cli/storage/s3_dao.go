@@ -0,0 +1,813 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mholt/archiver"
+)
+
+const (
+	// S3_HEADS_TTL is how long a S3Dao trusts its copy of the heads of the bucket, which other servers
+	// sharing the bucket may commit to, before listing them again.
+	S3_HEADS_TTL = 2 * time.Second
+	// S3_SNAPSHOT_DELAY is how long a S3Dao waits for the search index to settle after a change before
+	// uploading a snapshot of it, so that a build is snapshotted once rather than once per page.
+	S3_SNAPSHOT_DELAY = 10 * time.Second
+	// S3_GC_DELAY is how long the objects replaced by a Commit are kept before a later Commit deletes them,
+	// for the servers that still resolve entries from heads listed up to S3_HEADS_TTL before it, and for
+	// the reads of large entries in progress.
+	S3_GC_DELAY = 5 * time.Minute
+
+	s3PagesDir       = "pages/"
+	s3GenerationsDir = "generations/"
+	s3HeadsDir       = "heads/"
+	s3RetiredDir     = "retired/"
+	s3SearchSnapshot = "search.tar.gz"
+)
+
+// S3Dao stores entries as the objects of a bucket of S3, or of an S3-compatible store, for servers without
+// persistent disks. It is opened from a URL such as s3://bucket/prefix?region=eu-west-1, whose optional
+// endpoint parameter points it at another store, as in endpoint=http://localhost:9000. Credentials come
+// from the user info of the URL, or else from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN.
+//
+// Entries are stored under pages/ by their ID. A route that is staged is uploaded under generations/ as a
+// generation of its own, which Commit makes current by writing a head for it under heads/, the head of
+// the most recent generation covering an entry winning. What a Commit replaces is listed under retired/,
+// and deleted by the first Commit S3_GC_DELAY later. The search index stays on local disk, and a snapshot
+// of it is uploaded to the bucket as it changes, for new servers to start from.
+type S3Dao struct {
+	client  *s3Client
+	prefix  string        // the prefix of every key of the Dao, as in udocs/
+	gcDelay time.Duration // S3_GC_DELAY, but for tests
+
+	mu     sync.Mutex
+	heads  map[string]string // the current generation of each staged prefix
+	listed time.Time
+
+	dirty  chan struct{}
+	closed chan struct{}
+	done   chan struct{}
+	*SearchDB
+}
+
+// NewS3Dao opens the Dao at rawurl, with its search index in searchDir. When there is no index in
+// searchDir, the latest snapshot of the bucket, if any, is downloaded into it.
+func NewS3Dao(rawurl string, searchDir string) (*S3Dao, error) {
+	client, prefix, err := newS3Client(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("storage.NewS3Dao: %v", err)
+	}
+	s3 := &S3Dao{client: client, prefix: prefix, gcDelay: S3_GC_DELAY, heads: make(map[string]string)}
+	if _, err := s3.loadHeads(); err != nil {
+		return nil, fmt.Errorf("storage.NewS3Dao: failed to list %s: %v", rawurl, err)
+	}
+
+	if searchDir != "" {
+		if _, err := os.Stat(searchDir); os.IsNotExist(err) {
+			if err := s3.restoreSnapshot(searchDir); err != nil {
+				log.Printf("storage.NewS3Dao: failed to restore the search index, starting from scratch: %v", err)
+			}
+		}
+	}
+	if s3.SearchDB, err = NewSearchDB(searchDir); err != nil {
+		return nil, err
+	}
+	if s3.SearchDB != nil {
+		s3.dirty, s3.closed, s3.done = make(chan struct{}, 1), make(chan struct{}), make(chan struct{})
+		go s3.snapshotLoop()
+	}
+	return s3, nil
+}
+
+func newS3Client(rawurl string) (*s3Client, string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, "", err
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("%q has no bucket", rawurl)
+	}
+
+	query := u.Query()
+	client := &s3Client{
+		bucket:    u.Host,
+		region:    query.Get("region"),
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		http:      newS3HTTPClient(S3_TIMEOUT),
+		timeout:   S3_TIMEOUT,
+		now:       time.Now,
+	}
+	if client.region == "" {
+		client.region = "us-east-1"
+	}
+	if u.User != nil {
+		client.accessKey = u.User.Username()
+		client.secretKey, _ = u.User.Password()
+	}
+
+	endpoint := query.Get("endpoint")
+	if endpoint == "" {
+		endpoint = "https://s3." + client.region + ".amazonaws.com"
+	}
+	if client.endpoint, err = url.Parse(endpoint); err != nil {
+		return nil, "", fmt.Errorf("invalid endpoint %q: %v", endpoint, err)
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return client, prefix, nil
+}
+
+// Close uploads a last snapshot of the search index, if it changed since the previous one.
+func (s3 *S3Dao) Close() error {
+	if s3.closed == nil {
+		return nil
+	}
+	close(s3.closed)
+	<-s3.done
+	return nil
+}
+
+// loadHeads lists the heads of the bucket, and returns the current generation of each staged prefix.
+func (s3 *S3Dao) loadHeads() (map[string]string, error) {
+	keys, err := s3.client.list(s3.prefix + s3HeadsDir)
+	if err != nil {
+		return nil, err
+	}
+	heads := make(map[string]string)
+	for _, key := range keys {
+		if prefix, gen, ok := s3.parseHead(key); ok && gen > heads[prefix] {
+			heads[prefix] = gen
+		}
+	}
+
+	s3.mu.Lock()
+	s3.heads, s3.listed = heads, time.Now()
+	s3.mu.Unlock()
+	return heads, nil
+}
+
+// currentHeads returns the current generation of each staged prefix, listing them again once they are
+// older than S3_HEADS_TTL.
+func (s3 *S3Dao) currentHeads() (map[string]string, error) {
+	s3.mu.Lock()
+	heads, listed := s3.heads, s3.listed
+	s3.mu.Unlock()
+	if time.Since(listed) < S3_HEADS_TTL {
+		return heads, nil
+	}
+	return s3.loadHeads()
+}
+
+// resolve returns the generation an entry belongs to: that of the most recent head covering it, or ""
+// when no head covers it.
+func resolve(heads map[string]string, id string) string {
+	gen := ""
+	for prefix, g := range heads {
+		if (id == prefix || strings.HasPrefix(id, prefix+"/")) && g > gen {
+			gen = g
+		}
+	}
+	return gen
+}
+
+// key returns the key of the object of an entry of the given generation.
+func (s3 *S3Dao) key(gen, id string) string {
+	id = strings.TrimPrefix(path.Join("/", id), "/")
+	if gen == "" {
+		return s3.prefix + s3PagesDir + id
+	}
+	return s3.prefix + s3GenerationsDir + gen + "/" + id
+}
+
+// objectKey returns the key of the object of an entry, as it currently resolves.
+func (s3 *S3Dao) objectKey(id string) (string, error) {
+	heads, err := s3.currentHeads()
+	if err != nil {
+		return "", err
+	}
+	return s3.key(resolve(heads, path.Join("/", id)), id), nil
+}
+
+func (s3 *S3Dao) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	key, err := s3.objectKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %v", err)
+	}
+	data, err := s3.client.get(key)
+	if err == errS3NotFound {
+		return nil, &NotFoundError{Op: "storage.Fetch", ID: id}
+	} else if err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %v", err)
+	}
+	return data, nil
+}
+
+func (s3 *S3Dao) Insert(id string, data []byte) error {
+	key, err := s3.objectKey(id)
+	if err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	if err := s3.client.put(key, data, ContentType(id, data)); err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	return nil
+}
+
+// Get streams the object of an entry. Seeking it drops the response being read, and the next Read
+// requests the rest of the object from the new offset, so that serving a range of a large entry does not
+// download all of it.
+func (s3 *S3Dao) Get(ctx context.Context, id string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	key, err := s3.objectKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	resp, err := s3.client.send(ctx, http.MethodGet, key, nil, nil, nil)
+	if err == errS3NotFound {
+		return nil, &NotFoundError{Op: "storage.Get", ID: id}
+	} else if err != nil {
+		return nil, fmt.Errorf("storage.Get: %v", err)
+	}
+	return &s3Object{ctx: ctx, client: s3.client, key: key, info: s3ObjectInfo(id, resp), body: resp.Body}, nil
+}
+
+func (s3 *S3Dao) Stat(ctx context.Context, id string) (ObjectInfo, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	key, err := s3.objectKey(id)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Stat: %v", err)
+	}
+	resp, err := s3.client.send(ctx, http.MethodHead, key, nil, nil, nil)
+	if err == errS3NotFound {
+		return ObjectInfo{}, &NotFoundError{Op: "storage.Stat", ID: id}
+	} else if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Stat: %v", err)
+	}
+	resp.Body.Close()
+	return s3ObjectInfo(id, resp), nil
+}
+
+// Put reads r into memory, since requests to S3 are signed with the hash of their body, and stores it as
+// an object of the given content type.
+func (s3 *S3Dao) Put(ctx context.Context, id string, r io.Reader, contentType string) (ObjectInfo, error) {
+	data, err := ioutil.ReadAll(contextReader{ctx, r})
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	if contentType == "" {
+		contentType = ContentType(id, data)
+	}
+	key, err := s3.objectKey(id)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	resp, err := s3.client.send(ctx, http.MethodPut, key, nil, http.Header{"Content-Type": {contentType}}, data)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("storage.Put: %v", err)
+	}
+	resp.Body.Close()
+	return s3.Stat(ctx, id)
+}
+
+// s3ObjectInfo returns the metadata of the entry id from the response to a GET or HEAD of its object.
+// Objects uploaded without a content type, which S3 reports as binary/octet-stream, have theirs guessed
+// from the extension of id.
+func s3ObjectInfo(id string, resp *http.Response) ObjectInfo {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" || contentType == "binary/octet-stream" {
+		if guessed := mime.TypeByExtension(path.Ext(id)); guessed != "" {
+			contentType = guessed
+		} else {
+			contentType = "application/octet-stream"
+		}
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	size := resp.ContentLength
+	if size < 0 {
+		size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	}
+	return ObjectInfo{ID: id, Size: size, ContentType: contentType, ModTime: modTime, ETag: resp.Header.Get("ETag")}
+}
+
+// s3Object reads an object of S3 from its offset, through the body of a response to a GET of the object
+// from there.
+type s3Object struct {
+	ctx    context.Context
+	client *s3Client
+	key    string
+	info   ObjectInfo
+	offset int64
+	body   io.ReadCloser // reads the object from offset, nil until the next Read requests it
+}
+
+func (o *s3Object) Info() ObjectInfo { return o.info }
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		// the range must come from the same object the metadata came from
+		header := http.Header{"Range": {fmt.Sprintf("bytes=%d-", o.offset)}}
+		if o.info.ETag != "" {
+			header.Set("If-Match", o.info.ETag)
+		}
+		resp, err := o.client.send(o.ctx, http.MethodGet, o.key, nil, header, nil)
+		if err != nil {
+			return 0, fmt.Errorf("storage.Read: %v", err)
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close()
+			return 0, fmt.Errorf("storage.Read: GET %s from %d: expected a range, got: %s", o.key, o.offset, resp.Status)
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.info.Size
+	default:
+		return o.offset, fmt.Errorf("storage.Seek: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return o.offset, fmt.Errorf("storage.Seek: negative offset %d", offset)
+	}
+	if offset != o.offset {
+		o.Close()
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func (s3 *S3Dao) Delete(id string) error {
+	key, err := s3.objectKey(id)
+	if err != nil {
+		return fmt.Errorf("storage.Delete: %v", err)
+	}
+	if ok, err := s3.client.exists(key); err != nil {
+		return fmt.Errorf("storage.Delete: %v", err)
+	} else if !ok {
+		return fmt.Errorf("storage.Delete: %s not found", id)
+	}
+	if err := s3.client.delete(key); err != nil {
+		return fmt.Errorf("storage.Delete: %v", err)
+	}
+	if err := s3.Unindex(id); err != nil {
+		return fmt.Errorf("storage.Delete: %v", err)
+	}
+	return nil
+}
+
+// entries lists the entries whose ID starts with prefix, mapped to the keys of their objects.
+func (s3 *S3Dao) entries(prefix string) (map[string]string, error) {
+	heads, err := s3.currentHeads()
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	entries := make(map[string]string)
+	add := func(gen, dir string) error {
+		keys, err := s3.client.list(dir + prefix)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			// objects of generations that were replaced, or are still staged, are not entries
+			if id := "/" + strings.TrimPrefix(key, dir); resolve(heads, id) == gen {
+				entries[id] = key
+			}
+		}
+		return nil
+	}
+
+	if err := add("", s3.prefix+s3PagesDir); err != nil {
+		return nil, err
+	}
+	gens := make(map[string]bool)
+	for _, gen := range heads {
+		if !gens[gen] {
+			gens[gen] = true
+			if err := add(gen, s3.prefix+s3GenerationsDir+gen+"/"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entries, nil
+}
+
+// globPrefix returns the part of a glob pattern before its first special character, which every ID it
+// matches starts with.
+func globPrefix(pattern string) string {
+	pattern = path.Join("/", pattern)
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+func (s3 *S3Dao) FetchGlob(pattern string) []string {
+	ids := make([]string, 0)
+	entries, err := s3.entries(globPrefix(pattern))
+	if err != nil {
+		log.Printf("storage.FetchGlob: %v", err)
+		return ids
+	}
+	for id := range entries {
+		if filepath.Ext(id) != "" && globMatch(pattern, id, false) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// DeleteGlob deletes the entries matching pattern, and the entries under the directories matching it, in
+// batches. The heads of the prefixes it deletes are deleted along with their generations.
+func (s3 *S3Dao) DeleteGlob(pattern string) error {
+	entries, err := s3.entries(globPrefix(pattern))
+	if err != nil {
+		return fmt.Errorf("storage.DeleteGlob: %v", err)
+	}
+	var ids, keys []string
+	for id, key := range entries {
+		if globMatch(pattern, id, true) {
+			ids, keys = append(ids, id), append(keys, key)
+		}
+	}
+
+	// replaced heads are deleted too, or they would become current again until swept
+	headKeys, err := s3.client.list(s3.prefix + s3HeadsDir)
+	if err != nil {
+		return fmt.Errorf("storage.DeleteGlob: %v", err)
+	}
+	var dropped []string
+	for _, key := range headKeys {
+		if prefix, gen, ok := s3.parseHead(key); ok && globMatch(pattern, prefix, true) {
+			keys = append(keys, key)
+			dropped = append(dropped, gen)
+		}
+	}
+
+	if err := s3.client.deleteBatch(keys); err != nil {
+		return fmt.Errorf("storage.DeleteGlob: %v", err)
+	}
+	for _, gen := range dropped {
+		if err := s3.deletePrefix(s3.prefix + s3GenerationsDir + gen + "/"); err != nil {
+			return fmt.Errorf("storage.DeleteGlob: %v", err)
+		}
+	}
+	if _, err := s3.loadHeads(); err != nil {
+		return fmt.Errorf("storage.DeleteGlob: %v", err)
+	}
+
+	for _, id := range ids {
+		if err := s3.Unindex(id); err != nil {
+			return fmt.Errorf("storage.DeleteGlob: %v", err)
+		}
+	}
+	return nil
+}
+
+// deletePrefix deletes every object whose key starts with prefix.
+func (s3 *S3Dao) deletePrefix(prefix string) error {
+	keys, err := s3.client.list(prefix)
+	if err != nil {
+		return err
+	}
+	return s3.client.deleteBatch(keys)
+}
+
+func (s3 *S3Dao) headKey(prefix, gen string) string {
+	return s3.prefix + s3HeadsDir + strings.TrimPrefix(prefix, "/") + "@" + gen
+}
+
+// parseHead returns the prefix and the generation of the head key.
+func (s3 *S3Dao) parseHead(key string) (prefix, gen string, ok bool) {
+	head := strings.TrimPrefix(key, s3.prefix+s3HeadsDir)
+	i := strings.LastIndex(head, "@")
+	if i < 0 {
+		return "", "", false
+	}
+	return "/" + head[:i], head[i+1:], true
+}
+
+func (s3 *S3Dao) Drop() error {
+	for _, dir := range []string{s3HeadsDir, s3PagesDir, s3GenerationsDir, s3RetiredDir} {
+		if err := s3.deletePrefix(s3.prefix + dir); err != nil {
+			return fmt.Errorf("storage.Drop: %v", err)
+		}
+	}
+	if _, err := s3.loadHeads(); err != nil {
+		return fmt.Errorf("storage.Drop: %v", err)
+	}
+	return nil
+}
+
+func (s3 *S3Dao) Query(req QueryRequest) (*QueryResult, error) {
+	return s3.SearchDB.Query(req)
+}
+
+func (s3 *S3Dao) Index(id string, doc SearchDocument) error {
+	defer s3.indexChanged()
+	return s3.SearchDB.IndexPage(id, doc)
+}
+
+func (s3 *S3Dao) IndexedHashes() (map[string]string, error) {
+	return s3.SearchDB.IndexedHashes()
+}
+
+func (s3 *S3Dao) Unindex(id string) error {
+	defer s3.indexChanged()
+	return s3.SearchDB.Unindex(id)
+}
+
+func (s3 *S3Dao) ResetIndex() error {
+	defer s3.indexChanged()
+	return s3.SearchDB.Reset()
+}
+
+// indexChanged schedules a snapshot of the search index.
+func (s3 *S3Dao) indexChanged() {
+	if s3.dirty == nil {
+		return
+	}
+	select {
+	case s3.dirty <- struct{}{}:
+	default: // a snapshot is already scheduled
+	}
+}
+
+// snapshotLoop uploads a snapshot of the search index S3_SNAPSHOT_DELAY after it changes, and once more
+// when the Dao is closed.
+func (s3 *S3Dao) snapshotLoop() {
+	defer close(s3.done)
+	snapshot := func() {
+		if err := s3.Snapshot(); err != nil {
+			log.Printf("storage.S3Dao: failed to snapshot the search index: %v", err)
+		}
+	}
+	for {
+		select {
+		case <-s3.dirty:
+			select {
+			case <-time.After(S3_SNAPSHOT_DELAY):
+			case <-s3.closed:
+			}
+			snapshot()
+		case <-s3.closed:
+			select {
+			case <-s3.dirty:
+				snapshot()
+			default:
+			}
+			return
+		}
+	}
+}
+
+// Snapshot uploads a snapshot of the search index to the bucket.
+func (s3 *S3Dao) Snapshot() error {
+	if s3.SearchDB == nil {
+		return nil
+	}
+	tmp, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	tarball := filepath.Join(tmp, s3SearchSnapshot)
+	if err := s3.SearchDB.Archive(tarball); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(tarball)
+	if err != nil {
+		return err
+	}
+	return s3.client.put(s3.prefix+s3SearchSnapshot, data, "application/gzip")
+}
+
+// restoreSnapshot downloads the snapshot of the search index into searchDir.
+func (s3 *S3Dao) restoreSnapshot(searchDir string) error {
+	data, err := s3.client.get(s3.prefix + s3SearchSnapshot)
+	if err == errS3NotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// extracted next to searchDir, so that it is renamed rather than copied into place
+	if err := os.MkdirAll(filepath.Dir(searchDir), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(searchDir), "udocs")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	tarball := filepath.Join(tmp, s3SearchSnapshot)
+	if err := ioutil.WriteFile(tarball, data, 0644); err != nil {
+		return err
+	}
+	extracted := filepath.Join(tmp, "index")
+	if err := archiver.TarGz.Open(tarball, extracted); err != nil {
+		return err
+	}
+
+	// the snapshot holds the index directory of the server that uploaded it, whatever its name
+	dirs, err := ioutil.ReadDir(extracted)
+	if err != nil {
+		return err
+	}
+	if len(dirs) != 1 || !dirs[0].IsDir() {
+		return fmt.Errorf("%s does not hold a search index", s3SearchSnapshot)
+	}
+	return os.Rename(filepath.Join(extracted, dirs[0].Name()), searchDir)
+}
+
+type s3Staging struct {
+	s3     *S3Dao
+	prefix string
+	gen    string
+}
+
+// Stage creates a staging area for the entries under prefix, as a new generation of the prefix.
+func (s3 *S3Dao) Stage(prefix string) (Staging, error) {
+	if strings.Trim(prefix, "/") == "" {
+		return nil, fmt.Errorf("storage.Stage: cannot stage the root of the dao")
+	}
+	gen := fmt.Sprintf("%020d", time.Now().UnixNano())
+	return &s3Staging{s3: s3, prefix: path.Join("/", prefix), gen: gen}, nil
+}
+
+func (st *s3Staging) staged(id string) bool {
+	return id == st.prefix || strings.HasPrefix(id, st.prefix+"/")
+}
+
+func (st *s3Staging) Fetch(id string) ([]byte, error) {
+	if filepath.Ext(id) == "" {
+		id = filepath.Join(id, "index.html")
+	}
+	if !st.staged(path.Join("/", id)) {
+		return nil, fmt.Errorf("storage.Fetch: %s is not staged under %s", id, st.prefix)
+	}
+	data, err := st.s3.client.get(st.s3.key(st.gen, id))
+	if err != nil {
+		return nil, fmt.Errorf("storage.Fetch: %s: %v", id, err)
+	}
+	return data, nil
+}
+
+func (st *s3Staging) Insert(id string, data []byte) error {
+	if !st.staged(path.Join("/", id)) {
+		return fmt.Errorf("storage.Insert: %s is not staged under %s", id, st.prefix)
+	}
+	if err := st.s3.client.put(st.s3.key(st.gen, id), data, ContentType(id, data)); err != nil {
+		return fmt.Errorf("storage.Insert: %v", err)
+	}
+	return nil
+}
+
+// Commit writes the head of the staged generation, which readers switch to in a single step, and then
+// retires what it replaced: the heads and generations of the prefix and the prefixes under it, the
+// objects of the generations of enclosing prefixes under the prefix, and the plain entries under the
+// prefix. Servers whose heads are older than the commit keep reading them until they are swept.
+func (st *s3Staging) Commit() error {
+	s3 := st.s3
+	if err := s3.client.put(s3.headKey(st.prefix, st.gen), nil, ""); err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+	heads, err := s3.loadHeads()
+	if err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+
+	keys, err := s3.client.list(s3.prefix + s3HeadsDir)
+	if err != nil {
+		return fmt.Errorf("storage.Commit: %v", err)
+	}
+	replaced := []string{s3.key("", st.prefix) + "/"}
+	for _, key := range keys {
+		prefix, gen, ok := s3.parseHead(key)
+		if !ok || gen >= st.gen {
+			continue
+		}
+		if st.staged(prefix) {
+			replaced = append(replaced, key, s3.prefix+s3GenerationsDir+gen+"/")
+		} else if strings.HasPrefix(st.prefix, prefix+"/") && heads[prefix] == gen {
+			replaced = append(replaced, s3.key(gen, st.prefix)+"/")
+		}
+	}
+
+	// the commit is visible already, so failing to clean up after it does not fail it
+	if err := s3.retire(st.gen, replaced); err != nil {
+		log.Printf("storage.Commit: failed to retire what %s replaced: %v", st.prefix, err)
+	}
+	if err := s3.sweep(); err != nil {
+		log.Printf("storage.Commit: failed to sweep retired objects: %v", err)
+	}
+	return nil
+}
+
+// retire records the keys, and the prefixes of keys ending in /, replaced by the commit of gen, for a
+// Commit to delete once S3_GC_DELAY has passed.
+func (s3 *S3Dao) retire(gen string, keys []string) error {
+	key := s3.prefix + s3RetiredDir + fmt.Sprintf("%020d", time.Now().UnixNano()) + "@" + gen
+	return s3.client.put(key, []byte(strings.Join(keys, "\n")), "text/plain")
+}
+
+// sweep deletes what was retired more than S3_GC_DELAY ago, along with the records of it.
+func (s3 *S3Dao) sweep() error {
+	records, err := s3.client.list(s3.prefix + s3RetiredDir)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		name := strings.TrimPrefix(record, s3.prefix+s3RetiredDir)
+		retired, err := strconv.ParseInt(strings.SplitN(name, "@", 2)[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if time.Since(time.Unix(0, retired)) < s3.gcDelay {
+			break // records are listed oldest first
+		}
+
+		data, err := s3.client.get(record)
+		if err == errS3NotFound {
+			continue // swept by another server meanwhile
+		} else if err != nil {
+			return err
+		}
+		var keys []string
+		for _, key := range strings.Split(string(data), "\n") {
+			if strings.HasSuffix(key, "/") {
+				if err := s3.deletePrefix(key); err != nil {
+					return err
+				}
+			} else if key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if err := s3.client.deleteBatch(append(keys, record)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (st *s3Staging) Abort() error {
+	if err := st.s3.deletePrefix(st.s3.key(st.gen, st.prefix) + "/"); err != nil {
+		return fmt.Errorf("storage.Abort: %v", err)
+	}
+	return nil
+}
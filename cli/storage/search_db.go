@@ -2,59 +2,282 @@ package storage
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"log"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
 	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+	"github.com/boltdb/bolt"
+	"github.com/mholt/archiver"
 )
 
+// SearchDB is the search index of a Dao. Its methods are safe for concurrent use: bleve serves searches
+// while pages are indexed, so only Reset, which replaces the index, and Archive, which copies it, hold
+// off other callers. The bleve index itself is not exposed, so that every use of it is locked.
 type SearchDB struct {
 	Path string
 	mapping.IndexMapping
-	bleve.Index
+	index bleve.Index
+
+	mu     sync.RWMutex // guards index, which Reset replaces
+	writes sync.RWMutex // held by Archive, so that the index is not written to while it is copied
 }
 
 const (
-	TITLE    = "title"
-	MODIFIED = "modified"
+	TITLE           = "title"
+	BODY            = "body"
+	ROUTE           = "route"
+	VERSION         = "version"
+	HEADER          = "header"
+	DESCRIPTION     = "description"
+	TAGS            = "tags"
+	OWNERS          = "owners"
+	BREADCRUMB      = "breadcrumb"
+	SECTIONS        = "sections"
+	SECTION_ID      = "sections.id"
+	SECTION_HEADING = "sections.heading"
+	SECTION_BODY    = "sections.body"
+	MODIFIED        = "modified"
+	HASH            = "hash"
 )
 
+// matches in page titles and section headings are boosted over matches in body text
+const (
+	TITLE_BOOST   = 3.0
+	HEADING_BOOST = 2.0
+)
+
+const (
+	DEFAULT_QUERY_SIZE = 10
+	MAX_QUERY_SIZE     = 100
+)
+
+// SCHEMA_VERSION must be bumped whenever the content of indexed documents changes in a way that is not
+// reflected in the index mapping. Changes to the mapping itself are detected automatically.
+const SCHEMA_VERSION = "1"
+
+var schemaVersionKey = []byte("udocs_schema_version")
+
+var ErrNoSearchIndex = errors.New("storage: no search index")
+
+// SEARCH_LOCK_TIMEOUT is how long NewSearchDB waits for another process, such as a running server, to
+// release the search index before failing. bleve itself would wait forever.
+const SEARCH_LOCK_TIMEOUT = time.Second
+
+// NewSearchDB opens the search index in dir, creating it if it does not exist. An existing index is kept
+// across restarts, unless it was built with a different schema, in which case it is rebuilt empty.
+//
+// When dir is empty, no index is opened and a nil *SearchDB is returned. Its methods treat every page as
+// unindexed, which lets administrative commands use a Dao without contending with a running server for
+// the lock on the index.
 func NewSearchDB(dir string) (*SearchDB, error) {
-	os.RemoveAll(filepath.Dir(dir))
-	os.MkdirAll(filepath.Dir(dir), 0755)
-	indexMapping := buildIndexMapping()
+	if dir == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, err
+	}
+
+	s := &SearchDB{
+		Path:         dir,
+		IndexMapping: buildIndexMapping(),
+	}
+
+	if err := checkUnlocked(dir); err != nil {
+		return nil, err
+	}
 	index, err := bleve.Open(dir)
 	if err == bleve.ErrorIndexPathDoesNotExist {
-		index, err = bleve.New(dir, indexMapping)
-		if err != nil {
-			return nil, err
-		}
+		return s, s.create()
 	} else if err != nil {
 		return nil, err
 	}
-	return &SearchDB{
-		Path:         dir,
-		IndexMapping: indexMapping,
-		Index:        index,
-	}, nil
+	s.index = index
+
+	version, err := index.GetInternal(schemaVersionKey)
+	if err != nil {
+		return nil, err
+	}
+	if string(version) != s.schemaVersion() {
+		log.Printf("storage.NewSearchDB: search index schema changed, rebuilding %s", dir)
+		return s, s.Reset()
+	}
+
+	return s, nil
+}
+
+// checkUnlocked fails when another process holds the search index in dir, by opening its BoltDB store
+// read-only, with a timeout, before bleve opens it without one.
+func checkUnlocked(dir string) error {
+	store := filepath.Join(dir, "store")
+	if _, err := os.Stat(store); err != nil {
+		return nil // no index yet, or none bleve can open either
+	}
+	db, err := bolt.Open(store, 0600, &bolt.Options{Timeout: SEARCH_LOCK_TIMEOUT, ReadOnly: true})
+	if err == bolt.ErrTimeout {
+		return fmt.Errorf("storage.NewSearchDB: the search index %s is in use by another process, such as a running `udocs serve`, which must be stopped first", dir)
+	} else if err != nil {
+		return nil // left for bleve to report
+	}
+	return db.Close()
+}
+
+// Reset discards every document in the search index, by recreating it from scratch.
+func (s *SearchDB) Reset() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index != nil {
+		if err := s.index.Close(); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(s.Path); err != nil {
+		return err
+	}
+	return s.create()
+}
+
+// Close closes the search index, which is then unlocked for other processes.
+func (s *SearchDB) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index.Close()
+}
+
+func (s *SearchDB) create() error {
+	index, err := bleve.New(s.Path, s.IndexMapping)
+	if err != nil {
+		return err
+	}
+	s.index = index
+	return index.SetInternal(schemaVersionKey, []byte(s.schemaVersion()))
+}
+
+func (s *SearchDB) schemaVersion() string {
+	data, err := json.Marshal(s.IndexMapping)
+	if err != nil {
+		return SCHEMA_VERSION
+	}
+	sum := sha256.Sum256(data)
+	return SCHEMA_VERSION + "-" + hex.EncodeToString(sum[:8])
+}
+
+// IndexedHashes returns the content hash of every page in the search index, keyed by page id.
+func (s *SearchDB) IndexedHashes() (map[string]string, error) {
+	if s == nil {
+		return map[string]string{}, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count, err := s.index.DocCount()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, count)
+	if count == 0 {
+		return hashes, nil
+	}
+
+	sr := bleve.NewSearchRequestOptions(bleve.NewMatchAllQuery(), int(count), 0, false)
+	sr.Fields = []string{HASH}
+	results, err := s.index.Search(sr)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range results.Hits {
+		hashes[hit.ID] = stringField(hit.Fields, HASH)
+	}
+	return hashes, nil
+}
+
+// Unindex removes the page with the given id from the search index.
+func (s *SearchDB) Unindex(id string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.writes.RLock()
+	defer s.writes.RUnlock()
+	return s.index.Delete(id)
+}
+
+// Archive writes a tarball of the index to filename. Pages are not indexed or unindexed meanwhile, but
+// searches go on.
+func (s *SearchDB) Archive(filename string) error {
+	if s == nil {
+		return ErrNoSearchIndex
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.writes.Lock()
+	defer s.writes.Unlock()
+	return archiver.TarGz.Make(filename, []string{s.Path})
 }
 
 func buildIndexMapping() mapping.IndexMapping {
 	textFieldAnalyzer := "en"
 	pageMapping := bleve.NewDocumentMapping()
 
+	// titles, guide headers, breadcrumbs and section headings are mapped into fields of their own,
+	// so that Query can boost matches in them over matches in the body text
 	enTextFieldMapping := bleve.NewTextFieldMapping()
 	enTextFieldMapping.Analyzer = textFieldAnalyzer
 	pageMapping.AddFieldMappingsAt(TITLE, enTextFieldMapping)
+	pageMapping.AddFieldMappingsAt(HEADER, enTextFieldMapping)
+	pageMapping.AddFieldMappingsAt(BREADCRUMB, enTextFieldMapping)
+	pageMapping.AddFieldMappingsAt(BODY, enTextFieldMapping)
+	pageMapping.AddFieldMappingsAt(DESCRIPTION, enTextFieldMapping)
+
+	// routes, versions, tags and owners are matched exactly, so they can be used as filters and facets
+	keywordFieldMapping := bleve.NewTextFieldMapping()
+	keywordFieldMapping.Analyzer = keyword.Name
+	keywordFieldMapping.IncludeInAll = false
+	pageMapping.AddFieldMappingsAt(ROUTE, keywordFieldMapping)
+	pageMapping.AddFieldMappingsAt(VERSION, keywordFieldMapping)
+	pageMapping.AddFieldMappingsAt(TAGS, keywordFieldMapping)
+	pageMapping.AddFieldMappingsAt(OWNERS, keywordFieldMapping)
 
 	dateTimeMapping := bleve.NewDateTimeFieldMapping()
 	pageMapping.AddFieldMappingsAt(MODIFIED, dateTimeMapping)
 
+	// content hashes and section anchors are only stored: the former let the index be reconciled with
+	// the pages of the Dao, and the latter let matches deep-link to the section they were found in
+	storedFieldMapping := bleve.NewTextFieldMapping()
+	storedFieldMapping.Index = false
+	storedFieldMapping.IncludeInAll = false
+	storedFieldMapping.IncludeTermVectors = false
+	pageMapping.AddFieldMappingsAt(HASH, storedFieldMapping)
+
+	sectionMapping := bleve.NewDocumentMapping()
+	sectionMapping.AddFieldMappingsAt("id", storedFieldMapping)
+	sectionMapping.AddFieldMappingsAt("heading", enTextFieldMapping)
+	sectionMapping.AddFieldMappingsAt("body", enTextFieldMapping)
+	pageMapping.AddSubDocumentMapping(SECTIONS, sectionMapping)
+
 	indexMapping := bleve.NewIndexMapping()
 	indexMapping.AddDocumentMapping("page", pageMapping)
 	indexMapping.DefaultAnalyzer = textFieldAnalyzer
@@ -62,11 +285,86 @@ func buildIndexMapping() mapping.IndexMapping {
 	return indexMapping
 }
 
+// SearchDocument is the document stored in the search index for each page. Body and the section
+// bodies are plain text, Route is the route of the guide that owns the page (and Version the version
+// of the guide, if it is versioned), and Hash is a hash of the page content the document was built from.
+// Description, Tags and Owners come from the front matter of the page.
+type SearchDocument struct {
+	Title       string    `json:"title"`
+	Route       string    `json:"route"`
+	Version     string    `json:"version,omitempty"`
+	Header      string    `json:"header"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Owners      []string  `json:"owners,omitempty"`
+	Breadcrumb  []string  `json:"breadcrumb"`
+	Body        string    `json:"body"`
+	Sections    []Section `json:"sections"`
+	Modified    time.Time `json:"modified"`
+	Hash        string    `json:"hash"`
+}
+
+// Section is the content of a page under a single heading. ID is the anchor of the heading.
+type Section struct {
+	ID      string `json:"id"`
+	Heading string `json:"heading"`
+	Body    string `json:"body"`
+}
+
+// Type implements bleve's mapping.Classifier, so that pages are indexed with the "page" document mapping.
+func (doc SearchDocument) Type() string {
+	return "page"
+}
+
+// IndexPage adds (or replaces) the page with the given id in the search index.
+func (s *SearchDB) IndexPage(id string, doc SearchDocument) error {
+	if s == nil {
+		return nil
+	}
+	if doc.Route == "" {
+		doc.Route = parseCollection(id)
+	}
+	if doc.Modified.IsZero() {
+		doc.Modified = time.Now()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.writes.RLock()
+	defer s.writes.RUnlock()
+	return s.index.Index(id, doc)
+}
+
+// QueryRequest describes a search of the index. Size defaults to DEFAULT_QUERY_SIZE. When Routes is
+// not empty, only pages belonging to one of those routes are matched, and likewise for Versions, Tags
+// and Owners.
+type QueryRequest struct {
+	Phrase   string
+	From     int
+	Size     int
+	Routes   []string
+	Versions []string
+	Tags     []string
+	Owners   []string
+}
+
 type QueryResult struct {
-	Phrase       string
-	Total        uint64
-	Took         float64
-	QueryMatches []QueryMatch
+	Phrase       string       `json:"phrase"`
+	Total        uint64       `json:"total"`
+	Took         float64      `json:"took"`
+	From         int          `json:"from"`
+	Size         int          `json:"size"`
+	Routes       []string     `json:"routes,omitempty"`
+	Versions     []string     `json:"versions,omitempty"`
+	Tags         []string     `json:"tags,omitempty"`
+	Owners       []string     `json:"owners,omitempty"`
+	Facets       []RouteFacet `json:"facets"`
+	QueryMatches []QueryMatch `json:"query_matches"`
+}
+
+// RouteFacet is the number of matches for a query within a single route.
+type RouteFacet struct {
+	Route string `json:"route"`
+	Count int    `json:"count"`
 }
 
 func (qr *QueryResult) ToMap() map[string]interface{} {
@@ -78,43 +376,139 @@ func (qr *QueryResult) ToMap() map[string]interface{} {
 		"phrase":        qr.Phrase,
 		"total":         qr.Total,
 		"took":          qr.Took,
+		"from":          qr.From,
+		"size":          qr.Size,
+		"routes":        qr.Routes,
+		"versions":      qr.Versions,
+		"tags":          qr.Tags,
+		"owners":        qr.Owners,
+		"facets":        qr.Facets,
 		"query_matches": qr.QueryMatches,
 	}
 }
 
+// HasPrevious reports whether there are matches before the current page of results.
+func (qr *QueryResult) HasPrevious() bool {
+	return qr != nil && qr.From > 0
+}
+
+// HasNext reports whether there are matches after the current page of results.
+func (qr *QueryResult) HasNext() bool {
+	return qr != nil && uint64(qr.From+len(qr.QueryMatches)) < qr.Total
+}
+
+// Previous returns the offset of the previous page of results.
+func (qr *QueryResult) Previous() int {
+	if from := qr.From - qr.Size; from > 0 {
+		return from
+	}
+	return 0
+}
+
+// Next returns the offset of the next page of results.
+func (qr *QueryResult) Next() int {
+	return qr.From + qr.Size
+}
+
+// QueryMatch is a single page matching a query. When the match was found within a section of the
+// page, Anchor is the ID of that section, and URL deep-links to it.
 type QueryMatch struct {
-	ID       string
-	Rank     int
-	Score    float64
-	Modified string
-	Title    string
-	Body     template.HTML
+	ID          string        `json:"id"`
+	URL         string        `json:"url"`
+	Anchor      string        `json:"anchor,omitempty"`
+	Rank        int           `json:"rank"`
+	Score       float64       `json:"score"`
+	Modified    string        `json:"modified"`
+	Title       string        `json:"title"`
+	Route       string        `json:"route"`
+	Version     string        `json:"version,omitempty"`
+	Header      string        `json:"header"`
+	Description string        `json:"description,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Breadcrumb  []string      `json:"breadcrumb"`
+	Body        template.HTML `json:"body"`
 }
 
-func (s *SearchDB) Query(phrase string) (*QueryResult, error) {
-	sr := bleve.NewSearchRequest(bleve.NewQueryStringQuery(phrase))
+func (s *SearchDB) Query(req QueryRequest) (*QueryResult, error) {
+	if s == nil {
+		return nil, ErrNoSearchIndex
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if req.From < 0 {
+		req.From = 0
+	}
+	if req.Size <= 0 {
+		req.Size = DEFAULT_QUERY_SIZE
+	} else if req.Size > MAX_QUERY_SIZE {
+		req.Size = MAX_QUERY_SIZE
+	}
+
+	q := bleve.NewBooleanQuery()
+	q.AddMust(bleve.NewQueryStringQuery(req.Phrase))
+	q.AddShould(boostedMatchQuery(req.Phrase, TITLE, TITLE_BOOST), boostedMatchQuery(req.Phrase, SECTION_HEADING, HEADING_BOOST))
+	if len(req.Routes) > 0 {
+		q.AddMust(termsQuery(ROUTE, req.Routes))
+	}
+	if len(req.Versions) > 0 {
+		q.AddMust(termsQuery(VERSION, req.Versions))
+	}
+	if len(req.Tags) > 0 {
+		q.AddMust(termsQuery(TAGS, req.Tags))
+	}
+	if len(req.Owners) > 0 {
+		q.AddMust(termsQuery(OWNERS, req.Owners))
+	}
+
+	sr := bleve.NewSearchRequestOptions(q, req.Size, req.From, false)
 	sr.Highlight = bleve.NewHighlightWithStyle("html")
-	sr.Fields = []string{TITLE, MODIFIED}
+	sr.Highlight.AddField(BODY)
+	sr.Fields = []string{TITLE, ROUTE, VERSION, HEADER, DESCRIPTION, TAGS, BREADCRUMB, SECTION_ID, MODIFIED}
+	sr.AddFacet(ROUTE, bleve.NewFacetRequest(ROUTE, MAX_QUERY_SIZE))
 
-	searchResults, err := s.Search(sr)
+	searchResults, err := s.index.Search(sr)
 	if err != nil {
 		return nil, err
 	}
 
 	qr := QueryResult{
-		Phrase:       phrase,
+		Phrase:       req.Phrase,
 		Took:         formatFloat(searchResults.Took.Seconds()),
 		Total:        searchResults.Total,
+		From:         req.From,
+		Size:         req.Size,
+		Routes:       req.Routes,
+		Versions:     req.Versions,
+		Tags:         req.Tags,
+		Owners:       req.Owners,
+		Facets:       make([]RouteFacet, 0),
 		QueryMatches: make([]QueryMatch, 0),
 	}
 
+	if facet, ok := searchResults.Facets[ROUTE]; ok {
+		for _, term := range facet.Terms {
+			qr.Facets = append(qr.Facets, RouteFacet{Route: term.Term, Count: term.Count})
+		}
+	}
+
 	for i, hit := range searchResults.Hits {
 		qm := QueryMatch{
-			Rank:     i + searchResults.Request.From + 1,
-			ID:       hit.ID,
-			Score:    hit.Score,
-			Title:    hit.Fields[TITLE].(string),
-			Modified: hit.Fields[MODIFIED].(string),
+			Rank:        i + searchResults.Request.From + 1,
+			ID:          hit.ID,
+			URL:         hit.ID,
+			Score:       hit.Score,
+			Title:       stringField(hit.Fields, TITLE),
+			Route:       stringField(hit.Fields, ROUTE),
+			Version:     stringField(hit.Fields, VERSION),
+			Header:      stringField(hit.Fields, HEADER),
+			Description: stringField(hit.Fields, DESCRIPTION),
+			Tags:        stringsField(hit.Fields, TAGS),
+			Breadcrumb:  stringsField(hit.Fields, BREADCRUMB),
+			Modified:    stringField(hit.Fields, MODIFIED),
+		}
+		if anchor := matchedSection(hit, stringsField(hit.Fields, SECTION_ID)); anchor != "" {
+			qm.Anchor = anchor
+			qm.URL = hit.ID + "#" + anchor
 		}
 		var buf bytes.Buffer
 		for _, fragments := range hit.Fragments {
@@ -129,12 +523,67 @@ func (s *SearchDB) Query(phrase string) (*QueryResult, error) {
 	return &qr, nil
 }
 
-var htmlCharFilterRegexp = regexp.MustCompile(`</?[!\w]+((\s+\w+(\s*=\s*(?:".*?"|'.*?'|[^'">\s]+))?)+\s*|\s*)/?>`)
+// termsQuery matches documents whose keyword field holds any one of terms.
+func termsQuery(field string, terms []string) query.Query {
+	queries := make([]query.Query, len(terms))
+	for i, term := range terms {
+		tq := bleve.NewTermQuery(term)
+		tq.SetField(field)
+		queries[i] = tq
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+func boostedMatchQuery(phrase, field string, boost float64) query.Query {
+	mq := bleve.NewMatchQuery(phrase)
+	mq.SetField(field)
+	mq.SetBoost(boost)
+	return mq
+}
+
+// matchedSection returns the anchor of the section of a hit with the most matching terms, where matches
+// in a section heading count double. Sections without an anchor are ignored.
+func matchedSection(hit *search.DocumentMatch, anchors []string) string {
+	scores := make(map[int]int)
+	for field, weight := range map[string]int{SECTION_HEADING: 2, SECTION_BODY: 1} {
+		for _, locations := range hit.Locations[field] {
+			for _, location := range locations {
+				if len(location.ArrayPositions) > 0 {
+					scores[int(location.ArrayPositions[0])] += weight
+				}
+			}
+		}
+	}
+
+	best, anchor := 0, ""
+	for i, score := range scores {
+		if i < len(anchors) && anchors[i] != "" && (score > best || (score == best && anchors[i] < anchor)) {
+			best, anchor = score, anchors[i]
+		}
+	}
+	return anchor
+}
+
+func stringField(fields map[string]interface{}, name string) string {
+	if s, ok := fields[name].(string); ok {
+		return s
+	}
+	return ""
+}
 
-func filterHTMLTags(input []byte) []byte {
-	return htmlCharFilterRegexp.ReplaceAllFunc(input, func(in []byte) []byte {
-		return bytes.Repeat([]byte(``), len(in))
-	})
+// stringsField returns a stored array field, which bleve returns as a plain string when it holds a single value.
+func stringsField(fields map[string]interface{}, name string) []string {
+	switch v := fields[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		strs := make([]string, len(v))
+		for i := range v {
+			strs[i], _ = v[i].(string)
+		}
+		return strs
+	}
+	return nil
 }
 
 func formatFloat(val float64) float64 {
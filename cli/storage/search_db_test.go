@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchDBPersists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "search")
+	searchDB, err := NewSearchDB(path)
+	if err != nil {
+		t.Fatalf("NewSearchDB(%s) => %v", path, err)
+	}
+	if err := searchDB.IndexPage("/test/index.html", SearchDocument{Title: "Test", Body: "persistent", Hash: "abc"}); err != nil {
+		t.Fatalf("IndexPage => %v", err)
+	}
+	searchDB.Close()
+
+	searchDB, err = NewSearchDB(path)
+	if err != nil {
+		t.Fatalf("NewSearchDB(%s) => %v", path, err)
+	}
+	hashes, err := searchDB.IndexedHashes()
+	if err != nil {
+		t.Fatalf("IndexedHashes => %v", err)
+	}
+	if hashes["/test/index.html"] != "abc" {
+		t.Errorf("IndexedHashes -> expected: map[/test/index.html:abc] got: %v", hashes)
+	}
+
+	// an index built with a different schema is rebuilt from scratch
+	if err := searchDB.index.SetInternal(schemaVersionKey, []byte("0")); err != nil {
+		t.Fatalf("SetInternal => %v", err)
+	}
+	searchDB.Close()
+
+	searchDB, err = NewSearchDB(path)
+	if err != nil {
+		t.Fatalf("NewSearchDB(%s) => %v", path, err)
+	}
+	defer searchDB.Close()
+	if count, _ := searchDB.index.DocCount(); count != 0 {
+		t.Errorf("DocCount -> expected: 0 after schema change, got: %d", count)
+	}
+}
+
+func TestSearchDBInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "search")
+	searchDB, err := NewSearchDB(path)
+	if err != nil {
+		t.Fatalf("NewSearchDB(%s) => %v", path, err)
+	}
+	defer searchDB.Close()
+
+	// a second open, as by an admin command while the server is up, fails rather than waiting forever
+	opened := make(chan error, 1)
+	go func() {
+		second, err := NewSearchDB(path)
+		if err == nil {
+			second.Close()
+		}
+		opened <- err
+	}()
+	select {
+	case err := <-opened:
+		if err == nil || !strings.Contains(err.Error(), "in use by another process") {
+			t.Errorf("NewSearchDB(%s) of an index in use -> expected an error telling it is in use, got: %v", path, err)
+		}
+	case <-time.After(5 * SEARCH_LOCK_TIMEOUT):
+		t.Fatalf("NewSearchDB(%s) of an index in use -> expected to fail within %v", path, SEARCH_LOCK_TIMEOUT)
+	}
+}
@@ -0,0 +1,159 @@
+package udocs
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// admonitionRegex matches the first line of an admonition block, as in !!! warning "Title", or ??? tip for
+// a collapsed one, or ???+ tip for a collapsible one that starts out open.
+var admonitionRegex = regexp.MustCompile(`^(!!!|\?\?\?\+?)[ \t]+([A-Za-z]+)(?:[ \t]+"([^"]*)")?[ \t]*$`)
+
+// alertRegex matches the marker that starts a GitHub alert, as in > [!NOTE], at the top of a blockquote.
+// A trailing - makes the alert collapsed, and a trailing + collapsible, and the rest of the line is a title.
+var alertRegex = regexp.MustCompile(`^\s*\[!([A-Za-z]+)\]([+-]?)[ \t]*([^\n]*)\n?`)
+
+// admonitionKinds maps the types of admonitions to the kinds that they are styled as, and the icons
+// shown in their titles.
+var admonitionKinds = map[string]struct{ kind, icon string }{
+	"note":      {"note", "fa-info-circle"},
+	"info":      {"note", "fa-info-circle"},
+	"abstract":  {"note", "fa-info-circle"},
+	"tip":       {"tip", "fa-lightbulb-o"},
+	"hint":      {"tip", "fa-lightbulb-o"},
+	"success":   {"tip", "fa-check-circle"},
+	"important": {"important", "fa-exclamation-circle"},
+	"question":  {"important", "fa-question-circle"},
+	"warning":   {"warning", "fa-warning"},
+	"caution":   {"warning", "fa-warning"},
+	"attention": {"warning", "fa-warning"},
+	"danger":    {"danger", "fa-bolt"},
+	"error":     {"danger", "fa-times-circle"},
+	"bug":       {"danger", "fa-bug"},
+}
+
+// preprocessAdmonitions rewrites the admonition blocks of a markdown page, whose content is indented by
+// four spaces under a line such as !!! warning "Title", into GitHub alerts, as in
+//
+//	> [!WARNING] Title
+//	> content
+//
+// since the markdown renderer takes their indented content for a code block otherwise.
+func preprocessAdmonitions(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var buf bytes.Buffer
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimRight(string(lines[i]), "\r\n")
+		if fence != "" {
+			if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			buf.Write(lines[i])
+			continue
+		} else if matches := fenceRegex.FindStringSubmatch(text); matches != nil {
+			fence = matches[1]
+			buf.Write(lines[i])
+			continue
+		}
+
+		matches := admonitionRegex.FindStringSubmatch(text)
+		if matches == nil {
+			buf.Write(lines[i])
+			continue
+		}
+
+		// the content of the block is its following lines that are blank or indented
+		var content bytes.Buffer
+		end := i + 1
+		for j := i + 1; j < len(lines); j++ {
+			line := strings.TrimRight(string(lines[j]), "\r\n")
+			if strings.TrimSpace(line) == "" {
+				content.WriteString("\n")
+				continue
+			}
+			dedented := strings.TrimPrefix(line, "\t")
+			if dedented == line {
+				if !strings.HasPrefix(line, "    ") {
+					break
+				}
+				dedented = line[4:]
+			}
+			content.WriteString(dedented + "\n")
+			end = j + 1
+		}
+
+		collapse := ""
+		switch matches[1] {
+		case "???":
+			collapse = "-"
+		case "???+":
+			collapse = "+"
+		}
+		buf.WriteString(fmt.Sprintf("> [!%s]%s %s\n", strings.ToUpper(matches[2]), collapse, matches[3]))
+
+		body := preprocessFences(preprocessAdmonitions(bytes.TrimRight(content.Bytes(), "\n")))
+		for _, line := range strings.Split(string(body), "\n") {
+			buf.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		buf.WriteString("\n")
+		i = end - 1
+	}
+	return buf.Bytes()
+}
+
+// processBlockquoteElement turns a blockquote that starts with a GitHub alert marker, as in > [!NOTE], into
+// an admonition: an aside, or a details element when it is collapsible, with a title and the type of the
+// admonition as its class.
+func processBlockquoteElement(node *html.Node) {
+	para := node.FirstChild
+	for para != nil && para.Type == html.TextNode && strings.TrimSpace(para.Data) == "" {
+		para = para.NextSibling
+	}
+	if para == nil || para.Type != html.ElementNode || para.Data != "p" || para.FirstChild == nil || para.FirstChild.Type != html.TextNode {
+		return
+	}
+	marker := para.FirstChild
+	matches := alertRegex.FindStringSubmatch(marker.Data)
+	if matches == nil {
+		return
+	}
+
+	typ := strings.ToLower(matches[1])
+	kind, ok := admonitionKinds[typ]
+	if !ok {
+		kind = admonitionKinds["note"]
+	}
+	title := strings.TrimSpace(matches[3])
+	if title == "" {
+		title = strings.ToUpper(typ[:1]) + typ[1:]
+	}
+
+	// the marker is removed, along with the paragraph it leaves empty
+	marker.Data = marker.Data[len(matches[0]):]
+	if marker.Data == "" {
+		para.RemoveChild(marker)
+	}
+	if para.FirstChild == nil || (para.FirstChild == para.LastChild && para.FirstChild.Type == html.TextNode && strings.TrimSpace(para.FirstChild.Data) == "") {
+		node.RemoveChild(para)
+	}
+
+	titleElem := element("p", "admonition-title")
+	node.Data, node.DataAtom = "aside", atom.Aside
+	node.Attr = []html.Attribute{html.Attribute{Key: "class", Val: "admonition admonition-" + kind.kind}}
+	switch matches[2] {
+	case "-":
+		node.Data, node.DataAtom, titleElem.Data = "details", atom.Details, "summary"
+	case "+":
+		node.Data, node.DataAtom, titleElem.Data = "details", atom.Details, "summary"
+		node.Attr = append(node.Attr, html.Attribute{Key: "open"})
+	}
+	titleElem.AppendChild(element("i", "fa "+kind.icon))
+	titleElem.AppendChild(&html.Node{Type: html.TextNode, Data: " " + title})
+	node.InsertBefore(titleElem, node.FirstChild)
+}
@@ -0,0 +1,69 @@
+package udocs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdmonitions(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected string
+	}{
+		{
+			given:    "> [!NOTE]\n> Read **this**.\n",
+			expected: `<aside class="admonition admonition-note"><p class="admonition-title"><i class="fa fa-info-circle"></i> Note</p>` + "\n" + `<p>Read <strong>this</strong>.</p>` + "\n" + `</aside>`,
+		},
+		{
+			given:    "> [!WARNING] Careful now\n> Text.\n",
+			expected: `<aside class="admonition admonition-warning"><p class="admonition-title"><i class="fa fa-warning"></i> Careful now</p>` + "\n" + `<p>Text.</p>` + "\n" + `</aside>`,
+		},
+		{
+			given:    "!!! danger \"Do not\"\n    Never do it.\n\n    ```go {1}\n    x := 1\n    ```\n\nAfter.\n",
+			expected: `<aside class="admonition admonition-danger"><p class="admonition-title"><i class="fa fa-bolt"></i> Do not</p>` + "\n" + `<p>Never do it.</p>` + "\n" + `<div class="code-block">`,
+		},
+		{
+			given:    "??? tip\n    Hidden.\n",
+			expected: `<details class="admonition admonition-tip"><summary class="admonition-title"><i class="fa fa-lightbulb-o"></i> Tip</summary>` + "\n" + `<p>Hidden.</p>` + "\n" + `</details>`,
+		},
+		{
+			given:    "???+ bug\n    Shown.\n",
+			expected: `<details class="admonition admonition-danger" open=""><summary class="admonition-title"><i class="fa fa-bug"></i> Bug</summary>`,
+		},
+		{
+			given:    "> [!TIP]-\n> Folded.\n",
+			expected: `<details class="admonition admonition-tip"><summary class="admonition-title"><i class="fa fa-lightbulb-o"></i> Tip</summary>`,
+		},
+		{
+			given:    "> plain quote\n",
+			expected: "<blockquote>\n<p>plain quote</p>\n</blockquote>",
+		},
+		{
+			given:    "```\n!!! note\n    Example.\n```\n",
+			expected: "!!! note\n    Example.",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := processMarkdown("test", []byte(tc.given), testRenderer, nil)
+		if err != nil {
+			t.Fatalf("Terminating test due to failed markdown processing: %v", err)
+		}
+		if !strings.Contains(string(got), tc.expected) {
+			t.Errorf(errFmt, tc.given, tc.expected, string(got))
+		}
+	}
+}
+
+func TestAdmonitionsSearchable(t *testing.T) {
+	given := "# Page\n\n??? warning \"Rate limits\"\n    Requests are throttled.\n"
+	got, err := processMarkdown("test", []byte(given), testRenderer, nil)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed markdown processing: %v", err)
+	}
+
+	expected := "Rate limits Requests are throttled."
+	if text := extractText(got); !strings.Contains(text, expected) {
+		t.Errorf(errFmt, given, expected, text)
+	}
+}
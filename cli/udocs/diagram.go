@@ -0,0 +1,192 @@
+package udocs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// DIAGRAM_CACHE is the directory of the dao that rendered diagrams are cached in, by the hash of their source.
+// It is shared by every route, so a diagram is only rendered once however many guides and versions use it.
+const DIAGRAM_CACHE = "/_diagrams"
+
+// diagramTimeout bounds the time a local renderer may take to render a single diagram.
+const diagramTimeout = 30 * time.Second
+
+// errNoRenderer is returned when no local renderer of a diagram language is installed.
+var errNoRenderer = errors.New("no local renderer is installed")
+
+// diagramRenderers render the source of a diagram, by language, into an SVG image. They are variables so
+// that tests can replace them.
+var diagramRenderers = map[string]func(src []byte) ([]byte, error){
+	"mermaid":  renderMermaid,
+	"dot":      renderGraphviz,
+	"graphviz": renderGraphviz,
+	"plantuml": renderPlantUML,
+}
+
+// DiagramFailure is a diagram of a page that a local renderer failed to render.
+type DiagramFailure struct {
+	Lang   string
+	Source string
+	Err    error
+}
+
+// Diagrams renders the mermaid, dot and plantuml fenced code blocks of pages into inline SVG images with
+// the local renderers that are installed (mmdc, dot and plantuml). Diagrams are cached in the dao by the
+// hash of their source. A diagram whose renderer is not installed, or fails, is left as a code block, and
+// the failure is recorded.
+type Diagrams struct {
+	cache    storage.Store
+	Failures []DiagramFailure
+}
+
+// NewDiagrams returns a Diagrams that caches rendered diagrams in cache, which may be nil to not cache them.
+func NewDiagrams(cache storage.Store) *Diagrams {
+	return &Diagrams{cache: cache}
+}
+
+// render renders the diagram node, the div the markdown renderer wraps a fenced code block in, into an SVG
+// image followed by its source. It reports false when node is not a diagram, or could not be rendered.
+func (d *Diagrams) render(node *html.Node) bool {
+	var lang string
+	for _, a := range node.Attr {
+		if a.Key == "class" && strings.HasPrefix(a.Val, "highlight highlight-") {
+			lang = strings.ToLower(parseCodeInfo(strings.TrimPrefix(a.Val, "highlight highlight-")).Lang)
+		}
+	}
+	renderer, ok := diagramRenderers[lang]
+	if !ok || node.FirstChild == nil {
+		return false
+	}
+
+	src := rawText(node.FirstChild)
+	svg, err := d.cached(lang, src, renderer)
+	if err == errNoRenderer {
+		return false
+	} else if err != nil {
+		d.Failures = append(d.Failures, DiagramFailure{Lang: lang, Source: src, Err: err})
+		return false
+	}
+
+	image, err := parseSVG(svg)
+	if err != nil {
+		d.Failures = append(d.Failures, DiagramFailure{Lang: lang, Source: src, Err: err})
+		return false
+	}
+
+	for c := node.FirstChild; c != nil; c = node.FirstChild {
+		node.RemoveChild(c)
+	}
+	node.Attr = []html.Attribute{html.Attribute{Key: "class", Val: "diagram diagram-" + lang}}
+	node.AppendChild(image)
+
+	// the source is kept, for readers who want to copy or edit the diagram
+	details, summary := element("details", "diagram-source"), &html.Node{Type: html.ElementNode, Data: "summary"}
+	summary.AppendChild(&html.Node{Type: html.TextNode, Data: "Source"})
+	pre, code := &html.Node{Type: html.ElementNode, Data: "pre"}, element("code", "language-"+lang)
+	code.AppendChild(&html.Node{Type: html.TextNode, Data: src})
+	pre.AppendChild(code)
+	details.AppendChild(summary)
+	details.AppendChild(pre)
+	node.AppendChild(details)
+	return true
+}
+
+func (d *Diagrams) cached(lang, src string, renderer func([]byte) ([]byte, error)) ([]byte, error) {
+	id := filepath.Join(DIAGRAM_CACHE, lang+"-"+hashContent([]byte(src))+".svg")
+	if d.cache != nil {
+		if svg, err := d.cache.Fetch(id); err == nil {
+			return svg, nil
+		}
+	}
+
+	svg, err := renderer([]byte(src))
+	if err != nil {
+		return nil, err
+	}
+	if d.cache != nil {
+		if err := d.cache.Insert(id, svg); err != nil {
+			return nil, err
+		}
+	}
+	return svg, nil
+}
+
+// parseSVG parses an SVG image, skipping the XML declaration and doctype renderers put before it, into a
+// div that holds it.
+func parseSVG(svg []byte) (*html.Node, error) {
+	i := bytes.Index(svg, []byte("<svg"))
+	if i < 0 {
+		return nil, errors.New("renderer did not produce an SVG image")
+	}
+
+	div := element("div", "diagram-image")
+	nodes, err := html.ParseFragment(bytes.NewReader(svg[i:]), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nodes {
+		div.AppendChild(n)
+	}
+	return div, nil
+}
+
+func renderGraphviz(src []byte) ([]byte, error) {
+	return runRenderer(src, "dot", "-Tsvg")
+}
+
+func renderPlantUML(src []byte) ([]byte, error) {
+	return runRenderer(src, "plantuml", "-tsvg", "-pipe")
+}
+
+// renderMermaid renders a mermaid diagram with mermaid-cli, which reads and writes files rather than pipes.
+func renderMermaid(src []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "udocs-mermaid")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in, out := filepath.Join(dir, "diagram.mmd"), filepath.Join(dir, "diagram.svg")
+	if err := ioutil.WriteFile(in, src, 0644); err != nil {
+		return nil, err
+	}
+	if _, err := runRenderer(nil, "mmdc", "--quiet", "-i", in, "-o", out); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(out)
+}
+
+// runRenderer runs a local renderer with src as its input, and returns its output.
+func runRenderer(src []byte, name string, args ...string) ([]byte, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, errNoRenderer
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagramTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(src), &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %v", name, err)
+	}
+	return stdout.Bytes(), nil
+}
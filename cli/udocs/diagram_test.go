@@ -0,0 +1,106 @@
+package udocs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+func TestDiagrams(t *testing.T) {
+	renders := 0
+	defer func(renderers map[string]func([]byte) ([]byte, error)) { diagramRenderers = renderers }(diagramRenderers)
+	diagramRenderers = map[string]func([]byte) ([]byte, error){
+		"dot": func(src []byte) ([]byte, error) {
+			renders++
+			return []byte(`<?xml version="1.0"?><!DOCTYPE svg><svg width="10"><text>` + strings.TrimSpace(string(src)) + `</text></svg>`), nil
+		},
+		"mermaid": func(src []byte) ([]byte, error) {
+			return nil, errors.New("Parse error on line 1")
+		},
+		"plantuml": func(src []byte) ([]byte, error) {
+			return nil, errNoRenderer
+		},
+	}
+
+	dao := storage.NewMockDao("/")
+	testCases := []struct {
+		given    string
+		expected []string
+		failures int
+	}{
+		{
+			given: "```dot\ndigraph { a -> b }\n```\n",
+			expected: []string{
+				`<div class="diagram diagram-dot"><div class="diagram-image"><svg width="10"><text>digraph { a -&gt; b }</text></svg></div>`,
+				`<details class="diagram-source"><summary>Source</summary><pre><code class="language-dot">digraph { a -&gt; b }`,
+			},
+		},
+		{
+			given:    "```mermaid\ngraph TD;\n```\n",
+			expected: []string{`<div class="code-block">`, `<code class="language-mermaid">`},
+			failures: 1,
+		},
+		{
+			given:    "```plantuml\n@startuml\n```\n",
+			expected: []string{`<div class="code-block">`, `<code class="language-plantuml">`},
+		},
+	}
+
+	for _, tc := range testCases {
+		diagrams := NewDiagrams(dao)
+		got, err := processMarkdown("test", []byte(tc.given), testRenderer, diagrams)
+		if err != nil {
+			t.Fatalf("Terminating test due to failed markdown processing: %v", err)
+		}
+		for _, expected := range tc.expected {
+			if !strings.Contains(string(got), expected) {
+				t.Errorf(errFmt, tc.given, expected, string(got))
+			}
+		}
+		if len(diagrams.Failures) != tc.failures {
+			t.Errorf(errFmt, tc.given, tc.failures, diagrams.Failures)
+		}
+	}
+
+	// the dot diagram is rendered again from the cache
+	if _, err := processMarkdown("test", []byte(testCases[0].given), testRenderer, NewDiagrams(dao)); err != nil {
+		t.Fatalf("Terminating test due to failed markdown processing: %v", err)
+	}
+	if renders != 1 {
+		t.Errorf(errFmt, "rendering a cached diagram", 1, renders)
+	}
+}
+
+func TestCheckLinksDiagrams(t *testing.T) {
+	defer func(renderers map[string]func([]byte) ([]byte, error)) { diagramRenderers = renderers }(diagramRenderers)
+	diagramRenderers = map[string]func([]byte) ([]byte, error){
+		"mermaid": func(src []byte) ([]byte, error) {
+			return nil, errors.New("Parse error on line 1")
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	given := "# Architecture\n\nThe flow:\n\n```mermaid\ngraph TD;\n  A-->B;\n```\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, README_MD), []byte(given), 0644); err != nil {
+		t.Fatalf("Terminating test due to failed file write: %v", err)
+	}
+
+	problems, err := CheckLinks(dir, LinkCheckOptions{})
+	if err != nil {
+		t.Fatalf("CheckLinks(%s) => %v", dir, err)
+	}
+	expected := LinkProblem{File: README_MD, Line: 5, Link: "mermaid diagram", Message: "failed to render: Parse error on line 1"}
+	if len(problems) != 1 || problems[0] != expected {
+		t.Errorf(errFmt, given, expected, problems)
+	}
+}
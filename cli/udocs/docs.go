@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/seanawilliams/udocs/cli/storage"
@@ -20,10 +21,12 @@ import (
 var innerHTMLTemplate = MustParseTemplate(nil, "inner.html")
 
 const (
-	README_MD    = "README.md"
-	SUMMARY_MD   = "SUMMARY.md"
-	SIDEBAR_JSON = "sidebar.json"
-	INDEX_HTML   = "index.html"
+	README_MD     = "README.md"
+	SUMMARY_MD    = "SUMMARY.md"
+	SIDEBAR_JSON  = "sidebar.json"
+	INDEX_HTML    = "index.html"
+	MANIFEST_JSON = ".manifest.json"
+	TOKENS_JSON   = "tokens.json"
 )
 
 // Validate validates the the given docs directory meets the format required by UDocs.
@@ -54,92 +57,360 @@ func Validate(dir string) error {
 	return nil
 }
 
-func Build(route, dir string, dao storage.Dao) error {
+// Build renders the docs directory dir into dao under the given route. Files whose content has not
+// changed since the previous build of the route are not rendered again, and entries whose source file no
+// longer exists are purged from the dao (along with their search index entries).
+//
+// The route is built into a staging area of the dao, which is swapped into place once the build is
+// complete, so readers never see a partially built route, and a failed build leaves the route intact.
+func Build(route, dir string, dao storage.Dao) (*BuildReport, error) {
+	return BuildVersion(route, "", dir, dao)
+}
+
+// BuildVersion is like Build, but renders dir as the given version of a versioned guide, under the
+// route /route/version. Each version of a guide is built, and rebuilt, independently of the others.
+func BuildVersion(route, version, dir string, dao storage.Dao) (*BuildReport, error) {
+	return BuildWithOptions(route, dir, dao, BuildOptions{Version: version})
+}
+
+// BuildOptions configures BuildWithOptions.
+type BuildOptions struct {
+	// Version is the version of a versioned guide that dir is built as, if any.
+	Version string
+	// Drafts includes the pages marked as drafts by their front matter, which are left out otherwise.
+	Drafts bool
+	// History is the git history of the files of dir, as published along with them. It is read from the
+	// git repository dir is in, if any, when it is nil.
+	History GitHistory
+}
+
+// BuildWithOptions is like Build, with the version of the guide and the handling of drafts given by opts.
+func BuildWithOptions(route, dir string, dao storage.Dao, opts BuildOptions) (*BuildReport, error) {
+	// builds of the route, and of its versions, stage from what the previous build committed
+	defer LockRoute(route)()
+
+	version := opts.Version
 	abs, err := filepath.Abs(dir)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	prefix := filepath.Join(route, version)
+
+	// the manifest of the previous build tells us which pages are unchanged, and which are left hanging
+	oldManifest, _ := LoadManifest(prefix, dao) // load manifest, if it exists
+	manifest := make(Manifest)
+	report := new(BuildReport)
+	sidebar, _ := LoadSidebar(dao) // load sidebar, if it exists
+
+	stage, err := dao.Stage(prefix)
+	if err != nil {
+		return nil, err
 	}
+	committed := false
+	defer func() {
+		if !committed {
+			stage.Abort()
+		}
+	}()
 
-	// cache all original pages, so we can mark any hanging pages for later deletion
-	// purgeList := make(map[string]struct{}, 0)
-	// for _, filename := range dao.FetchGlob(route + "/**/*") {
-	// 	purgeList[filename] = struct{}{}
-	// }
+	// the versions of a guide are stored under its route, so they are carried over when it is rebuilt
+	if guide := sidebar.Guide(route); guide != nil && version == "" {
+		for _, v := range guide.Versions {
+			if err := copyVersion(route, v, dao, stage); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	var summary Summary
 	foundSummary := false
+	drafts := make(map[string]bool)
+	config, err := LoadGuideConfig(abs)
+	if err != nil {
+		return nil, err
+	}
+	markdownOpts := config.Markdown
+	renderer, err := NewRenderer(markdownOpts)
+	if err != nil {
+		return nil, err
+	}
+	// the diagram cache is content-addressed and shared by every route, so it is written outside the stage
+	diagrams := NewDiagrams(dao)
+	apiPages := make(map[string][]Page) // the pages built from the OpenAPI specs, by the ID of their spec
+	history := opts.History
+	if history == nil {
+		history, _ = ReadGitHistory(abs) // pages outside of git repositories simply have no history
+	}
 	if err := filepath.Walk(abs, func(path string, fi os.FileInfo, err error) error {
 		if fi == nil || !fi.Mode().IsRegular() {
 			return nil
 		}
 
 		var id string
+		var meta *PageMeta
+		rel := path[len(abs):]
 
 		data, err := ioutil.ReadFile(path)
 		if err != nil {
 			return err
 		}
+		if rel == "/"+GUIDE_CONFIG {
+			return nil
+		}
+		hash := hashContent(data)
+
+		// includes are expanded first, so that pages are rebuilt when the files they include change, or
+		// the markdown options of the guide, or the way udocs renders them, do
+		if isMarkdownPage(path) && !IsSummaryFile(path) {
+			if data, err = ExpandIncludes(abs, path, data); err != nil {
+				return fmt.Errorf("udocs.Build failed to expand the includes of %s: %v", rel, err)
+			}
+			hash = renderHash(data, markdownOpts)
+		}
 
 		if IsSummaryFile(path) && !foundSummary {
 			foundSummary = true
-			summary, err = ParseSummary(route, data)
+			summary, err = ParseSummary(prefix, data)
 			if err != nil {
 				return err
 			}
+			summary.Route, summary.Version = route, version
 
-			sidebar, _ := LoadSidebar(dao) // load sidebar, if it exists
-
-			data, err = json.Marshal(sidebar.Merge(summary))
-			if err != nil {
-				return err
+			// the sidebar is shared by every route, so it is only updated once the route is committed
+			if !oldManifest.Unchanged(rel, SIDEBAR_JSON, hash) {
+				report.record(oldManifest, rel, SIDEBAR_JSON)
 			}
-			id = SIDEBAR_JSON
+			manifest[rel] = ManifestEntry{ID: SIDEBAR_JSON, Hash: hash}
+			return nil
 		} else if isMarkdownPage(path) {
-			data, err = processMarkdown(route, data)
-			if err != nil {
-				return err
+			id = getHTMLPath("/", prefix, rel)
+			if meta, data, err = ParseFrontMatter(data); err != nil {
+				return fmt.Errorf("udocs.Build failed to parse the front matter of %s: %v", rel, err)
+			}
+			if meta != nil && meta.Draft && !opts.Drafts {
+				drafts[id] = true
+				return nil
+			}
+			meta = withHistory(meta, rel, history, config.EditURL)
+			if copyUnchanged(oldManifest, rel, id, hash, dao, stage) {
+				manifest[rel] = ManifestEntry{ID: id, Hash: hash, Meta: meta}
+				return nil
 			}
 
-			buf := new(bytes.Buffer)
-			if err := innerHTMLTemplate.Execute(buf, "inner", data); err != nil {
+			if data, err = renderPage(prefix, data, renderer, diagrams); err != nil {
 				return err
 			}
-			data = buf.Bytes()
-
-			id = getHTMLPath("/", route, path[len(abs):])
 		} else {
-			id = filepath.Join("/", route, path[len(abs):])
+			id = filepath.Join("/", prefix, rel)
+
+			// OpenAPI specs are built into reference pages, and kept as they are for download
+			if openAPIExtensions[filepath.Ext(path)] {
+				spec, err := parseOpenAPISpec(data)
+				if err != nil {
+					return fmt.Errorf("udocs.Build failed to parse the OpenAPI spec %s: %v", rel, err)
+				}
+				if spec != nil {
+					pageHash := renderHash(data, markdownOpts)
+					for _, page := range spec.pages(id) {
+						apiPages[id] = append(apiPages[id], page.Page)
+						key := rel + "#" + page.Path
+						pageMeta := withHistory(&PageMeta{Title: page.Title, Description: page.Description}, rel, history, config.EditURL)
+						if copyUnchanged(oldManifest, key, page.Path, pageHash, dao, stage) {
+							manifest[key] = ManifestEntry{ID: page.Path, Hash: pageHash, Meta: pageMeta}
+							continue
+						}
+						html, err := renderPage(prefix, page.markdown, renderer, diagrams)
+						if err != nil {
+							return err
+						}
+						if err := stage.Insert(page.Path, html); err != nil {
+							return err
+						}
+						report.record(oldManifest, key, page.Path)
+						manifest[key] = ManifestEntry{ID: page.Path, Hash: pageHash, Meta: pageMeta}
+					}
+				}
+			}
+
+			if copyUnchanged(oldManifest, rel, id, hash, dao, stage) {
+				manifest[rel] = oldManifest[rel]
+				return nil
+			}
 		}
 
-		if err := dao.Insert(id, data); err != nil {
+		if err := stage.Insert(id, data); err != nil {
 			return err
 		}
 
-		// delete(purgeList, id)
+		report.record(oldManifest, rel, id)
+		manifest[rel] = ManifestEntry{ID: id, Hash: hash, Meta: meta}
 		return nil
 
 	}); err != nil {
-		return err
+		return nil, err
+	}
+
+	// pages whose source file was deleted or renamed since the previous build are simply not staged
+	for rel, entry := range oldManifest {
+		if _, ok := manifest[rel]; ok || entry.ID == SIDEBAR_JSON {
+			continue
+		}
+		report.Removed = append(report.Removed, entry.ID)
 	}
+	report.sort()
 
-	// for filename := range purgeList {
-	// 	if err := dao.Delete(filename); err != nil {
-	// 		return err
-	// 	}
-	// }
+	if err := manifest.Save(prefix, stage); err != nil {
+		return nil, err
+	}
 
-	if err := LoadQuipDocuments(summary, dao); err != nil {
-		return err
+	metadata := manifest.Metadata()
+	if err := metadata.Save(prefix, stage); err != nil {
+		return nil, err
+	}
+	summary.applyAPIPages(apiPages)
+	summary.applyMetadata(metadata, drafts)
+
+	if version != "" {
+		if err := summary.saveVersion(stage); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := LoadQuipDocuments(summary, stage); err != nil {
+		return nil, err
+	}
+
+	if err := stage.Commit(); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	// the sidebar is reloaded, in case another route was published meanwhile
+	if err := UpdateSidebar(dao, func(sidebar Sidebar) (Sidebar, error) {
+		return sidebar.Merge(summary), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, id := range report.Removed {
+		if err := dao.Unindex(id); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := UpdateSearchIndex(summary, dao); err != nil {
-		return err
+		return nil, err
 	}
 
+	return report, nil
+}
+
+// renderPage renders a markdown page of the route at prefix into the HTML that is stored for it.
+func renderPage(prefix string, data []byte, renderer Renderer, diagrams *Diagrams) ([]byte, error) {
+	data, err := processMarkdown(prefix, data, renderer, diagrams)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := innerHTMLTemplate.Execute(buf, "inner", data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// withHistory adds the git history of the source file rel of a page, and the URL it is edited at, to the
+// metadata of the page, which is created if the page has none.
+func withHistory(meta *PageMeta, rel string, history GitHistory, editURLTemplate string) *PageMeta {
+	h := history[rel]
+	url := editURL(editURLTemplate, rel, h)
+	if h == nil && url == "" {
+		return meta
+	}
+	if meta == nil {
+		meta = new(PageMeta)
+	}
+	meta.Git, meta.EditURL = h, url
+	return meta
+}
+
+// copyUnchanged copies the page built from an unchanged source file from dao into stage. It reports
+// false when the source file changed, or its page could not be copied, in which case it must be rebuilt.
+func copyUnchanged(manifest Manifest, rel, id, hash string, dao storage.Dao, stage storage.Store) bool {
+	if !manifest.Unchanged(rel, id, hash) {
+		return false
+	}
+	data, err := dao.Fetch(id)
+	if err != nil {
+		return false
+	}
+	return stage.Insert(id, data) == nil
+}
+
+// buildLocks holds a lock for each route being built, which is removed once no build waits for it.
+var buildLocks = struct {
+	sync.Mutex
+	routes map[string]*buildLock
+}{routes: make(map[string]*buildLock)}
+
+type buildLock struct {
+	sync.Mutex
+	waiting int
+}
+
+// LockRoute waits until no other build of route, or of a version of it, is running in this process, and
+// returns the function that lets the next one run. Otherwise concurrent builds would each stage from the
+// same previous build, and the last to commit would drop the pages and versions of the others.
+func LockRoute(route string) (unlock func()) {
+	route = strings.Trim(route, "/")
+
+	buildLocks.Lock()
+	lock, ok := buildLocks.routes[route]
+	if !ok {
+		lock = new(buildLock)
+		buildLocks.routes[route] = lock
+	}
+	lock.waiting++
+	buildLocks.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		buildLocks.Lock()
+		if lock.waiting--; lock.waiting == 0 {
+			delete(buildLocks.routes, route)
+		}
+		buildLocks.Unlock()
+	}
+}
+
+// copyVersion copies the pages of a version of a guide from dao into stage.
+func copyVersion(route, version string, dao storage.Dao, stage storage.Store) error {
+	prefix := filepath.Join(route, version)
+	manifest, err := LoadManifest(prefix, dao)
+	if err != nil {
+		return nil // nothing to carry over
+	}
+
+	ids := []string{getManifestID(prefix), getMetadataID(prefix), getVersionSummaryID(route, version)}
+	for _, entry := range manifest {
+		if entry.ID != SIDEBAR_JSON {
+			ids = append(ids, entry.ID)
+		}
+	}
+	for _, id := range ids {
+		data, err := dao.Fetch(id)
+		if err != nil {
+			continue
+		}
+		if err := stage.Insert(id, data); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func LoadQuipDocuments(summary Summary, dao storage.Dao) error {
+func LoadQuipDocuments(summary Summary, dao storage.Store) error {
 	var walk func(pages []Page) error
 	walk = func(pages []Page) error {
 		for _, page := range pages {
@@ -150,7 +421,7 @@ func LoadQuipDocuments(summary Summary, dao storage.Dao) error {
 					return err
 				}
 
-				if err := dao.Insert(getPageID(summary.Route, id), []byte(thread.HTML)); err != nil {
+				if err := dao.Insert(getPageID(summary.Prefix(), id), []byte(thread.HTML)); err != nil {
 					return err
 				}
 			}
@@ -184,8 +455,8 @@ func WatchFiles(dir string, watch chan struct{}, kill chan error) {
 		select {
 		case event := <-watcher.Events:
 			if event.Op&fsnotify.Chmod != fsnotify.Chmod {
-				// only notify on changes to the actual file
-				if strings.HasSuffix(event.Name, ".md") {
+				// only notify on changes to the actual file, which may be a source file included by a page
+				if !strings.HasPrefix(filepath.Base(event.Name), ".") && !strings.HasSuffix(event.Name, "~") {
 					log.Println("file modified: ", event.Name)
 					watch <- struct{}{}
 				}
@@ -210,31 +481,123 @@ func ExtractRoute(r io.Reader) string {
 	return clean.ReplaceAllString(alphanum.ReplaceAllString(sb.String(), "-"), "")
 }
 
+var routeRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateRoute checks that route names a single guide, as ExtractRoute does, so that it cannot reach
+// outside of the directories and the storage it is joined to, nor match several guides as a glob.
+func ValidateRoute(route string) error {
+	if !routeRegex.MatchString(route) {
+		return fmt.Errorf("route %q may only contain letters, digits, '.', '-' and '_'", route)
+	}
+	return nil
+}
+
+// UpdateSearchIndex indexes every page of the summary, along with the guide header, the breadcrumb
+// of the page in the sidebar tree, and the sections of the page.
 func UpdateSearchIndex(summary Summary, dao storage.Dao) error {
-	var walk func(pages []Page) error
-	walk = func(pages []Page) error {
-		for _, page := range pages {
-			pageID, pageTitle := page.Path, page.Title
-			if strings.HasSuffix(pageID, SIDEBAR_JSON) {
-				continue
-			}
+	return walkSearchDocuments(summary, dao, func(pageID string, doc storage.SearchDocument) error {
+		return dao.Index(pageID, doc)
+	})
+}
 
-			pageData, err := dao.Fetch(pageID)
-			if err != nil {
-				continue
+// ReconcileSearchIndex brings the search index in line with the pages of the sidebar, including those of
+// every published version of versioned guides. Pages that are missing from the index, or whose content
+// changed since they were indexed, are (re)indexed, and indexed pages that no longer belong to any guide
+// are removed. It returns the number of pages indexed and removed.
+func ReconcileSearchIndex(sidebar Sidebar, dao storage.Dao) (int, int, error) {
+	hashes, err := dao.IndexedHashes()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	indexed, removed := 0, 0
+	found := make(map[string]struct{})
+	for _, summary := range sidebar.Summaries(dao) {
+		if err := walkSearchDocuments(summary, dao, func(pageID string, doc storage.SearchDocument) error {
+			found[pageID] = struct{}{}
+			if hash, ok := hashes[pageID]; ok && hash == doc.Hash {
+				return nil
 			}
+			indexed++
+			return dao.Index(pageID, doc)
+		}); err != nil {
+			return indexed, removed, err
+		}
+	}
 
-			if err := dao.Index(pageID, pageTitle, pageData); err != nil {
-				return err
+	for pageID := range hashes {
+		if _, ok := found[pageID]; ok {
+			continue
+		}
+		if err := dao.Unindex(pageID); err != nil {
+			return indexed, removed, err
+		}
+		removed++
+	}
+
+	return indexed, removed, nil
+}
+
+// walkSearchDocuments calls fn with the search document of every page of the summary that exists in dao.
+func walkSearchDocuments(summary Summary, dao storage.Dao, fn func(pageID string, doc storage.SearchDocument) error) error {
+	metadata, _ := LoadMetadata(summary.Prefix(), dao) // load metadata, if it exists
+
+	var walk func(pages []Page, breadcrumb []string) error
+	walk = func(pages []Page, breadcrumb []string) error {
+		for _, page := range pages {
+			crumbs := append(append([]string{}, breadcrumb...), page.Title)
+			if pageID := page.Path; pageID != "" && !strings.HasSuffix(pageID, SIDEBAR_JSON) {
+				if pageData, err := dao.Fetch(pageID); err == nil {
+					doc, err := newSearchDocument(summary, page, metadata[pageID], crumbs, pageData)
+					if err != nil {
+						return err
+					}
+					if err := fn(pageID, doc); err != nil {
+						return err
+					}
+				}
 			}
 
-			if len(page.SubPages) > 0 {
-				walk(page.SubPages)
+			if err := walk(page.SubPages, crumbs); err != nil {
+				return err
 			}
 		}
 		return nil
 	}
-	return walk(summary.Pages)
+	return walk(summary.Pages, []string{summary.Header})
+}
+
+func newSearchDocument(summary Summary, page Page, meta *PageMeta, breadcrumb []string, data []byte) (storage.SearchDocument, error) {
+	sections, err := extractSections(data)
+	if err != nil {
+		return storage.SearchDocument{}, err
+	}
+
+	doc := storage.SearchDocument{
+		Title:      page.Title,
+		Route:      strings.Trim(summary.Route, "/"),
+		Version:    summary.Version,
+		Header:     summary.Header,
+		Breadcrumb: breadcrumb,
+		Body:       extractText(data),
+		Sections:   sections,
+	}
+
+	if meta != nil {
+		doc.Description, doc.Tags, doc.Owners = meta.Description, meta.Tags, meta.Owners
+		if meta.Git != nil {
+			doc.Modified = meta.Git.Updated
+		}
+	}
+
+	// every field is hashed, since the header, breadcrumb or metadata of a page can change without its
+	// content changing, and they are indexed all the same
+	serialized, err := json.Marshal(doc)
+	if err != nil {
+		return storage.SearchDocument{}, err
+	}
+	doc.Hash = hashContent(serialized)
+	return doc, nil
 }
 
 func getPageID(route, path string) string {
@@ -2,6 +2,8 @@ package udocs
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -26,7 +28,7 @@ func TestBuild(t *testing.T) {
 	}
 
 	dao := storage.NewMockDao("/tmp")
-	if err := Build("test-route", dir, dao); err != nil {
+	if _, err := Build("test-route", dir, dao); err != nil {
 		t.Errorf("Build(test-route, %s, *APIMockDao) => %v", dir, err)
 	}
 
@@ -43,6 +45,126 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+func TestBuildIncremental(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		README_MD:  "# Test\nHello, world!",
+		SUMMARY_MD: "# Test\n* [Overview](README.md)\n* [Page](page.md)",
+		"page.md":  "# Page\nThis is only a test.",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	dao := storage.NewMockDao("/tmp")
+	report, err := Build("test-route", dir, dao)
+	if err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+	if len(report.Added) != 3 || len(report.Updated) != 0 || len(report.Removed) != 0 {
+		t.Errorf("initial build -> expected: 3 added, got: %s", report)
+	}
+
+	report, err = Build("test-route", dir, dao)
+	if err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+	if len(report.Added) != 0 || len(report.Updated) != 0 || len(report.Removed) != 0 {
+		t.Errorf("unchanged rebuild -> expected: no changes, got: %s", report)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, README_MD), []byte("# Test\nChanged."), 0644); err != nil {
+		t.Fatalf("Terminating test due to failed file write: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "page.md")); err != nil {
+		t.Fatalf("Terminating test due to failed file removal: %v", err)
+	}
+
+	report, err = Build("test-route", dir, dao)
+	if err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+	if len(report.Updated) != 1 || report.Updated[0] != "/test-route/index.html" {
+		t.Errorf("Updated -> expected: [/test-route/index.html] got: %v", report.Updated)
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != "/test-route/page.html" {
+		t.Errorf("Removed -> expected: [/test-route/page.html] got: %v", report.Removed)
+	}
+	if _, err := dao.Fetch("/test-route/page.html"); err == nil {
+		t.Error("expected /test-route/page.html to be purged after its source file was removed")
+	}
+}
+
+func TestReconcileSearchIndex(t *testing.T) {
+	dir, err := filepath.Abs("../../docs") // the actual docs directory for UDocs
+	if err != nil {
+		t.Fatal("udocs.TestReconcileSearchIndex: UDocs docs directory is missing")
+	}
+
+	dao := storage.NewMockDao("/tmp")
+	if _, err := Build("test-route", dir, dao); err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+	sidebar, err := LoadSidebar(dao)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed sidebar load: %v", err)
+	}
+
+	dao.ResetIndex()
+	dao.Index("/stale/index.html", storage.SearchDocument{Title: "Stale"})
+	if indexed, removed, err := ReconcileSearchIndex(sidebar, dao); err != nil || indexed != 2 || removed != 1 {
+		t.Errorf("ReconcileSearchIndex -> expected: 2 indexed, 1 removed, got: %d indexed, %d removed (%v)", indexed, removed, err)
+	}
+
+	if indexed, removed, err := ReconcileSearchIndex(sidebar, dao); err != nil || indexed != 0 || removed != 0 {
+		t.Errorf("ReconcileSearchIndex -> expected: 0 indexed, 0 removed, got: %d indexed, %d removed (%v)", indexed, removed, err)
+	}
+}
+
+func TestSearchDocumentHash(t *testing.T) {
+	summary := Summary{Route: "test-route", Header: "Test"}
+	page := Page{Title: "Overview", Path: "/test-route/index.html"}
+	data := []byte("<h1>Overview</h1><p>Body</p>")
+	doc, err := newSearchDocument(summary, page, nil, []string{"Test"}, data)
+	if err != nil {
+		t.Fatalf("newSearchDocument => %v", err)
+	}
+
+	// a page is reindexed whenever any of its indexed fields changes, not only its content
+	testCases := map[string]func() (storage.SearchDocument, error){
+		"header": func() (storage.SearchDocument, error) {
+			return newSearchDocument(Summary{Route: "test-route", Header: "Renamed"}, page, nil, []string{"Test"}, data)
+		},
+		"version": func() (storage.SearchDocument, error) {
+			return newSearchDocument(Summary{Route: "test-route", Header: "Test", Version: "v2"}, page, nil, []string{"Test"}, data)
+		},
+		"title": func() (storage.SearchDocument, error) {
+			return newSearchDocument(summary, Page{Title: "Renamed", Path: page.Path}, nil, []string{"Test"}, data)
+		},
+		"breadcrumb": func() (storage.SearchDocument, error) {
+			return newSearchDocument(summary, page, nil, []string{"Test", "Guides"}, data)
+		},
+		"metadata": func() (storage.SearchDocument, error) {
+			return newSearchDocument(summary, page, &PageMeta{Tags: []string{"new"}}, []string{"Test"}, data)
+		},
+	}
+	for field, changed := range testCases {
+		if other, err := changed(); err != nil || other.Hash == doc.Hash {
+			t.Errorf(errFmt, "hash after changing the "+field, "a new hash", other.Hash)
+		}
+	}
+	if same, _ := newSearchDocument(summary, page, nil, []string{"Test"}, data); same.Hash != doc.Hash {
+		t.Errorf(errFmt, "hash of the same page", doc.Hash, same.Hash)
+	}
+}
+
 const testSummary = `
 # My Test 1.0 	(Route/Path)
 * [Overview](README.md)
@@ -58,3 +180,45 @@ func TestExtractRoute(t *testing.T) {
 		t.Errorf("Expected: %s, Got: %s", expected, route)
 	}
 }
+
+func TestBuildFailureLeavesRouteIntact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		README_MD:  "# Test\nFirst build.",
+		SUMMARY_MD: "# Test\n* [Overview](README.md)",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	dao := storage.NewMockDao("/tmp")
+	if _, err := Build("test-route", dir, dao); err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+
+	// README.md is rebuilt before SUMMARY.md fails to parse
+	files = map[string]string{
+		README_MD:  "# Test\nSecond build.",
+		SUMMARY_MD: "* [Overview](README.md)",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+	if _, err := Build("test-route", dir, dao); err == nil {
+		t.Fatal("expected Build to fail on a SUMMARY.md without a header")
+	}
+
+	data, err := dao.Fetch("/test-route/index.html")
+	if err != nil || !bytes.Contains(data, []byte("First build.")) {
+		t.Errorf("expected the failed build to leave the first build in place, got: %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,239 @@
+package udocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rice "github.com/GeertJohan/go.rice"
+	"github.com/seanawilliams/udocs/cli/storage"
+	"golang.org/x/net/html"
+)
+
+const (
+	SEARCH_HTML       = "search.html"
+	SEARCH_INDEX_JSON = "search_index.json"
+)
+
+// ExportOptions configures a static site export.
+type ExportOptions struct {
+	// BaseURL is prepended to every internal link. When empty, links are rewritten relative to each page.
+	BaseURL string
+	// Params are the template parameters used when rendering the document template (organization, color, etc).
+	Params map[string]interface{}
+}
+
+// SearchIndexEntry is a single page of the client-side search index written by Export.
+type SearchIndexEntry struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Export writes a route previously built into dao as a self-contained static site in dest. Every page is
+// rendered through the full document template with the sidebar baked in, the static assets are copied
+// alongside them, and a search index is generated for client-side search.
+func Export(route string, dao storage.Dao, dest string, opts ExportOptions) error {
+	manifest, err := LoadManifest(route, dao)
+	if err != nil {
+		return err
+	}
+
+	sidebar, err := LoadSidebar(dao)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{}
+	for k, v := range opts.Params {
+		params[k] = v
+	}
+	tmpl := MustParseTemplate(params, DefaultTemplateFiles()...).
+		WithParameter("static", true).
+		WithParameter("sidebar", sidebar)
+
+	prefix := filepath.Join("/", route)
+	for _, entry := range manifest {
+		if entry.ID == SIDEBAR_JSON {
+			continue
+		}
+
+		data, err := dao.Fetch(entry.ID)
+		if err != nil {
+			return err
+		}
+
+		page := strings.TrimPrefix(entry.ID, prefix)
+		if filepath.Ext(page) == ".html" {
+			buf := new(bytes.Buffer)
+			toc, err := ExtractTOC(data)
+			if err != nil {
+				return err
+			}
+			if err := tmpl.WithParameter("meta", entry.Meta).WithParameter("toc", toc).Execute(buf, "document", data); err != nil {
+				return err
+			}
+			if data, err = rewriteLinks(buf.Bytes(), prefix, page, opts.BaseURL); err != nil {
+				return err
+			}
+		}
+
+		if err := writeExportFile(filepath.Join(dest, page), data); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.WithParameter("query_result", nil).WithParameter("meta", nil).WithParameter("toc", nil).Execute(buf, "search", nil); err != nil {
+		return err
+	}
+	data, err := rewriteLinks(buf.Bytes(), prefix, "/"+SEARCH_HTML, opts.BaseURL)
+	if err != nil {
+		return err
+	}
+	if err := writeExportFile(filepath.Join(dest, SEARCH_HTML), data); err != nil {
+		return err
+	}
+
+	if err := exportSearchIndex(route, sidebar, dao, dest, opts.BaseURL); err != nil {
+		return err
+	}
+
+	return exportStaticAssets(filepath.Join(dest, "static"))
+}
+
+func exportSearchIndex(route string, sidebar Sidebar, dao storage.Dao, dest, baseURL string) error {
+	prefix := filepath.Join("/", route)
+	entries := make([]SearchIndexEntry, 0)
+
+	var walk func(pages []Page)
+	walk = func(pages []Page) {
+		for _, page := range pages {
+			if data, err := dao.Fetch(page.Path); err == nil {
+				url := strings.TrimPrefix(page.Path, prefix)
+				if baseURL != "" {
+					url = strings.TrimSuffix(baseURL, "/") + url
+				} else {
+					url = strings.TrimPrefix(url, "/")
+				}
+				entries = append(entries, SearchIndexEntry{URL: url, Title: page.Title, Body: extractText(data)})
+			}
+			walk(page.SubPages)
+		}
+	}
+	for _, summary := range sidebar {
+		if summary.Route == route {
+			walk(summary.Pages)
+		}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return writeExportFile(filepath.Join(dest, SEARCH_INDEX_JSON), data)
+}
+
+func exportStaticAssets(dest string) error {
+	box, err := rice.FindBox("../../static")
+	if err != nil {
+		return err
+	}
+
+	return box.Walk("", func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if filepath.Base(path) == "templates" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		data, err := box.Bytes(path)
+		if err != nil {
+			return err
+		}
+		return writeExportFile(filepath.Join(dest, path), data)
+	})
+}
+
+// rewriteLinks rewrites the absolute links of a rendered page so the page can be hosted from any location.
+// Links into the route (and to /static, /search and the search index) are made relative to page, or
+// prefixed with baseURL.
+func rewriteLinks(document []byte, prefix, page, baseURL string) ([]byte, error) {
+	dom, err := html.Parse(bytes.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			for i, a := range node.Attr {
+				if a.Key == "href" || a.Key == "src" || a.Key == "action" || a.Key == "data-index" {
+					node.Attr[i].Val = exportURL(a.Val, prefix, page, baseURL)
+				}
+			}
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(dom)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, dom); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func exportURL(url, prefix, page, baseURL string) string {
+	if !strings.HasPrefix(url, "/") || strings.HasPrefix(url, "//") {
+		return url
+	}
+
+	target := url
+	switch {
+	case url == "/search":
+		target = "/" + SEARCH_HTML
+	case url == prefix:
+		target = "/" + INDEX_HTML
+	case strings.HasPrefix(url, prefix+"/"):
+		target = url[len(prefix):]
+	case url == "/"+SEARCH_INDEX_JSON, strings.HasPrefix(url, "/static/"):
+	default:
+		return url
+	}
+
+	if baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/") + target
+	}
+	return relativeURL(page, target)
+}
+
+// relativeURL returns the path of target relative to the directory of page, where both are absolute URL paths.
+func relativeURL(page, target string) string {
+	fragment := ""
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target, fragment = target[:i], target[i:]
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(page), target)
+	if err != nil {
+		return target + fragment
+	}
+	return filepath.ToSlash(rel) + fragment
+}
+
+func writeExportFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
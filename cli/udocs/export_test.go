@@ -0,0 +1,69 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+func TestExport(t *testing.T) {
+	dir, err := filepath.Abs("../../docs") // the actual docs directory for UDocs
+	if err != nil {
+		t.Fatal("udocs.TestExport: UDocs docs directory is missing")
+	}
+
+	dest, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dest)
+
+	dao := storage.NewMockDao("/tmp")
+	if _, err := Build("test-route", dir, dao); err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+
+	if err := Export("test-route", dao, dest, ExportOptions{}); err != nil {
+		t.Fatalf("Export(test-route, *MockDao, %s) => %v", dest, err)
+	}
+
+	for _, f := range []string{INDEX_HTML, "BestPractices.html", SEARCH_HTML, SEARCH_INDEX_JSON, "static/styles/app.css"} {
+		if _, err := os.Stat(filepath.Join(dest, f)); err != nil {
+			t.Errorf("Export(test-route, *MockDao, %s) => missing %s", dest, f)
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dest, INDEX_HTML))
+	if err != nil {
+		t.Fatalf("Terminating test due to failed file read: %v", err)
+	}
+	if page := string(data); !strings.Contains(page, `href="static/styles/app.css"`) || strings.Contains(page, `href="/test-route/`) {
+		t.Errorf("Export(test-route, *MockDao, %s) => expected relative links in %s", dest, INDEX_HTML)
+	}
+}
+
+func TestExportURL(t *testing.T) {
+	testCases := []struct {
+		url, page, baseURL, expected string
+	}{
+		{url: "/route/page.html", page: "/index.html", expected: "page.html"},
+		{url: "/route/page.html#section", page: "/sub/index.html", expected: "../page.html#section"},
+		{url: "/route", page: "/sub/page.html", expected: "../index.html"},
+		{url: "/static/styles/app.css", page: "/sub/page.html", expected: "../static/styles/app.css"},
+		{url: "/search", page: "/index.html", expected: SEARCH_HTML},
+		{url: "/route/page.html", page: "/sub/index.html", baseURL: "https://docs.example.com/", expected: "https://docs.example.com/page.html"},
+		{url: "/other-route/page.html", page: "/index.html", expected: "/other-route/page.html"},
+		{url: "https://example.com/page.html", page: "/index.html", expected: "https://example.com/page.html"},
+		{url: "#section", page: "/index.html", expected: "#section"},
+	}
+
+	for _, tc := range testCases {
+		if got := exportURL(tc.url, "/route", tc.page, tc.baseURL); got != tc.expected {
+			t.Errorf(errFmt, tc.url, tc.expected, got)
+		}
+	}
+}
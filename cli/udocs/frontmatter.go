@@ -0,0 +1,184 @@
+package udocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/seanawilliams/udocs/cli/storage"
+	"gopkg.in/yaml.v2"
+)
+
+// META_JSON maps the pages of a route that have front matter to their metadata, as in /:route/meta.json.
+const META_JSON = "meta.json"
+
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// PageMeta is the metadata of a page, given by the YAML (between "---" lines) or TOML (between "+++"
+// lines) front matter at the top of its markdown file.
+//
+// Title is used in the sidebar when SUMMARY.md gives the page no title of its own. Draft pages are only
+// built by udocs-serve. RedirectFrom lists former paths of the page, relative to the docs directory, that
+// redirect to it. Template names the template the page is rendered with, instead of "document".
+//
+// Git and EditURL are not given by the front matter, but by the git history of the markdown file and the
+// edit_url of the udocs.yml of the guide, at build time.
+type PageMeta struct {
+	Title        string   `json:"title,omitempty" yaml:"title" toml:"title"`
+	Description  string   `json:"description,omitempty" yaml:"description" toml:"description"`
+	Tags         []string `json:"tags,omitempty" yaml:"tags" toml:"tags"`
+	Owners       []string `json:"owners,omitempty" yaml:"owners" toml:"owners"`
+	Draft        bool     `json:"draft,omitempty" yaml:"draft" toml:"draft"`
+	RedirectFrom []string `json:"redirect_from,omitempty" yaml:"redirect_from" toml:"redirect_from"`
+	Template     string   `json:"template,omitempty" yaml:"template" toml:"template"`
+
+	Git     *PageHistory `json:"git,omitempty" yaml:"-" toml:"-"`
+	EditURL string       `json:"edit_url,omitempty" yaml:"-" toml:"-"`
+}
+
+// ParseFrontMatter splits the front matter off the top of a markdown page, and returns it parsed along
+// with the rest of the page. The metadata is nil when the page has no front matter.
+func ParseFrontMatter(data []byte) (*PageMeta, []byte, error) {
+	var delimiter string
+	switch {
+	case bytes.HasPrefix(data, []byte(yamlDelimiter)):
+		delimiter = yamlDelimiter
+	case bytes.HasPrefix(data, []byte(tomlDelimiter)):
+		delimiter = tomlDelimiter
+	default:
+		return nil, data, nil
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if string(bytes.TrimSpace(lines[0])) != delimiter {
+		return nil, data, nil
+	}
+
+	offset := len(lines[0])
+	for _, line := range lines[1:] {
+		if string(bytes.TrimSpace(line)) != delimiter {
+			offset += len(line)
+			continue
+		}
+
+		meta := new(PageMeta)
+		raw := data[len(lines[0]):offset]
+		var err error
+		if delimiter == yamlDelimiter {
+			err = yaml.Unmarshal(raw, meta)
+		} else {
+			_, err = toml.Decode(string(raw), meta)
+		}
+		if err != nil {
+			return nil, data, fmt.Errorf("udocs.ParseFrontMatter: %v", err)
+		}
+		return meta, data[offset+len(line):], nil
+	}
+
+	// without a closing delimiter, the page merely starts with a thematic break
+	return nil, data, nil
+}
+
+// Metadata maps the ids of the pages of a route to their metadata.
+type Metadata map[string]*PageMeta
+
+func LoadMetadata(route string, dao storage.Dao) (Metadata, error) {
+	metadata := make(Metadata)
+
+	data, err := dao.Fetch(getMetadataID(route))
+	if err != nil {
+		return metadata, err
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return metadata, err
+	}
+
+	return metadata, nil
+}
+
+// FindMetadata returns the metadata of the route that serves the page with the given id, which is
+// found in the closest ancestor directory of the page that has any.
+func FindMetadata(id string, dao storage.Dao) Metadata {
+	for dir := path.Dir(id); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		if metadata, err := LoadMetadata(dir, dao); err == nil {
+			return metadata
+		}
+	}
+	return make(Metadata)
+}
+
+func (m Metadata) Save(route string, dao storage.Store) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return dao.Insert(getMetadataID(route), data)
+}
+
+// Redirect returns the id of the page that lists id as one of its former paths.
+func (m Metadata) Redirect(id string) (string, bool) {
+	for target, meta := range m {
+		for _, from := range meta.redirects(target) {
+			if from == id {
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
+
+// redirects returns the ids that redirect to the page with the given id, which is served from the same
+// route as the former paths of the page.
+func (meta *PageMeta) redirects(id string) []string {
+	prefix := filepath.Dir(id)
+	ids := make([]string, 0, len(meta.RedirectFrom))
+	for _, from := range meta.RedirectFrom {
+		if strings.HasPrefix(from, "/") {
+			ids = append(ids, getHTMLPath(from))
+		} else {
+			ids = append(ids, getHTMLPath(prefix, from))
+		}
+	}
+	return ids
+}
+
+// applyMetadata fills in the sidebar entries of the summary from the metadata of their pages. Entries of
+// pages that were not built because they are drafts are dropped, keeping the entries nested under them.
+func (s *Summary) applyMetadata(metadata Metadata, drafts map[string]bool) {
+	var apply func(pages []Page) []Page
+	apply = func(pages []Page) []Page {
+		applied := make([]Page, 0, len(pages))
+		for _, page := range pages {
+			page.SubPages = apply(page.SubPages)
+			if drafts[page.Path] {
+				if len(page.SubPages) == 0 {
+					continue
+				}
+				page.Path = ""
+			}
+			if meta, ok := metadata[page.Path]; ok && page.Path != "" {
+				if page.Title == "" {
+					page.Title = meta.Title
+				}
+				page.Description, page.Draft = meta.Description, meta.Draft
+			}
+			if page.Title == "" && page.Path != "" {
+				page.Title = strings.TrimSuffix(filepath.Base(getMarkdownPath(page.Path)), ".md")
+			}
+			applied = append(applied, page)
+		}
+		return applied
+	}
+	s.Pages = apply(s.Pages)
+}
+
+func getMetadataID(route string) string {
+	return filepath.Join("/", route, META_JSON)
+}
@@ -0,0 +1,117 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected *PageMeta
+		body     string
+		err      bool
+	}{
+		{
+			given:    "---\ntitle: Hello\ntags: [a, b]\ndraft: true\nredirect_from:\n  - old.md\n---\n# Hello\n",
+			expected: &PageMeta{Title: "Hello", Tags: []string{"a", "b"}, Draft: true, RedirectFrom: []string{"old.md"}},
+			body:     "# Hello\n",
+		},
+		{
+			given:    "+++\ntitle = \"Hello\"\nowners = [\"docs-team\"]\ntemplate = \"wide\"\n+++\n# Hello\n",
+			expected: &PageMeta{Title: "Hello", Owners: []string{"docs-team"}, Template: "wide"},
+			body:     "# Hello\n",
+		},
+		{given: "# Hello\n---\ntitle: Hello\n---\n", body: "# Hello\n---\ntitle: Hello\n---\n"},
+		{given: "---\n# Hello\n", body: "---\n# Hello\n"},
+		{given: "---\ntitle: [Hello\n---\n# Hello\n", err: true},
+	}
+
+	for _, tc := range testCases {
+		meta, body, err := ParseFrontMatter([]byte(tc.given))
+		if tc.err {
+			if err == nil {
+				t.Errorf(errFmt, tc.given, "an error", meta)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf(errFmt, tc.given, tc.expected, err)
+			continue
+		}
+		if !reflect.DeepEqual(meta, tc.expected) {
+			t.Errorf(errFmt, tc.given, tc.expected, meta)
+		}
+		if string(body) != tc.body {
+			t.Errorf(errFmt, tc.given, tc.body, string(body))
+		}
+	}
+}
+
+func TestBuildFrontMatter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		README_MD:  "# Test\nHello, world!",
+		SUMMARY_MD: "# Test\n* [Overview](README.md)\n* [](page.md)\n* [Draft](draft.md)",
+		"page.md":  "---\ntitle: Titled Page\ndescription: A page with front matter\nredirect_from: [moved.md]\n---\n# Page\n",
+		"draft.md": "+++\ndraft = true\n+++\n# Draft\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		opts  BuildOptions
+		pages int
+	}{
+		{opts: BuildOptions{}, pages: 2},
+		{opts: BuildOptions{Drafts: true}, pages: 3},
+	}
+
+	for _, tc := range testCases {
+		dao := storage.NewMockDao("/tmp")
+		if _, err := BuildWithOptions("test-route", dir, dao, tc.opts); err != nil {
+			t.Fatalf("BuildWithOptions(test-route, %s, *MockDao, %+v) => %v", dir, tc.opts, err)
+		}
+
+		_, err := dao.Fetch("/test-route/draft.html")
+		if built := err == nil; built != tc.opts.Drafts {
+			t.Errorf(errFmt, tc.opts, tc.opts.Drafts, built)
+		}
+
+		sidebar, err := LoadSidebar(dao)
+		if err != nil || len(sidebar) != 1 {
+			t.Fatalf("Terminating test due to invalid sidebar: %v", err)
+		}
+		pages := sidebar[0].Pages
+		if len(pages) != tc.pages {
+			t.Fatalf(errFmt, tc.opts, tc.pages, pages)
+		}
+		if pages[1].Title != "Titled Page" || pages[1].Description != "A page with front matter" {
+			t.Errorf(errFmt, tc.opts, "the title and description of page.md", pages[1])
+		}
+		if tc.opts.Drafts && !pages[2].Draft {
+			t.Errorf(errFmt, tc.opts, "a draft entry", pages[2])
+		}
+
+		metadata, err := LoadMetadata("test-route", dao)
+		if err != nil {
+			t.Fatalf("Terminating test due to failed metadata load: %v", err)
+		}
+		if target, ok := metadata.Redirect("/test-route/moved.html"); !ok || target != "/test-route/page.html" {
+			t.Errorf(errFmt, "/test-route/moved.html", "/test-route/page.html", target)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package udocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GIT_HISTORY_JSON is the git history of a docs directory, which udocs publish sends next to the directory
+// in its tarball, since the server has no repository to read it from.
+const GIT_HISTORY_JSON = "git-history.json"
+
+// PageHistory is the git history of the source file of a page: when it was last changed, by whom and in
+// which commit, and everyone who has changed it, from the most to the least prolific.
+type PageHistory struct {
+	Updated      time.Time `json:"updated"`
+	Author       string    `json:"author"`
+	Commit       string    `json:"commit"`
+	Contributors []string  `json:"contributors,omitempty"`
+}
+
+// GitHistory maps the files of a docs directory, as in /guide/intro.md, to their git history.
+type GitHistory map[string]*PageHistory
+
+// ReadGitHistory reads the history of the files of the docs directory dir from the local git repository it
+// is in. It fails when dir is not in a git repository, or git is not installed.
+func ReadGitHistory(dir string) (GitHistory, error) {
+	prefix, err := gitOutput(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, fmt.Errorf("udocs.ReadGitHistory: %s is not in a git repository: %v", dir, err)
+	}
+	out, err := gitOutput(dir, "-c", "core.quotepath=off", "log", "--format=%x00%H%x1f%at%x1f%an", "--name-only", "--", ".")
+	if err != nil {
+		return nil, fmt.Errorf("udocs.ReadGitHistory failed to read the log of %s: %v", dir, err)
+	}
+	return parseGitLog(strings.TrimSpace(prefix), out), nil
+}
+
+// parseGitLog parses the log of the files under prefix, from the most recent commit to the earliest, as
+// printed by git log --format=%x00%H%x1f%at%x1f%an --name-only.
+func parseGitLog(prefix, log string) GitHistory {
+	history := make(GitHistory)
+	commits := make(map[string]map[string]int) // the number of commits to each file, by author
+	for _, entry := range strings.Split(log, "\x00") {
+		lines := strings.Split(strings.TrimSpace(entry), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		seconds, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range lines[1:] {
+			name = strings.TrimSpace(name)
+			if name == "" || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			file := path.Join("/", strings.TrimPrefix(name, prefix))
+			if _, ok := history[file]; !ok {
+				history[file] = &PageHistory{Updated: time.Unix(seconds, 0).UTC(), Author: fields[2], Commit: fields[0]}
+				commits[file] = make(map[string]int)
+			}
+			commits[file][fields[2]]++
+		}
+	}
+
+	for file, authors := range commits {
+		for author := range authors {
+			history[file].Contributors = append(history[file].Contributors, author)
+		}
+		contributors := history[file].Contributors
+		sort.Slice(contributors, func(i, j int) bool {
+			if authors[contributors[i]] != authors[contributors[j]] {
+				return authors[contributors[i]] > authors[contributors[j]]
+			}
+			return contributors[i] < contributors[j]
+		})
+	}
+	return history
+}
+
+// LoadGitHistory loads the history saved as filename, which is empty when there is no such file.
+func LoadGitHistory(filename string) (GitHistory, error) {
+	history := make(GitHistory)
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return history, nil
+	} else if err != nil {
+		return history, err
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return history, fmt.Errorf("udocs.LoadGitHistory failed to parse %s: %v", filename, err)
+	}
+	return history, nil
+}
+
+// Save saves the history as filename.
+func (h GitHistory) Save(filename string) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// editURL expands the edit_url of a guide for the source file rel of a page, as in /guide/intro.md. {path}
+// is replaced with the path of the file in the docs directory, and {commit} with the commit that last
+// changed it, or HEAD if it has no history.
+func editURL(template, rel string, history *PageHistory) string {
+	if template == "" {
+		return ""
+	}
+	commit := "HEAD"
+	if history != nil {
+		commit = history.Commit
+	}
+	return strings.NewReplacer("{path}", strings.TrimPrefix(rel, "/"), "{commit}", commit).Replace(template)
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
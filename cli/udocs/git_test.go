@@ -0,0 +1,147 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+func TestParseGitLog(t *testing.T) {
+	log := "\x00c3\x1f1700000300\x1fGrace\n\ndocs/README.md\n" +
+		"\x00c2\x1f1700000200\x1fAda\n\ndocs/README.md\ndocs/guide/intro.md\n" +
+		"\x00c1\x1f1700000100\x1fAda\n\ndocs/README.md\nother/file.md\n"
+
+	expected := GitHistory{
+		"/README.md":      {Updated: time.Unix(1700000300, 0).UTC(), Author: "Grace", Commit: "c3", Contributors: []string{"Ada", "Grace"}},
+		"/guide/intro.md": {Updated: time.Unix(1700000200, 0).UTC(), Author: "Ada", Commit: "c2", Contributors: []string{"Ada"}},
+	}
+	if got := parseGitLog("docs/", log); !reflect.DeepEqual(got, expected) {
+		t.Errorf(errFmt, log, expected, got)
+	}
+}
+
+func TestEditURL(t *testing.T) {
+	testCases := []struct {
+		template string
+		history  *PageHistory
+		expected string
+	}{
+		{template: "", history: nil, expected: ""},
+		{template: "https://example.com/edit/master/docs/{path}", history: nil, expected: "https://example.com/edit/master/docs/guide/intro.md"},
+		{template: "https://example.com/blob/{commit}/docs/{path}", history: nil, expected: "https://example.com/blob/HEAD/docs/guide/intro.md"},
+		{template: "https://example.com/blob/{commit}/docs/{path}", history: &PageHistory{Commit: "abc123"}, expected: "https://example.com/blob/abc123/docs/guide/intro.md"},
+	}
+
+	for _, tc := range testCases {
+		if got := editURL(tc.template, "/guide/intro.md", tc.history); got != tc.expected {
+			t.Errorf(errFmt, tc.template, tc.expected, got)
+		}
+	}
+}
+
+func TestBuildGitHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(repo)
+
+	dir := filepath.Join(repo, "docs")
+	files := map[string]string{
+		README_MD:    "# Test\nHello, world!",
+		SUMMARY_MD:   "# Test\n* [Overview](README.md)\n* [Page](page.md)",
+		"page.md":    "# Page\n",
+		GUIDE_CONFIG: "edit_url: https://example.com/edit/master/docs/{path}\n",
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Terminating test due to failed dir creation: %v", err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	commit := func(author, date string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=" + author, "-c", "user.email=" + author + "@example.com"}, args...)...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Terminating test due to failed git %v: %v: %s", args, err, out)
+		}
+	}
+	commit("Ada", "2024-03-01T12:00:00Z", "init", "-q")
+	commit("Ada", "2024-03-01T12:00:00Z", "add", ".")
+	commit("Ada", "2024-03-01T12:00:00Z", "commit", "-q", "-m", "Add docs")
+	if err := ioutil.WriteFile(filepath.Join(dir, "page.md"), []byte("# Page\nUpdated.\n"), 0644); err != nil {
+		t.Fatalf("Terminating test due to failed file write: %v", err)
+	}
+	commit("Grace", "2024-03-04T12:00:00Z", "commit", "-q", "-a", "-m", "Update page")
+
+	history, err := ReadGitHistory(dir)
+	if err != nil {
+		t.Fatalf("ReadGitHistory(%s) => %v", dir, err)
+	}
+	page := history["/page.md"]
+	if page == nil || page.Author != "Grace" || !page.Updated.Equal(time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)) || !reflect.DeepEqual(page.Contributors, []string{"Ada", "Grace"}) {
+		t.Errorf(errFmt, dir, "page.md last updated by Grace", page)
+	}
+
+	// the server builds the history published along with the docs, which is not read from any repository
+	testCases := []struct {
+		opts     BuildOptions
+		expected *PageHistory
+	}{
+		{opts: BuildOptions{}, expected: page},
+		{opts: BuildOptions{History: GitHistory{}}, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		dao := storage.NewMockDao("/tmp")
+		if _, err := BuildWithOptions("test-route", dir, dao, tc.opts); err != nil {
+			t.Fatalf("BuildWithOptions(test-route, %s, *MockDao, %+v) => %v", dir, tc.opts, err)
+		}
+		metadata, err := LoadMetadata("test-route", dao)
+		if err != nil {
+			t.Fatalf("Terminating test due to failed metadata load: %v", err)
+		}
+		meta := metadata["/test-route/page.html"]
+		if meta == nil || meta.EditURL != "https://example.com/edit/master/docs/page.md" {
+			t.Fatalf(errFmt, tc.opts, "the edit URL of page.md", meta)
+		}
+		if !reflect.DeepEqual(meta.Git, tc.expected) {
+			t.Errorf(errFmt, tc.opts, tc.expected, meta.Git)
+		}
+	}
+}
+
+func TestGitHistorySave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, GIT_HISTORY_JSON)
+	if history, err := LoadGitHistory(filename); err != nil || history == nil || len(history) != 0 {
+		t.Errorf(errFmt, filename, "an empty history", history)
+	}
+
+	given := GitHistory{"/README.md": {Updated: time.Unix(1700000000, 0).UTC(), Author: "Ada", Commit: "c1", Contributors: []string{"Ada"}}}
+	if err := given.Save(filename); err != nil {
+		t.Fatalf("Terminating test due to failed history save: %v", err)
+	}
+	if history, err := LoadGitHistory(filename); err != nil || !reflect.DeepEqual(history, given) {
+		t.Errorf(errFmt, filename, given, history)
+	}
+}
@@ -0,0 +1,286 @@
+package udocs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/syntaxhighlight"
+	"golang.org/x/net/html"
+)
+
+const (
+	// DEFAULT_HIGHLIGHT_THEME is the theme of highlighted code blocks when no other theme is configured.
+	DEFAULT_HIGHLIGHT_THEME = "github"
+
+	// codeInfoSeparator separates the language of a fenced code block from its attributes.
+	codeInfoSeparator = "--"
+)
+
+// HIGHLIGHT_THEMES are the themes of highlighted code blocks, each a stylesheet in /static/styles/highlight.
+var HIGHLIGHT_THEMES = []string{"github", "monokai", "solarized-light"}
+
+// plainLanguages are the languages of code blocks that are not highlighted, beyond their line structure.
+var plainLanguages = map[string]bool{"text": true, "txt": true, "plain": true, "plaintext": true, "none": true}
+
+// fenceRegex matches the opening line of a fenced code block, and captures its fence and info string.
+var fenceRegex = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\n]*?)[ \t]*$")
+
+// fenceInfoRegex matches the fields of the info string of a fenced code block: a range of lines to
+// highlight, as in {3-5,8}, a key=value or key="quoted value" attribute, or a bare word.
+var fenceInfoRegex = regexp.MustCompile(`\{[^}]*\}|[A-Za-z_-]+="[^"]*"|\S+`)
+
+// codeInfo is the language and the attributes of a fenced code block.
+type codeInfo struct {
+	Lang        string
+	Title       string
+	LineNumbers bool
+	Highlighted map[int]bool
+}
+
+// IsHighlightTheme reports whether theme is one of HIGHLIGHT_THEMES.
+func IsHighlightTheme(theme string) bool {
+	for _, t := range HIGHLIGHT_THEMES {
+		if t == theme {
+			return true
+		}
+	}
+	return false
+}
+
+// preprocessFences rewrites the info strings of fenced code blocks with attributes, as in
+//
+//	```go {3-5,8} title="main.go" linenos
+//
+// into a single word that the markdown renderer passes on as the language of the block, as in
+// "go--hl-3-5_8--title-6d61696e2e676f--linenos". The renderer does not recognize such fences otherwise,
+// and only lets letters, digits, dashes and underscores through into the class of the block.
+func preprocessFences(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	fence := ""
+	for i, line := range lines {
+		text := strings.TrimRight(string(line), "\r\n")
+		if fence != "" {
+			// a fence is closed by a fence of the same character, at least as long, without an info string
+			if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+
+		matches := fenceRegex.FindStringSubmatch(text)
+		if matches == nil {
+			continue
+		}
+		fence = matches[1]
+
+		fields := fenceInfoRegex.FindAllString(matches[2], -1)
+		if len(fields) == 0 || (len(fields) == 1 && !strings.HasPrefix(fields[0], "{")) {
+			continue
+		}
+
+		attrs := []string{"text"}
+		for j, field := range fields {
+			switch {
+			case strings.HasPrefix(field, "{"):
+				ranges := strings.Replace(strings.Trim(field, "{} "), " ", "", -1)
+				attrs = append(attrs, "hl-"+strings.Replace(ranges, ",", "_", -1))
+			case strings.Contains(field, "="):
+				kv := strings.SplitN(field, "=", 2)
+				if key := strings.ToLower(kv[0]); key == "title" || key == "filename" {
+					attrs = append(attrs, "title-"+hex.EncodeToString([]byte(strings.Trim(kv[1], `"`))))
+				}
+			case j == 0:
+				attrs[0] = strings.TrimPrefix(field, ".")
+			case strings.ToLower(field) == "linenos":
+				attrs = append(attrs, "linenos")
+			}
+		}
+
+		token := strings.Join(attrs, codeInfoSeparator)
+		newline := line[len(text):]
+		lines[i] = append([]byte(text[:strings.Index(text, fence)+len(fence)]+token), newline...)
+	}
+	return bytes.Join(lines, nil)
+}
+
+// parseCodeInfo parses the language of a code block, as set by the markdown renderer in the class of
+// the div around the block, along with the attributes encoded into it by preprocessFences.
+func parseCodeInfo(lang string) codeInfo {
+	attrs := strings.Split(lang, codeInfoSeparator)
+	info := codeInfo{Lang: attrs[0], Highlighted: make(map[int]bool)}
+
+	for _, attr := range attrs[1:] {
+		switch {
+		case attr == "linenos":
+			info.LineNumbers = true
+		case strings.HasPrefix(attr, "title-"):
+			if title, err := hex.DecodeString(strings.TrimPrefix(attr, "title-")); err == nil {
+				info.Title = string(title)
+			}
+		case strings.HasPrefix(attr, "hl-"):
+			for _, r := range strings.Split(strings.TrimPrefix(attr, "hl-"), "_") {
+				bounds := strings.SplitN(r, "-", 2)
+				start, err := strconv.Atoi(bounds[0])
+				if err != nil {
+					continue
+				}
+				end := start
+				if len(bounds) == 2 {
+					if end, err = strconv.Atoi(bounds[1]); err != nil {
+						continue
+					}
+				}
+				for line := start; line <= end; line++ {
+					info.Highlighted[line] = true
+				}
+			}
+		}
+	}
+	return info
+}
+
+// highlightCodeBlock replaces the content of the div the markdown renderer wraps a fenced code block
+// in with the highlighted lines of the code, in a <pre><code> block, preceded by the filename of the
+// block, if it has one, and a button that copies the code to the clipboard.
+func highlightCodeBlock(node *html.Node, info codeInfo) {
+	lang := strings.ToLower(info.Lang)
+	code := ""
+	if pre := node.FirstChild; pre != nil {
+		code = rawText(pre)
+	}
+
+	for c := node.FirstChild; c != nil; c = node.FirstChild {
+		node.RemoveChild(c)
+	}
+	node.Attr = []html.Attribute{html.Attribute{Key: "class", Val: "code-block"}}
+
+	if info.Title != "" {
+		title := element("div", "code-filename")
+		title.AppendChild(&html.Node{Type: html.TextNode, Data: info.Title})
+		node.AppendChild(title)
+	}
+
+	copyButton := element("button", "code-copy")
+	copyButton.Attr = append(copyButton.Attr,
+		html.Attribute{Key: "type", Val: "button"},
+		html.Attribute{Key: "title", Val: "Copy to clipboard"},
+		html.Attribute{Key: "aria-label", Val: "Copy to clipboard"},
+	)
+	copyButton.AppendChild(element("i", "fa fa-clipboard"))
+	node.AppendChild(copyButton)
+
+	preClass := "highlight"
+	if info.LineNumbers {
+		preClass += " line-numbers"
+	}
+	pre := element("pre", preClass)
+	codeElem := element("code", "language-"+lang)
+	pre.AppendChild(codeElem)
+	node.AppendChild(pre)
+
+	for i, tokens := range tokenizeCode(code, lang) {
+		n := i + 1
+		lineClass := "line"
+		if info.Highlighted[n] {
+			lineClass += " hl"
+		}
+		line := element("span", lineClass)
+		if info.LineNumbers {
+			number := element("span", "ln")
+			number.AppendChild(&html.Node{Type: html.TextNode, Data: strconv.Itoa(n)})
+			line.AppendChild(number)
+		}
+		for _, token := range tokens {
+			text := &html.Node{Type: html.TextNode, Data: token.text}
+			if token.class == "" {
+				line.AppendChild(text)
+				continue
+			}
+			span := element("span", token.class)
+			span.AppendChild(text)
+			line.AppendChild(span)
+		}
+		codeElem.AppendChild(line)
+		codeElem.AppendChild(&html.Node{Type: html.TextNode, Data: "\n"})
+	}
+}
+
+type codeToken struct {
+	class, text string
+}
+
+// tokenizeCode splits code into lines of highlighted tokens. Diffs are highlighted line by line, and
+// other languages with the language-independent lexer of syntaxhighlight.
+func tokenizeCode(code, lang string) [][]codeToken {
+	code = strings.TrimSuffix(code, "\n")
+
+	if lang == "diff" || lang == "patch" || plainLanguages[lang] {
+		var lines [][]codeToken
+		for _, line := range strings.Split(code, "\n") {
+			class := ""
+			switch {
+			case plainLanguages[lang]:
+			case strings.HasPrefix(line, "+"):
+				class = "gi"
+			case strings.HasPrefix(line, "-"):
+				class = "gd"
+			case strings.HasPrefix(line, "@@"):
+				class = "gu"
+			}
+			lines = append(lines, []codeToken{{class: class, text: line}})
+		}
+		return lines
+	}
+
+	printer := &linePrinter{lines: [][]codeToken{nil}}
+	syntaxhighlight.Print(syntaxhighlight.NewScanner([]byte(code)), nil, printer)
+	return printer.lines
+}
+
+// linePrinter is a syntaxhighlight.Printer that collects the tokens of each line of code, splitting the
+// tokens that span several lines, such as block comments.
+type linePrinter struct {
+	lines [][]codeToken
+}
+
+func (p *linePrinter) Print(w io.Writer, kind syntaxhighlight.Kind, text string) error {
+	class := syntaxhighlight.DefaultHTMLConfig.Class(kind)
+	for i, part := range strings.Split(text, "\n") {
+		if i > 0 {
+			p.lines = append(p.lines, nil)
+		}
+		if part != "" {
+			last := len(p.lines) - 1
+			p.lines[last] = append(p.lines[last], codeToken{class: class, text: part})
+		}
+	}
+	return nil
+}
+
+// rawText returns the text content of a node verbatim, with its whitespace intact.
+func rawText(node *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			buf.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+	return buf.String()
+}
+
+func element(tag, class string) *html.Node {
+	return &html.Node{
+		Type: html.ElementNode,
+		Data: tag,
+		Attr: []html.Attribute{html.Attribute{Key: "class", Val: class}},
+	}
+}
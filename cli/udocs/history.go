@@ -0,0 +1,152 @@
+package udocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HISTORY_JSON = "history.json"
+	// DEFAULT_HISTORY_SIZE is the number of snapshots kept per route when no limit is configured.
+	DEFAULT_HISTORY_SIZE = 10
+)
+
+// historyLock serializes updates to the publish history of every route.
+var historyLock sync.Mutex
+
+// Snapshot is a single publication of a route. The tarball it was published from is kept in the
+// archive directory of the route, so that the route can be rolled back to it. The archive directory is
+// on the local disk of the server, so snapshots are only kept when its storage is local too.
+type Snapshot struct {
+	ID        string    `json:"id"`
+	Version   string    `json:"version,omitempty"`
+	Publisher string    `json:"publisher"`
+	Published time.Time `json:"published"`
+	Commit    string    `json:"commit,omitempty"`
+	Files     int       `json:"files"`
+}
+
+// History lists the snapshots of a route, from the most recent to the oldest. Current is the ID of the
+// snapshot the route was last built from, by a publish or a rollback.
+type History struct {
+	Route     string     `json:"route"`
+	Current   string     `json:"current"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// NewSnapshot returns a snapshot of route with an ID that is unique within the history of the route,
+// and reserves the path of its tarball.
+func NewSnapshot(route string) (Snapshot, error) {
+	if err := os.MkdirAll(filepath.Join(ArchivePath(), route), 0755); err != nil {
+		return Snapshot{}, fmt.Errorf("udocs.NewSnapshot: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for id := now.Unix(); ; id++ {
+		snapshot := Snapshot{ID: strconv.FormatInt(id, 10), Published: now}
+		f, err := os.OpenFile(SnapshotPath(route, snapshot.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			continue
+		} else if err != nil {
+			return snapshot, fmt.Errorf("udocs.NewSnapshot: %v", err)
+		}
+		return snapshot, f.Close()
+	}
+}
+
+// SnapshotPath returns the path of the tarball a snapshot of route was published from.
+func SnapshotPath(route, id string) string {
+	return filepath.Join(ArchivePath(), route, id+".tar.gz")
+}
+
+func LoadHistory(route string) (History, error) {
+	history := History{Route: route, Snapshots: make([]Snapshot, 0)}
+
+	data, err := ioutil.ReadFile(getHistoryPath(route))
+	if os.IsNotExist(err) {
+		return history, nil
+	} else if err != nil {
+		return history, fmt.Errorf("udocs.LoadHistory: %v", err)
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return history, fmt.Errorf("udocs.LoadHistory: %v", err)
+	}
+
+	return history, nil
+}
+
+func (h History) Save() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(getHistoryPath(h.Route)), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(getHistoryPath(h.Route), data, 0644)
+}
+
+// Find returns the snapshot with the given id.
+func (h History) Find(id string) (Snapshot, bool) {
+	for _, snapshot := range h.Snapshots {
+		if snapshot.ID == id {
+			return snapshot, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// UpdateHistory loads the history of route, applies fn to it and saves it, while holding a lock that
+// keeps concurrent publications of the route from losing each other's updates.
+func UpdateHistory(route string, fn func(*History) error) error {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	history, err := LoadHistory(route)
+	if err != nil {
+		return err
+	}
+	if err := fn(&history); err != nil {
+		return err
+	}
+	return history.Save()
+}
+
+// Record adds snapshot to the history as the current snapshot. Only the most recent limit snapshots
+// are kept, and the tarballs of older snapshots are deleted.
+func (h *History) Record(snapshot Snapshot, limit int) {
+	if limit <= 0 {
+		limit = DEFAULT_HISTORY_SIZE
+	}
+
+	h.Snapshots = append([]Snapshot{snapshot}, h.Snapshots...)
+	h.Current = snapshot.ID
+	if len(h.Snapshots) > limit {
+		for _, old := range h.Snapshots[limit:] {
+			os.Remove(SnapshotPath(h.Route, old.ID))
+		}
+		h.Snapshots = h.Snapshots[:limit]
+	}
+}
+
+// CountFiles returns the number of regular files in dir, recursively.
+func CountFiles(dir string) int {
+	count := 0
+	filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && fi.Mode().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
+func getHistoryPath(route string) string {
+	return filepath.Join(ArchivePath(), route, HISTORY_JSON)
+}
@@ -0,0 +1,50 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestHistoryRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	defer os.Setenv("HOME", home)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		snapshot, err := NewSnapshot("test-route")
+		if err != nil {
+			t.Fatalf("Terminating test due to failed snapshot creation: %v", err)
+		}
+		if err := UpdateHistory("test-route", func(history *History) error {
+			history.Record(snapshot, 2)
+			return nil
+		}); err != nil {
+			t.Fatalf("Terminating test due to failed history update: %v", err)
+		}
+		ids = append(ids, snapshot.ID)
+	}
+
+	history, err := LoadHistory("test-route")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed history load: %v", err)
+	}
+	if len(history.Snapshots) != 2 || history.Snapshots[0].ID != ids[2] || history.Current != ids[2] {
+		t.Errorf(errFmt, ids, "the 2 most recent snapshots, the latest current", history)
+	}
+	if _, ok := history.Find(ids[0]); ok {
+		t.Errorf("expected snapshot %s to be pruned", ids[0])
+	}
+	if _, err := os.Stat(SnapshotPath("test-route", ids[0])); !os.IsNotExist(err) {
+		t.Errorf("expected the tarball of snapshot %s to be deleted", ids[0])
+	}
+	if _, err := os.Stat(SnapshotPath("test-route", ids[2])); err != nil {
+		t.Errorf("expected the tarball of snapshot %s to be kept: %v", ids[2], err)
+	}
+}
@@ -6,36 +6,52 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/shurcooL/github_flavored_markdown"
+	"github.com/seanawilliams/udocs/cli/storage"
+	"github.com/shurcooL/sanitized_anchor_name"
 	"golang.org/x/net/html"
 )
 
-func processMarkdown(route string, data []byte) ([]byte, error) {
-	dom, err := processDOM(filepath.Join("/", route), markdownToHTML(data))
+func processMarkdown(route string, data []byte, renderer Renderer, diagrams *Diagrams) ([]byte, error) {
+	raw, err := markdownToHTML(renderer, data)
+	if err != nil {
+		return nil, err
+	}
+	dom, err := processDOM(filepath.Join("/", route), raw, diagrams)
 	if err != nil {
 		return nil, err
 	}
 	return stripDOM(dom, `<html><head></head><body>`, `</body></html>`), nil
 }
 
-func markdownToHTML(data []byte) []byte {
-	return github_flavored_markdown.Markdown(data)
-}
-
-func processDOM(root string, htmlDoc []byte) ([]byte, error) {
+// processDOM rewrites the HTML rendered from a markdown page of the route at root. Diagrams are rendered
+// with diagrams, unless it is nil.
+func processDOM(root string, htmlDoc []byte, diagrams *Diagrams) ([]byte, error) {
 	dom, err := html.Parse(bytes.NewReader(htmlDoc))
 	if err != nil {
 		return nil, err
 	}
 
+	slugs := make(map[string]bool) // the heading anchors of the page so far
 	var process func(*html.Node) ([]byte, error)
 	process = func(node *html.Node) ([]byte, error) {
 		if node.Type == html.ElementNode {
 			switch node.Data {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				processHeadingElement(node, slugs)
 			case "a":
 				processAnchorElement(node, root)
+			case "blockquote":
+				processBlockquoteElement(node)
 			case "div":
-				processDivElement(node)
+				if hasClass(node, "math") {
+					processMathElement(node)
+				} else if diagrams == nil || !diagrams.render(node) {
+					processDivElement(node)
+				}
+			case "span":
+				if hasClass(node, "math") {
+					processMathElement(node)
+				}
 			case "img":
 				processImageElement(node, root)
 			case "code":
@@ -62,12 +78,93 @@ func processDOM(root string, htmlDoc []byte) ([]byte, error) {
 
 func processAnchorElement(node *html.Node, root string) {
 	for i, a := range node.Attr {
-		if a.Key == "href" && !strings.ContainsRune(a.Val, '#') && isMarkdownPage(a.Val) && !isRemoteURL(a.Val) {
-			node.Attr[i].Val = getHTMLPath(root, a.Val)
+		if a.Key != "href" || isRemoteURL(a.Val) {
+			continue
+		}
+		// links to markdown pages may point at a heading of the page, as in page.md#heading
+		page, fragment := a.Val, ""
+		if j := strings.IndexRune(page, '#'); j >= 0 {
+			page, fragment = page[:j], page[j:]
+		}
+		if isMarkdownPage(page) {
+			node.Attr[i].Val = getHTMLPath(root, page) + fragment
 		}
 	}
 }
 
+// processHeadingElement gives a heading an id that is unique within the page, derived from the text of
+// the heading as GitHub does, and a permalink to itself that shows on hover. The anchors emitted by the
+// GFM renderer are replaced, since repeated headings all get the same anchor from it.
+func processHeadingElement(node *html.Node, slugs map[string]bool) {
+	for c := node.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && c.Data == "a" && hasClass(c, "anchor") {
+			node.RemoveChild(c)
+		}
+		c = next
+	}
+
+	id := -1
+	for i, a := range node.Attr {
+		if a.Key == "id" {
+			id = i
+		}
+	}
+
+	var slug string
+	if id >= 0 {
+		// headings written in raw HTML keep their id
+		slug = node.Attr[id].Val
+	} else {
+		slug = uniqueSlug(sanitized_anchor_name.Create(nodeText(node)), slugs)
+		node.Attr = append(node.Attr, html.Attribute{Key: "id", Val: slug})
+	}
+	slugs[slug] = true
+
+	permalink := &html.Node{
+		Type: html.ElementNode,
+		Data: "a",
+		Attr: []html.Attribute{
+			html.Attribute{Key: "name", Val: slug},
+			html.Attribute{Key: "class", Val: "anchor"},
+			html.Attribute{Key: "href", Val: "#" + slug},
+			html.Attribute{Key: "aria-hidden", Val: "true"},
+		},
+	}
+	permalink.AppendChild(&html.Node{
+		Type: html.ElementNode,
+		Data: "span",
+		Attr: []html.Attribute{html.Attribute{Key: "class", Val: "octicon octicon-link"}},
+	})
+	node.InsertBefore(permalink, node.FirstChild)
+}
+
+// uniqueSlug returns slug, or slug suffixed with the lowest number that makes it unique among slugs,
+// as in "usage-1" for the second "Usage" heading of a page.
+func uniqueSlug(slug string, slugs map[string]bool) string {
+	if slug == "" {
+		slug = "section"
+	}
+	unique := slug
+	for i := 1; slugs[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", slug, i)
+	}
+	return unique
+}
+
+func hasClass(node *html.Node, class string) bool {
+	for _, a := range node.Attr {
+		if a.Key == "class" {
+			for _, c := range strings.Fields(a.Val) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func processImageElement(node *html.Node, root string) {
 	for i, img := range node.Attr {
 		if img.Key == "src" && !isRemoteURL(img.Val) {
@@ -91,23 +188,166 @@ func processTableElement(node *html.Node) {
 func processDivElement(node *html.Node) {
 	for _, d := range node.Attr {
 		if d.Key == "class" {
-			// add syntax highlighting for code blocks (uses prism.js)
+			// highlight fenced code blocks, which the GFM renderer wraps in a div of their language
 			if lang := strings.TrimPrefix(d.Val, "highlight highlight-"); lang != d.Val {
-				langClass := fmt.Sprintf("language-%s", strings.ToLower(lang))
-				if preElem := node.FirstChild; preElem != nil {
-					codeElem := &html.Node{
-						Type: html.ElementNode,
-						Data: "code",
-						Attr: []html.Attribute{html.Attribute{Key: "class", Val: langClass}},
-					}
-					if preElem.FirstChild != nil {
-						codeElem.AppendChild(&html.Node{Type: html.TextNode, Data: preElem.FirstChild.Data})
-					}
-					node.FirstChild.FirstChild = codeElem
+				highlightCodeBlock(node, parseCodeInfo(lang))
+				return
+			}
+		}
+	}
+}
+
+// extractText returns the text content of an HTML fragment, with its tags removed and whitespace collapsed.
+// Math is given by the TeX of its formulas.
+func extractText(htmlDoc []byte) string {
+	var buf bytes.Buffer
+	inMath, inAnnotation := false, false
+	tokenizer := html.NewTokenizer(bytes.NewReader(htmlDoc))
+	for {
+		switch tt := tokenizer.Next(); tt {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(buf.String()), " ")
+		case html.StartTagToken, html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			start := tt == html.StartTagToken
+			switch string(name) {
+			case "math":
+				inMath = start
+			case "annotation":
+				inAnnotation = start
+			}
+		case html.TextToken:
+			if !inMath || inAnnotation {
+				buf.Write(tokenizer.Text())
+				buf.WriteRune(' ')
+			}
+		}
+	}
+}
+
+// extractSections splits the text content of an HTML fragment into the sections introduced by its headings.
+// Content before the first heading is returned as a section without an ID or heading.
+func extractSections(htmlDoc []byte) ([]storage.Section, error) {
+	dom, err := html.Parse(bytes.NewReader(htmlDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []storage.Section{storage.Section{}}
+	var body bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch {
+		case node.Type == html.ElementNode && isHeadingElement(node):
+			sections[len(sections)-1].Body = strings.Join(strings.Fields(body.String()), " ")
+			body.Reset()
+			sections = append(sections, storage.Section{ID: headingID(node), Heading: nodeText(node)})
+			return
+		case node.Type == html.ElementNode && (node.Data == "script" || node.Data == "style"):
+			return
+		case node.Type == html.ElementNode && node.Data == "math":
+			body.WriteString(mathText(node) + " ")
+			return
+		case node.Type == html.TextNode:
+			body.WriteString(node.Data + " ")
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(dom)
+	sections[len(sections)-1].Body = strings.Join(strings.Fields(body.String()), " ")
+
+	if first := sections[0]; first.Body == "" {
+		sections = sections[1:]
+	}
+	return sections, nil
+}
+
+// TOCEntry is a heading listed in the table of contents of a page.
+type TOCEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Level int    `json:"level"`
+}
+
+// TOC_MIN_LEVEL and TOC_MAX_LEVEL bound the levels of the headings listed in the table of contents of a
+// page: the title (h1) is left out, and so are the finest headings.
+const (
+	TOC_MIN_LEVEL = 2
+	TOC_MAX_LEVEL = 4
+)
+
+// ExtractTOC returns the table of contents of a rendered page, made of its h2 to h4 headings in order.
+func ExtractTOC(htmlDoc []byte) ([]TOCEntry, error) {
+	dom, err := html.Parse(bytes.NewReader(htmlDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	toc := make([]TOCEntry, 0)
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && isHeadingElement(node) {
+			level := int(node.Data[1] - '0')
+			if id := headingID(node); id != "" && level >= TOC_MIN_LEVEL && level <= TOC_MAX_LEVEL {
+				toc = append(toc, TOCEntry{ID: id, Title: nodeText(node), Level: level})
+			}
+			return
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(dom)
+	return toc, nil
+}
+
+func isHeadingElement(node *html.Node) bool {
+	switch node.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
+
+// headingID returns the anchor of a heading, which is either its id attribute, or the name of the
+// anchor element nested in it by the GFM renderer.
+func headingID(node *html.Node) string {
+	for _, a := range node.Attr {
+		if a.Key == "id" {
+			return a.Val
+		}
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "a" {
+			for _, a := range c.Attr {
+				if a.Key == "name" {
+					return a.Val
 				}
 			}
 		}
 	}
+	return ""
+}
+
+func nodeText(node *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.ElementNode && n.Data == "math":
+			buf.WriteString(mathText(n) + " ")
+			return
+		case n.Type == html.TextNode:
+			buf.WriteString(n.Data + " ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(node)
+	return strings.Join(strings.Fields(buf.String()), " ")
 }
 
 func stripDOM(dom []byte, prefix, suffix string) []byte {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/html"
@@ -14,15 +15,25 @@ const errFmt = "Given: %v\n\t  Expected: %v\n\t  Got: %v"
 func TestMarkdownToHTML(t *testing.T) {
 	given := "# Hello, world!\nThis is only a test."
 	expected := `.*<h1>.*Hello, world!</h1>[[:space:]]*<p>This is only a test.</p>`
-	got := markdownToHTML([]byte(given))
 
-	match, err := regexp.Match(expected, got)
-	if err != nil {
-		t.Fatalf("Terminating test due to regexp failure: %v", err)
-	}
+	for _, engine := range []string{ENGINE_GOLDMARK, ENGINE_BLACKFRIDAY} {
+		renderer, err := NewRenderer(MarkdownOptions{Engine: engine})
+		if err != nil {
+			t.Fatalf("Terminating test due to failed renderer creation: %v", err)
+		}
+		got, err := markdownToHTML(renderer, []byte(given))
+		if err != nil {
+			t.Fatalf("Terminating test due to failed markdown rendering: %v", err)
+		}
 
-	if !match {
-		t.Errorf(errFmt, given, `.*<h1>.*Hello, world!</h1>[[:space:]]*<p>This is only a test.</p>`, string(got))
+		match, err := regexp.Match(expected, got)
+		if err != nil {
+			t.Fatalf("Terminating test due to regexp failure: %v", err)
+		}
+
+		if !match {
+			t.Errorf(errFmt, given, `.*<h1>.*Hello, world!</h1>[[:space:]]*<p>This is only a test.</p>`, string(got))
+		}
 	}
 }
 
@@ -129,17 +140,73 @@ func TestProcessCodeElement(t *testing.T) {
 }
 
 func TestProcessDivElement(t *testing.T) {
-	given := `<div class="highlight highlight-default"><pre>{if else then}</pre><div>`
-	expected := `<div class="highlight highlight-default"><pre><code class="language-default">{if else then}</code></pre><div></div></div>`
+	copyButton := `<button class="code-copy" type="button" title="Copy to clipboard" aria-label="Copy to clipboard"><i class="fa fa-clipboard"></i></button>`
+	testCases := []struct {
+		given    string
+		expected string
+	}{
+		{
+			given:    `<div class="highlight highlight-default"><pre>{if else then}</pre></div>`,
+			expected: `<div class="code-block">` + copyButton + `<pre class="highlight"><code class="language-default"><span class="line"><span class="pun">{</span><span class="kwd">if</span> <span class="kwd">else</span> <span class="kwd">then</span><span class="pun">}</span></span>` + "\n" + `</code></pre></div>`,
+		},
+		{
+			given:    `<div class="highlight highlight-text--hl-2--title-6e6f7465732e747874--linenos"><pre>one` + "\n" + `two` + "\n" + `</pre></div>`,
+			expected: `<div class="code-block"><div class="code-filename">notes.txt</div>` + copyButton + `<pre class="highlight line-numbers"><code class="language-text"><span class="line"><span class="ln">1</span>one</span>` + "\n" + `<span class="line hl"><span class="ln">2</span>two</span>` + "\n" + `</code></pre></div>`,
+		},
+		{
+			given:    `<div class="highlight highlight-diff"><pre>-old` + "\n" + `+new</pre></div>`,
+			expected: `<div class="code-block">` + copyButton + `<pre class="highlight"><code class="language-diff"><span class="line"><span class="gd">-old</span></span>` + "\n" + `<span class="line"><span class="gi">+new</span></span>` + "\n" + `</code></pre></div>`,
+		},
+	}
 
-	node, err := html.Parse(bytes.NewReader([]byte(given)))
+	for _, tc := range testCases {
+		node, err := html.Parse(bytes.NewReader([]byte(tc.given)))
+		if err != nil {
+			t.Fatalf("Terminating test due to failed HTML parse: %v", err)
+		}
+
+		processDivElement(extractNode(t, node))
+		if got := renderNode(t, node); tc.expected != got {
+			t.Errorf(errFmt, tc.given, tc.expected, got)
+		}
+	}
+}
+
+func TestPreprocessFences(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected string
+	}{
+		{"```go\nx := 1\n```\n", "```go\nx := 1\n```\n"},
+		{"```go {3-5,8}\n```\n", "```go--hl-3-5_8\n```\n"},
+		{"~~~ {2} linenos\n~~~\n", "~~~text--hl-2--linenos\n~~~\n"},
+		{"```js title=\"a b.js\"\n```\n", "```js--title-6120622e6a73\n```\n"},
+		{"```yaml filename=c.yml\n```\n", "```yaml--title-632e796d6c\n```\n"},
+		{"````\n```go {1}\n````\n", "````\n```go {1}\n````\n"},
+	}
+
+	for _, tc := range testCases {
+		if got := string(preprocessFences([]byte(tc.given))); tc.expected != got {
+			t.Errorf(errFmt, tc.given, tc.expected, got)
+		}
+	}
+}
+
+func TestHighlightMarkdown(t *testing.T) {
+	given := "```go {2} title=main.go\npackage main\n// comment\n```\n"
+	processed, err := processDOM("/test", renderMarkdown(t, given), nil)
 	if err != nil {
-		t.Fatalf("Terminating test due to failed HTML parse: %v", err)
+		t.Fatalf("Terminating test due to failed DOM processing: %v", err)
 	}
 
-	processDivElement(extractNode(t, node))
-	if got := renderNode(t, node); expected != got {
-		t.Errorf(errFmt, given, expected, got)
+	for _, expected := range []string{
+		`<div class="code-filename">main.go</div>`,
+		`<code class="language-go"><span class="line"><span class="kwd">package</span> <span class="pln">main</span></span>`,
+		`<span class="line hl"><span class="com">// comment</span></span>`,
+	} {
+		if !strings.Contains(string(processed), expected) {
+			t.Errorf(errFmt, given, expected, string(processed))
+		}
 	}
 }
 
@@ -194,3 +261,58 @@ func TestGetHTMLPath(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractSections(t *testing.T) {
+	given := `<p>Intro text.</p><h2><a name="first" class="anchor" href="#first"></a>First <em>Part</em></h2><p>One.</p><h3 id="second">Second</h3><p>Two.</p>`
+	expected := []struct{ id, heading, body string }{
+		{id: "", heading: "", body: "Intro text."},
+		{id: "first", heading: "First Part", body: "One."},
+		{id: "second", heading: "Second", body: "Two."},
+	}
+
+	got, err := extractSections([]byte(given))
+	if err != nil {
+		t.Fatalf("Terminating test due to failed section extraction: %v", err)
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf(errFmt, given, len(expected), len(got))
+	}
+	for i, section := range got {
+		if section.ID != expected[i].id || section.Heading != expected[i].heading || section.Body != expected[i].body {
+			t.Errorf(errFmt, given, expected[i], section)
+		}
+	}
+}
+
+func TestProcessHeadingElement(t *testing.T) {
+	given := "# Guide\n\n## Usage\n\n### Options\n\n## Usage\n\n#### Usage\n\n##### Details\n"
+	dom, err := processDOM("/test", renderMarkdown(t, given), nil)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed DOM processing: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<h2 id="usage"><a name="usage" class="anchor" href="#usage" aria-hidden="true">`,
+		`<h2 id="usage-1"><a name="usage-1" class="anchor" href="#usage-1" aria-hidden="true">`,
+		`<h4 id="usage-2">`,
+	} {
+		if !bytes.Contains(dom, []byte(expected)) {
+			t.Errorf(errFmt, given, expected, string(dom))
+		}
+	}
+
+	toc, err := ExtractTOC(dom)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed TOC extraction: %v", err)
+	}
+	expected := []TOCEntry{
+		{ID: "usage", Title: "Usage", Level: 2},
+		{ID: "options", Title: "Options", Level: 3},
+		{ID: "usage-1", Title: "Usage", Level: 2},
+		{ID: "usage-2", Title: "Usage", Level: 4},
+	}
+	if fmt.Sprint(toc) != fmt.Sprint(expected) {
+		t.Errorf(errFmt, given, expected, toc)
+	}
+}
@@ -0,0 +1,316 @@
+package udocs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shurcooL/sanitized_anchor_name"
+)
+
+// includeRegex matches an include directive on a line of its own, as in
+//
+//	{{< include "../examples/main.go" lines="10-30" >}}
+//
+// and captures the path of the included file and its attributes. Directives indented by four spaces or
+// more are in indented code blocks, and are left alone.
+var includeRegex = regexp.MustCompile(`^ {0,3}\{\{<\s*include\s+"([^"]+)"((?:\s+[a-z]+="[^"]*")*)\s*>\}\}\s*$`)
+
+var (
+	includeAttrRegex = regexp.MustCompile(`([a-z]+)="([^"]*)"`)
+	headingRegex     = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	regionRegex      = regexp.MustCompile(`#(end)?region\b\s*(\S*)`)
+)
+
+// includeLanguages maps the extensions of included source files to the languages of their code blocks,
+// where the two differ.
+var includeLanguages = map[string]string{
+	"js":  "javascript",
+	"ts":  "typescript",
+	"py":  "python",
+	"rb":  "ruby",
+	"rs":  "rust",
+	"sh":  "bash",
+	"yml": "yaml",
+}
+
+// IncludeError is an include directive that could not be expanded. File is relative to the docs directory,
+// Line is the line of File the directive is on, and Target is the path it includes.
+type IncludeError struct {
+	File    string
+	Line    int
+	Target  string
+	Message string
+}
+
+func (e *IncludeError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// ExpandIncludes replaces the include directives of the markdown page at path, in the docs directory root,
+// with the contents of the files they include. It returns the first directive that could not be expanded
+// as an *IncludeError.
+//
+// A markdown file is included without its front matter, and with its own directives expanded. Its section
+// attribute selects the section under the heading with the given text or anchor. Other files are included
+// as a fenced code block, whose language is given by the lang attribute or the extension of the file. The
+// lines attribute selects a range of lines, as in lines="10-30", and the region attribute the lines
+// between "#region name" and "#endregion" comments. Paths are relative to the including file, or to root
+// when they start with a slash.
+func ExpandIncludes(root, path string, data []byte) ([]byte, error) {
+	expanded, errs := expandIncludes(root, path, data)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return expanded, nil
+}
+
+// expandIncludes is like ExpandIncludes, but returns every directive that could not be expanded. Those are
+// left out of the page.
+func expandIncludes(root, path string, data []byte) ([]byte, []*IncludeError) {
+	e := &includeExpander{root: root}
+	return e.expand(path, data, nil), e.errs
+}
+
+type includeExpander struct {
+	root string
+	errs []*IncludeError
+}
+
+func (e *includeExpander) expand(path string, data []byte, stack []string) []byte {
+	stack = append(stack, path)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var buf bytes.Buffer
+	fence := ""
+	for i, line := range lines {
+		text := strings.TrimRight(string(line), "\r\n")
+
+		// directives in code blocks are examples of directives
+		if fence != "" {
+			if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			buf.Write(line)
+			continue
+		} else if matches := fenceRegex.FindStringSubmatch(text); matches != nil {
+			fence = matches[1]
+			buf.Write(line)
+			continue
+		}
+
+		matches := includeRegex.FindStringSubmatch(text)
+		if matches == nil {
+			buf.Write(line)
+			continue
+		}
+
+		included, err := e.include(path, matches[1], matches[2], stack)
+		if err != nil {
+			e.errs = append(e.errs, &IncludeError{File: e.rel(path), Line: i + 1, Target: matches[1], Message: err.Error()})
+			continue
+		}
+		buf.Write(included)
+		if len(included) > 0 && included[len(included)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// insideRoot reports whether file is under root once their symlinks are resolved, so that a link in the
+// docs directory cannot include files from elsewhere. A file that does not exist is checked by its
+// directory.
+func insideRoot(root, file string) bool {
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	if real, err := filepath.EvalSymlinks(file); err == nil {
+		file = real
+	} else if dir, err := filepath.EvalSymlinks(filepath.Dir(file)); err == nil {
+		file = filepath.Join(dir, filepath.Base(file))
+	}
+	rel, err := filepath.Rel(root, file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// include returns the contents the directive of the file at path includes from target.
+func (e *includeExpander) include(path, target, attrs string, stack []string) ([]byte, error) {
+	var file string
+	if strings.HasPrefix(target, "/") {
+		file = filepath.Join(e.root, target)
+	} else {
+		file = filepath.Join(filepath.Dir(path), target)
+	}
+	if !insideRoot(e.root, file) {
+		return nil, fmt.Errorf("%s is outside of the docs directory", target)
+	}
+	for _, p := range stack {
+		if p == file {
+			cycle := make([]string, 0, len(stack)+1)
+			for _, s := range append(stack, file) {
+				cycle = append(cycle, e.rel(s))
+			}
+			return nil, fmt.Errorf("include cycle %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s does not exist", target)
+	}
+
+	options := make(map[string]string)
+	for _, attr := range includeAttrRegex.FindAllStringSubmatch(attrs, -1) {
+		options[attr[1]] = attr[2]
+	}
+
+	markdown := isMarkdownPage(file)
+	if markdown {
+		if _, data, err = ParseFrontMatter(data); err != nil {
+			return nil, err
+		}
+	}
+	if region, ok := options["region"]; ok {
+		if data, err = selectRegion(data, region); err != nil {
+			return nil, err
+		}
+	}
+	if lines, ok := options["lines"]; ok {
+		if data, err = selectLines(data, lines); err != nil {
+			return nil, err
+		}
+	}
+
+	if !markdown {
+		return fenceCode(data, options["lang"], file), nil
+	}
+	if section, ok := options["section"]; ok {
+		if data, err = selectSection(data, section); err != nil {
+			return nil, err
+		}
+	}
+	return e.expand(file, data, stack), nil
+}
+
+func (e *includeExpander) rel(path string) string {
+	if rel, err := filepath.Rel(e.root, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// selectLines returns a range of lines of data, as in "10-30", "10-" or "10".
+func selectLines(data []byte, lines string) ([]byte, error) {
+	all := bytes.SplitAfter(data, []byte("\n"))
+	if len(all[len(all)-1]) == 0 {
+		all = all[:len(all)-1]
+	}
+
+	bounds := strings.SplitN(lines, "-", 2)
+	start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	end := start
+	if err == nil && len(bounds) == 2 {
+		if end = len(all); strings.TrimSpace(bounds[1]) != "" {
+			end, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		}
+	}
+	if err != nil || start < 1 || end < start {
+		return nil, fmt.Errorf("invalid line range %q", lines)
+	}
+	if end > len(all) {
+		return nil, fmt.Errorf("line range %q is past the end of the file, which has %d lines", lines, len(all))
+	}
+	return bytes.Join(all[start-1:end], nil), nil
+}
+
+// selectRegion returns the lines of data between the "#region name" and "#endregion" comments, without
+// the region comments themselves.
+func selectRegion(data []byte, region string) ([]byte, error) {
+	var buf bytes.Buffer
+	found, inside, depth := false, false, 0
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		matches := regionRegex.FindSubmatch(line)
+		switch {
+		case matches == nil:
+			if inside {
+				buf.Write(line)
+			}
+		case len(matches[1]) == 0: // #region
+			if inside {
+				depth++
+			} else if string(matches[2]) == region {
+				found, inside = true, true
+			}
+		default: // #endregion
+			if inside && depth == 0 && (len(matches[2]) == 0 || string(matches[2]) == region) {
+				inside = false
+			} else if inside {
+				depth--
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("region %q does not exist", region)
+	}
+	return buf.Bytes(), nil
+}
+
+// selectSection returns the section of a markdown page under the heading whose text or anchor is section,
+// heading included, up to the next heading of the same or a higher level.
+func selectSection(data []byte, section string) ([]byte, error) {
+	var buf bytes.Buffer
+	level, fence := 0, ""
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		text := strings.TrimRight(string(line), "\r\n")
+		if fence != "" {
+			if trimmed := strings.TrimSpace(text); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+		} else if matches := fenceRegex.FindStringSubmatch(text); matches != nil {
+			fence = matches[1]
+		} else if matches := headingRegex.FindStringSubmatch(text); matches != nil {
+			if level > 0 && len(matches[1]) <= level {
+				break
+			}
+			if level == 0 && (strings.EqualFold(matches[2], section) || sanitized_anchor_name.Create(matches[2]) == section) {
+				level = len(matches[1])
+			}
+		}
+		if level > 0 {
+			buf.Write(line)
+		}
+	}
+	if level == 0 {
+		return nil, fmt.Errorf("section %q does not exist", section)
+	}
+	return buf.Bytes(), nil
+}
+
+// fenceCode wraps the source of file in a fenced code block, with a fence longer than any run of backticks
+// in the source.
+func fenceCode(data []byte, lang, file string) []byte {
+	if lang == "" {
+		lang = strings.TrimPrefix(filepath.Ext(file), ".")
+		if l, ok := includeLanguages[lang]; ok {
+			lang = l
+		}
+	}
+
+	fence := "```"
+	for bytes.Contains(data, []byte(fence)) {
+		fence += "`"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fence + lang + "\n")
+	buf.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(fence + "\n")
+	return buf.Bytes()
+}
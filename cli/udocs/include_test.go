@@ -0,0 +1,153 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"examples/main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\t// #region greet\n\tfmt.Println(\"hello\")\n\t// #endregion\n}\n",
+		"snippets/setup.md":  "---\ntitle: Setup\n---\n# Setup\n\n## Install\n\nRun make.\n\n### Details\n\nMore.\n\n## Configure\n\nEdit udocs.env.\n",
+		"snippets/nested.md": "Before.\n\n{{< include \"../examples/main.go\" lines=\"5\" >}}\n",
+		"cycle/a.md":         "{{< include \"b.md\" >}}\n",
+		"cycle/b.md":         "{{< include \"a.md\" >}}\n",
+		"..notes.md":         "Notes.\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Terminating test due to failed dir creation: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	// a symlink in the docs directory does not let it include files from elsewhere
+	outside, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(outside)
+	if err := ioutil.WriteFile(filepath.Join(outside, "secret.md"), []byte("Secret.\n"), 0644); err != nil {
+		t.Fatalf("Terminating test due to failed file write: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.md"), filepath.Join(dir, "link.md")); err != nil {
+		t.Fatalf("Terminating test due to failed symlink creation: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linked")); err != nil {
+		t.Fatalf("Terminating test due to failed symlink creation: %v", err)
+	}
+
+	testCases := []struct {
+		given    string
+		expected string
+		err      string
+	}{
+		{
+			given:    "# Page\n{{< include \"examples/main.go\" lines=\"3-5\" >}}\nAfter.\n",
+			expected: "# Page\n```go\nimport \"fmt\"\n\nfunc main() {\n```\nAfter.\n",
+		},
+		{
+			given:    "{{< include \"/examples/main.go\" region=\"greet\" lang=\"golang\" >}}\n",
+			expected: "```golang\n\tfmt.Println(\"hello\")\n```\n",
+		},
+		{
+			given:    "{{< include \"snippets/setup.md\" section=\"Install\" >}}\n",
+			expected: "## Install\n\nRun make.\n\n### Details\n\nMore.\n\n",
+		},
+		{
+			given:    "{{< include \"snippets/setup.md\" section=\"configure\" >}}\n",
+			expected: "## Configure\n\nEdit udocs.env.\n",
+		},
+		{
+			given:    "{{< include \"snippets/nested.md\" >}}\n",
+			expected: "Before.\n\n```go\nfunc main() {\n```\n",
+		},
+		{
+			given:    "```\n{{< include \"missing.md\" >}}\n```\n",
+			expected: "```\n{{< include \"missing.md\" >}}\n```\n",
+		},
+		{
+			given:    "Example:\n\n    {{< include \"missing.md\" >}}\n",
+			expected: "Example:\n\n    {{< include \"missing.md\" >}}\n",
+		},
+		{
+			given: "Intro.\n{{< include \"missing.md\" >}}\n",
+			err:   "README.md:2: missing.md does not exist",
+		},
+		{
+			given: "{{< include \"snippets/setup.md\" section=\"Uninstall\" >}}\n",
+			err:   `README.md:1: section "Uninstall" does not exist`,
+		},
+		{
+			given: "{{< include \"examples/main.go\" lines=\"8-20\" >}}\n",
+			err:   `README.md:1: line range "8-20" is past the end of the file, which has 9 lines`,
+		},
+		{
+			given: "{{< include \"../outside.md\" >}}\n",
+			err:   "README.md:1: ../outside.md is outside of the docs directory",
+		},
+		{
+			given: "{{< include \"link.md\" >}}\n",
+			err:   "README.md:1: link.md is outside of the docs directory",
+		},
+		{
+			given: "{{< include \"linked/secret.md\" >}}\n",
+			err:   "README.md:1: linked/secret.md is outside of the docs directory",
+		},
+		{
+			given:    "{{< include \"..notes.md\" >}}\n",
+			expected: "Notes.\n",
+		},
+		{
+			given: "{{< include \"cycle/a.md\" >}}\n",
+			err:   "cycle/b.md:1: include cycle README.md -> cycle/a.md -> cycle/b.md -> cycle/a.md",
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := ExpandIncludes(dir, filepath.Join(dir, README_MD), []byte(tc.given))
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf(errFmt, tc.given, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf(errFmt, tc.given, tc.expected, err)
+		} else if string(got) != tc.expected {
+			t.Errorf(errFmt, tc.given, tc.expected, string(got))
+		}
+	}
+}
+
+func TestCheckLinksIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	given := "# Page\n\n{{< include \"examples/gone.go\" >}}\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, README_MD), []byte(given), 0644); err != nil {
+		t.Fatalf("Terminating test due to failed file write: %v", err)
+	}
+
+	problems, err := CheckLinks(dir, LinkCheckOptions{})
+	if err != nil {
+		t.Fatalf("CheckLinks(%s) => %v", dir, err)
+	}
+	if len(problems) != 1 || problems[0].Line != 3 || problems[0].Link != "examples/gone.go" || !strings.Contains(problems[0].Message, "does not exist") {
+		t.Errorf(errFmt, given, "examples/gone.go does not exist", problems)
+	}
+}
@@ -0,0 +1,368 @@
+package udocs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// linkCheckRoute is the route a docs directory is checked as if it were built into. Links are resolved
+// exactly as they are in a build, so the name of the route itself does not matter.
+const linkCheckRoute = "linkcheck"
+
+// LinkProblem is a broken link or image source in a docs directory. File is relative to the docs
+// directory, and Line is the line of File the link appears on (or 0 if it could not be located).
+type LinkProblem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Link    string `json:"link"`
+	Message string `json:"message"`
+}
+
+func (p LinkProblem) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, p.Link, p.Message)
+}
+
+// LinkCheckOptions configures CheckLinks.
+type LinkCheckOptions struct {
+	// Remote enables checking that remote (http and https) URLs respond without an error.
+	Remote bool
+	// Client makes the requests to remote URLs. When nil, a client with a 10 second timeout is used.
+	Client *http.Client
+}
+
+type checkedPage struct {
+	rel      string
+	source   []string
+	links    []pageLink
+	anchors  map[string]bool
+	diagrams []DiagramFailure
+}
+
+// pageLink is the link of an a or img element, both as written in the markdown source, and as rewritten
+// by processDOM.
+type pageLink struct {
+	original, processed string
+}
+
+// CheckLinks renders every markdown page of the docs directory dir through the same pipeline as Build,
+// and resolves every link, image source and #anchor of the pages, as well as the entries of SUMMARY.md,
+// against the pages and files of the directory. Include directives whose targets are missing, and diagrams
+// that fail to render, are reported as well. It returns every problem found, sorted by file and line.
+func CheckLinks(dir string, opts LinkCheckOptions) ([]LinkProblem, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	markdownOpts, err := LoadMarkdownOptions(abs)
+	if err != nil {
+		return nil, err
+	}
+	renderer, err := NewRenderer(markdownOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	pages := make(map[string]*checkedPage)
+	includes := make(map[IncludeError]bool) // directives of files included by several pages are reported once
+	var summary []byte
+	if err := filepath.Walk(abs, func(path string, fi os.FileInfo, err error) error {
+		if fi == nil || !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel := path[len(abs):]
+
+		if !isMarkdownPage(path) {
+			ids[filepath.Join("/", linkCheckRoute, rel)] = true
+			return nil
+		}
+
+		id := getHTMLPath("/", linkCheckRoute, rel)
+		ids[id] = true
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if IsSummaryFile(path) && filepath.Dir(rel) == "/" {
+			summary = data
+			return nil
+		}
+
+		expanded, errs := expandIncludes(abs, path, data)
+		for _, err := range errs {
+			includes[*err] = true
+		}
+
+		page, err := checkPage(strings.TrimPrefix(rel, "/"), data, expanded, renderer)
+		if err != nil {
+			return fmt.Errorf("udocs.CheckLinks failed to render %s: %v", rel, err)
+		}
+		pages[id] = page
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	checker := &linkChecker{ids: ids, pages: pages, opts: opts, remote: make(map[string]string)}
+	if checker.opts.Client == nil {
+		checker.opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	problems := make([]LinkProblem, 0)
+	if summary != nil {
+		problems = append(problems, checker.checkSummary(summary)...)
+	}
+	for id, page := range pages {
+		problems = append(problems, checker.checkPage(id, page)...)
+	}
+	for err := range includes {
+		problems = append(problems, LinkProblem{File: err.File, Line: err.Line, Link: err.Target, Message: err.Message})
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		return problems[i].Line < problems[j].Line
+	})
+	return problems, nil
+}
+
+// checkPage renders a markdown page, with its includes expanded, and collects its links, the anchors it
+// defines and the diagrams that failed to render.
+func checkPage(rel string, data, expanded []byte, renderer Renderer) (*checkedPage, error) {
+	raw, err := markdownToHTML(renderer, expanded)
+	if err != nil {
+		return nil, err
+	}
+	diagrams := NewDiagrams(nil)
+	processed, err := processDOM(filepath.Join("/", linkCheckRoute), raw, diagrams)
+	if err != nil {
+		return nil, err
+	}
+
+	originals, _, err := collectLinks(raw)
+	if err != nil {
+		return nil, err
+	}
+	rewritten, anchors, err := collectLinks(processed)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &checkedPage{rel: rel, source: strings.Split(string(data), "\n"), anchors: anchors, diagrams: diagrams.Failures}
+	for i, link := range rewritten {
+		original := link
+		if len(originals) == len(rewritten) {
+			original = originals[i]
+		}
+		page.links = append(page.links, pageLink{original: original, processed: link})
+	}
+	return page, nil
+}
+
+// collectLinks returns the links of the a and img elements of an HTML document, in document order,
+// along with the anchors defined by the id attributes of its elements and the names of its a elements.
+// The permalinks of headings are left out, since only some renderers emit them before processDOM does.
+func collectLinks(htmlDoc []byte) ([]string, map[string]bool, error) {
+	dom, err := html.Parse(bytes.NewReader(htmlDoc))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var links []string
+	anchors := make(map[string]bool)
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && !(node.Data == "a" && hasClass(node, "anchor")) {
+			for _, a := range node.Attr {
+				switch {
+				case a.Key == "id", a.Key == "name" && node.Data == "a":
+					anchors[a.Val] = true
+				case a.Key == "href" && node.Data == "a", a.Key == "src" && node.Data == "img":
+					links = append(links, a.Val)
+				}
+			}
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(dom)
+	return links, anchors, nil
+}
+
+type linkChecker struct {
+	ids    map[string]bool
+	pages  map[string]*checkedPage
+	opts   LinkCheckOptions
+	remote map[string]string // the problem with each remote URL checked so far, if any
+}
+
+func (c *linkChecker) checkSummary(data []byte) []LinkProblem {
+	var problems []LinkProblem
+
+	parsed, err := ParseSummary(linkCheckRoute, data)
+	if serr, ok := err.(*SummaryError); ok {
+		return []LinkProblem{LinkProblem{File: SUMMARY_MD, Line: serr.Line, Message: serr.Message}}
+	} else if err != nil {
+		return []LinkProblem{LinkProblem{File: SUMMARY_MD, Line: 1, Message: err.Error()}}
+	}
+
+	lines := strings.Split(string(data), "\n")
+	cursor := 0
+	var walk func(pages []Page)
+	walk = func(pages []Page) {
+		for _, page := range pages {
+			line := locate(lines, page.Title, &cursor)
+			if page.Path != "" && !IsQuipThread(page.Path) && !c.ids[page.Path] {
+				problems = append(problems, LinkProblem{
+					File:    SUMMARY_MD,
+					Line:    line,
+					Link:    page.Title,
+					Message: fmt.Sprintf("entry points at %s, which does not exist", getMarkdownPath(c.rel(page.Path))),
+				})
+			}
+			walk(page.SubPages)
+		}
+	}
+	walk(parsed.Pages)
+	return problems
+}
+
+func (c *linkChecker) checkPage(id string, page *checkedPage) []LinkProblem {
+	var problems []LinkProblem
+
+	cursor := 0
+	for _, link := range page.links {
+		line := locate(page.source, link.original, &cursor)
+		if message := c.checkLink(id, link.processed); message != "" {
+			problems = append(problems, LinkProblem{File: page.rel, Line: line, Link: link.original, Message: message})
+		}
+	}
+
+	cursor = 0
+	for _, failure := range page.diagrams {
+		// the fence of a diagram is the line before the first line of its source
+		line := locate(page.source, strings.SplitN(failure.Source, "\n", 2)[0], &cursor)
+		if line > 1 {
+			line--
+		}
+		problems = append(problems, LinkProblem{
+			File:    page.rel,
+			Line:    line,
+			Link:    failure.Lang + " diagram",
+			Message: fmt.Sprintf("failed to render: %v", failure.Err),
+		})
+	}
+	return problems
+}
+
+// checkLink resolves a link of the page with the given id, and describes the problem with it, if any.
+func (c *linkChecker) checkLink(id, link string) string {
+	switch {
+	case link == "":
+		return "link is empty"
+	case isRemoteURL(link):
+		if !c.opts.Remote {
+			return ""
+		}
+		return c.checkRemote(link)
+	case strings.HasPrefix(link, "//") || hasScheme(link):
+		return "" // mailto: and the like
+	}
+
+	target, fragment := link, ""
+	if i := strings.IndexRune(target, '#'); i >= 0 {
+		target, fragment = target[:i], target[i+1:]
+	}
+	if i := strings.IndexRune(target, '?'); i >= 0 {
+		target = target[:i]
+	}
+
+	switch {
+	case target == "":
+		target = id
+	case !strings.HasPrefix(target, "/"):
+		target = path.Join(path.Dir(id), target)
+	}
+	if !c.ids[target] {
+		if index := path.Join(target, INDEX_HTML); c.ids[index] {
+			target = index
+		} else {
+			return fmt.Sprintf("%s does not exist", c.rel(target))
+		}
+	}
+
+	if fragment == "" {
+		return ""
+	}
+	if page, ok := c.pages[target]; ok && !page.anchors[fragment] {
+		return fmt.Sprintf("%s has no heading or anchor #%s", getMarkdownPath(c.rel(target)), fragment)
+	}
+	return ""
+}
+
+func (c *linkChecker) checkRemote(url string) string {
+	if message, ok := c.remote[url]; ok {
+		return message
+	}
+
+	message := ""
+	resp, err := c.opts.Client.Head(url)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = c.opts.Client.Get(url) // some servers do not implement HEAD
+	}
+	if err != nil {
+		message = fmt.Sprintf("request failed: %v", err)
+	} else {
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			message = fmt.Sprintf("responded with HTTP %s", resp.Status)
+		}
+	}
+
+	c.remote[url] = message
+	return message
+}
+
+// rel returns the path of an id relative to the docs directory.
+func (c *linkChecker) rel(id string) string {
+	return strings.TrimPrefix(id, "/"+linkCheckRoute+"/")
+}
+
+// locate returns the number of the first line at or after the cursor that contains s, and advances the
+// cursor to it. Links appear in the source in the same order as in the rendered page, except for reference
+// links, so the search falls back to the whole source. It returns 0 if s is not found.
+func locate(lines []string, s string, cursor *int) int {
+	for i := *cursor; i < len(lines); i++ {
+		if strings.Contains(lines[i], s) {
+			*cursor = i
+			return i + 1
+		}
+	}
+	for i := 0; i < *cursor && i < len(lines); i++ {
+		if strings.Contains(lines[i], s) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func hasScheme(link string) bool {
+	i := strings.IndexRune(link, ':')
+	return i > 0 && !strings.ContainsAny(link[:i], "/?#")
+}
@@ -0,0 +1,80 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	files := map[string]string{
+		README_MD:          "# Test\n\n## Getting Started\n\nSee [the page](page.md#usage) and [the top](#getting-started).\n",
+		SUMMARY_MD:         "# Test\n* [Overview](README.md)\n* [Page](page.md)\n* [Missing](missing.md)\n",
+		"page.md":          "# Page\n\n## Usage\n\n![logo](images/logo.png)\n\n[gone](gone.md)\n\n[bad anchor](README.md#nowhere)\n\n[up](" + server.URL + "/ok) [down](" + server.URL + "/down)\n\n[mail](mailto:docs@example.com)\n",
+		"images/logo.png":  "png",
+		"guide/index.html": "<html></html>",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Terminating test due to failed dir creation: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		opts     LinkCheckOptions
+		expected []LinkProblem
+	}{
+		{
+			opts: LinkCheckOptions{},
+			expected: []LinkProblem{
+				{File: SUMMARY_MD, Line: 4, Link: "Missing"},
+				{File: "page.md", Line: 7, Link: "gone.md"},
+				{File: "page.md", Line: 9, Link: "README.md#nowhere"},
+			},
+		},
+		{
+			opts: LinkCheckOptions{Remote: true},
+			expected: []LinkProblem{
+				{File: SUMMARY_MD, Line: 4, Link: "Missing"},
+				{File: "page.md", Line: 7, Link: "gone.md"},
+				{File: "page.md", Line: 9, Link: "README.md#nowhere"},
+				{File: "page.md", Line: 11, Link: server.URL + "/down"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		problems, err := CheckLinks(dir, tc.opts)
+		if err != nil {
+			t.Fatalf("CheckLinks(%s, %+v) => %v", dir, tc.opts, err)
+		}
+		if len(problems) != len(tc.expected) {
+			t.Fatalf(errFmt, "CheckLinks", tc.expected, problems)
+		}
+		for i, problem := range problems {
+			expected := tc.expected[i]
+			if problem.File != expected.File || problem.Line != expected.Line || problem.Link != expected.Link || problem.Message == "" {
+				t.Errorf(errFmt, "CheckLinks", expected, problem)
+			}
+		}
+	}
+}
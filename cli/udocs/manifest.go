@@ -0,0 +1,110 @@
+package udocs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+// Manifest maps the source files of a route (relative to its docs directory) to the
+// Dao entries they were built into, so that a rebuild can skip unchanged files and
+// purge entries whose source file no longer exists.
+type Manifest map[string]ManifestEntry
+
+// ManifestEntry is the Dao entry a source file was built into, along with the front matter of the
+// file, if it is a markdown page that has any.
+type ManifestEntry struct {
+	ID   string    `json:"id"`
+	Hash string    `json:"hash"`
+	Meta *PageMeta `json:"meta,omitempty"`
+}
+
+// BuildReport lists the Dao entries that were added, updated and removed by a build.
+type BuildReport struct {
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+}
+
+func LoadManifest(route string, dao storage.Dao) (Manifest, error) {
+	manifest := make(Manifest)
+
+	data, err := dao.Fetch(getManifestID(route))
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, err
+	}
+
+	return manifest, nil
+}
+
+func (m Manifest) Save(route string, dao storage.Store) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return dao.Insert(getManifestID(route), data)
+}
+
+// Unchanged reports whether the given source file was previously built into id from identical content.
+func (m Manifest) Unchanged(path, id, hash string) bool {
+	entry, ok := m[path]
+	return ok && entry.ID == id && entry.Hash == hash
+}
+
+// Metadata returns the metadata of the pages built from source files that have front matter.
+func (m Manifest) Metadata() Metadata {
+	metadata := make(Metadata)
+	for _, entry := range m {
+		if entry.Meta != nil {
+			metadata[entry.ID] = entry.Meta
+		}
+	}
+	return metadata
+}
+
+func (r *BuildReport) record(old Manifest, path, id string) {
+	if _, ok := old[path]; ok {
+		r.Updated = append(r.Updated, id)
+	} else {
+		r.Added = append(r.Added, id)
+	}
+}
+
+func (r *BuildReport) sort() {
+	sort.Strings(r.Added)
+	sort.Strings(r.Updated)
+	sort.Strings(r.Removed)
+}
+
+func (r *BuildReport) String() string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%d added, %d updated, %d removed", len(r.Added), len(r.Updated), len(r.Removed))
+	for _, id := range r.Added {
+		buf.WriteString("\n  + " + id)
+	}
+	for _, id := range r.Updated {
+		buf.WriteString("\n  ~ " + id)
+	}
+	for _, id := range r.Removed {
+		buf.WriteString("\n  - " + id)
+	}
+	return buf.String()
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func getManifestID(route string) string {
+	return filepath.Join("/", route, MANIFEST_JSON)
+}
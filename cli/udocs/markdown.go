@@ -0,0 +1,245 @@
+package udocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/shurcooL/github_flavored_markdown"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+	"gopkg.in/yaml.v2"
+)
+
+// GUIDE_CONFIG is the optional file of a docs directory that configures how its guide is built.
+const GUIDE_CONFIG = "udocs.yml"
+
+// RENDER_VERSION is part of the hash of every rendered page in the manifest, so that pages are
+// rebuilt when udocs renders them differently. Bump it whenever the output of markdownToHTML or
+// processDOM changes.
+const RENDER_VERSION = "1"
+
+const (
+	// ENGINE_GOLDMARK renders markdown with goldmark, which follows the CommonMark and GFM specs.
+	ENGINE_GOLDMARK = "goldmark"
+	// ENGINE_BLACKFRIDAY renders markdown with blackfriday, as guides were rendered before goldmark.
+	// It supports tables, strikethrough and autolinks, whatever the options.
+	ENGINE_BLACKFRIDAY = "blackfriday"
+)
+
+// Renderer renders the markdown of a page into HTML, which processDOM then rewrites.
+//
+// Fenced code blocks with a language are rendered as GFM renders them, into
+// <div class="highlight highlight-LANG"><pre>CODE</pre></div>, since processDOM highlights them from there.
+type Renderer interface {
+	Render(markdown []byte) ([]byte, error)
+}
+
+// MarkdownOptions configures the markdown renderer of a guide, under the markdown key of its udocs.yml.
+type MarkdownOptions struct {
+	Engine          string `yaml:"engine" json:"engine"`
+	Tables          bool   `yaml:"tables" json:"tables"`
+	Strikethrough   bool   `yaml:"strikethrough" json:"strikethrough"`
+	Autolinks       bool   `yaml:"autolinks" json:"autolinks"`
+	TaskLists       bool   `yaml:"task_lists" json:"task_lists"`
+	Footnotes       bool   `yaml:"footnotes" json:"footnotes"`
+	DefinitionLists bool   `yaml:"definition_lists" json:"definition_lists"`
+	Typographer     bool   `yaml:"typographer" json:"typographer"`
+	HardWraps       bool   `yaml:"hard_wraps" json:"hard_wraps"`
+}
+
+// DefaultMarkdownOptions renders markdown with goldmark, and every extension but the typographer and
+// hard wraps.
+func DefaultMarkdownOptions() MarkdownOptions {
+	return MarkdownOptions{
+		Engine:          ENGINE_GOLDMARK,
+		Tables:          true,
+		Strikethrough:   true,
+		Autolinks:       true,
+		TaskLists:       true,
+		Footnotes:       true,
+		DefinitionLists: true,
+	}
+}
+
+// GuideConfig is the udocs.yml of a guide.
+type GuideConfig struct {
+	Markdown MarkdownOptions `yaml:"markdown"`
+	// EditURL is where the source files of the guide are edited, as in
+	// https://github.com/org/repo/edit/master/docs/{path}. {path} is replaced with the path of the source
+	// file of a page in the docs directory, and {commit} with the commit that last changed it.
+	EditURL string `yaml:"edit_url"`
+}
+
+// LoadGuideConfig loads the udocs.yml of the guide in the docs directory dir. Settings it leaves out keep
+// their defaults, as does every setting when there is no udocs.yml.
+func LoadGuideConfig(dir string) (GuideConfig, error) {
+	config := GuideConfig{Markdown: DefaultMarkdownOptions()}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, GUIDE_CONFIG))
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("udocs.LoadGuideConfig failed to parse %s: %v", GUIDE_CONFIG, err)
+	}
+	return config, nil
+}
+
+// LoadMarkdownOptions loads the markdown options of the guide in the docs directory dir from its udocs.yml.
+func LoadMarkdownOptions(dir string) (MarkdownOptions, error) {
+	config, err := LoadGuideConfig(dir)
+	return config.Markdown, err
+}
+
+// fingerprint identifies the options, so that pages are rebuilt when the options of their guide change.
+func (o MarkdownOptions) fingerprint() []byte {
+	data, _ := json.Marshal(o)
+	return data
+}
+
+// renderHash is the manifest hash of a page rendered from data with the options o.
+func renderHash(data []byte, o MarkdownOptions) string {
+	return hashContent(bytes.Join([][]byte{data, []byte(RENDER_VERSION), o.fingerprint()}, nil))
+}
+
+// NewRenderer returns the renderer of the engine given by opts.
+func NewRenderer(opts MarkdownOptions) (Renderer, error) {
+	switch opts.Engine {
+	case "", ENGINE_GOLDMARK:
+		return newGoldmarkRenderer(opts), nil
+	case ENGINE_BLACKFRIDAY:
+		return blackfridayRenderer{}, nil
+	}
+	return nil, fmt.Errorf("udocs.NewRenderer: unsupported markdown engine %q (expected %s or %s)", opts.Engine, ENGINE_GOLDMARK, ENGINE_BLACKFRIDAY)
+}
+
+// markdownToHTML renders a markdown page with renderer, after rewriting the syntax that udocs adds to
+// markdown into syntax the renderers support.
+func markdownToHTML(renderer Renderer, data []byte) ([]byte, error) {
+	return renderer.Render(preprocessFences(preprocessMath(preprocessAdmonitions(data))))
+}
+
+type blackfridayRenderer struct{}
+
+func (blackfridayRenderer) Render(markdown []byte) ([]byte, error) {
+	return github_flavored_markdown.Markdown(markdown), nil
+}
+
+type goldmarkRenderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+}
+
+func newGoldmarkRenderer(opts MarkdownOptions) *goldmarkRenderer {
+	var extensions []goldmark.Extender
+	if opts.Tables {
+		extensions = append(extensions, extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)))
+	}
+	if opts.Strikethrough {
+		extensions = append(extensions, extension.Strikethrough)
+	}
+	if opts.Autolinks {
+		extensions = append(extensions, extension.Linkify)
+	}
+	if opts.TaskLists {
+		extensions = append(extensions, extension.TaskList)
+	}
+	if opts.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if opts.DefinitionLists {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if opts.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+
+	// raw HTML is rendered, and then sanitized along with the rest of the page, as blackfriday pages are
+	rendererOpts := []renderer.Option{
+		goldmarkhtml.WithUnsafe(),
+		renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100)),
+	}
+	if opts.HardWraps {
+		rendererOpts = append(rendererOpts, goldmarkhtml.WithHardWraps())
+	}
+
+	return &goldmarkRenderer{
+		md: goldmark.New(
+			goldmark.WithExtensions(extensions...),
+			goldmark.WithRendererOptions(rendererOpts...),
+		),
+		policy: sanitizationPolicy(),
+	}
+}
+
+func (r *goldmarkRenderer) Render(markdown []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.md.Convert(markdown, &buf); err != nil {
+		return nil, err
+	}
+	return r.policy.SanitizeBytes(buf.Bytes()), nil
+}
+
+// sanitizationPolicy is the policy of github_flavored_markdown, along with the attributes of footnotes,
+// task lists and table alignments.
+func sanitizationPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(bluemonday.SpaceSeparatedTokens).OnElements("div", "span", "sup")
+	p.AllowAttrs("class", "name").Matching(bluemonday.SpaceSeparatedTokens).OnElements("a")
+	p.AllowAttrs("rel").Matching(regexp.MustCompile(`^nofollow$`)).OnElements("a")
+	p.AllowAttrs("aria-hidden").Matching(regexp.MustCompile(`^true$`)).OnElements("a")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).OnElements("a", "div")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowDataURIImages()
+	return p
+}
+
+// codeBlockRenderer renders fenced code blocks as GFM does, for processDOM to highlight.
+type codeBlockRenderer struct{}
+
+func (r codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*ast.FencedCodeBlock)
+	lang := n.Language(source)
+	if len(lang) == 0 {
+		w.WriteString("<pre><code>")
+	} else {
+		w.WriteString(`<div class="highlight highlight-`)
+		w.Write(util.EscapeHTML(lang))
+		w.WriteString(`"><pre>`)
+	}
+
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		w.Write(util.EscapeHTML(line.Value(source)))
+	}
+
+	if len(lang) == 0 {
+		w.WriteString("</code></pre>\n")
+	} else {
+		w.WriteString("</pre></div>\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
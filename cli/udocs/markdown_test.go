@@ -0,0 +1,104 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testRenderer, _ = NewRenderer(DefaultMarkdownOptions())
+
+func renderMarkdown(t *testing.T, markdown string) []byte {
+	data, err := markdownToHTML(testRenderer, []byte(markdown))
+	if err != nil {
+		t.Fatalf("Terminating test due to failed markdown rendering: %v", err)
+	}
+	return data
+}
+
+func TestGoldmarkRenderer(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected string
+	}{
+		{
+			given:    "Claim.[^1]\n\n[^1]: Source.\n",
+			expected: `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref" rel="nofollow">1</a></sup>`,
+		},
+		{
+			given:    "- [x] done\n- [ ] todo\n",
+			expected: `<li><input checked="" disabled="" type="checkbox"> done</li>`,
+		},
+		{
+			given:    "Term\n: Definition\n",
+			expected: "<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>",
+		},
+		{
+			given:    "~~gone~~ and https://example.com\n",
+			expected: `<del>gone</del> and <a href="https://example.com" rel="nofollow">https://example.com</a>`,
+		},
+		{
+			given:    "| a | b |\n|:--|--:|\n| 1 | 2 |\n",
+			expected: `<td align="left">1</td>`,
+		},
+		{
+			given:    "- one\n\n  - two\n    - three\n",
+			expected: "<li>two\n<ul>\n<li>three</li>",
+		},
+		{
+			given:    "```go\nx := <y>\n```\n",
+			expected: `<div class="highlight highlight-go"><pre>x := &lt;y&gt;` + "\n</pre></div>",
+		},
+		{
+			given:    "<script>alert(1)</script><b onclick=\"x()\">bold</b>\n",
+			expected: "<b>bold</b>",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := string(renderMarkdown(t, tc.given)); !strings.Contains(got, tc.expected) {
+			t.Errorf(errFmt, tc.given, tc.expected, got)
+		}
+	}
+}
+
+func TestLoadMarkdownOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts, err := LoadMarkdownOptions(dir)
+	if err != nil || opts != DefaultMarkdownOptions() {
+		t.Errorf(errFmt, "no "+GUIDE_CONFIG, DefaultMarkdownOptions(), opts)
+	}
+
+	given := "markdown:\n  footnotes: false\n  typographer: true\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, GUIDE_CONFIG), []byte(given), 0644); err != nil {
+		t.Fatalf("Terminating test due to failed file write: %v", err)
+	}
+	expected := DefaultMarkdownOptions()
+	expected.Footnotes, expected.Typographer = false, true
+	if opts, err = LoadMarkdownOptions(dir); err != nil || opts != expected {
+		t.Errorf(errFmt, given, expected, opts)
+	}
+
+	renderer, err := NewRenderer(opts)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed renderer creation: %v", err)
+	}
+	got, err := markdownToHTML(renderer, []byte("\"Quoted\" claim.[^1]\n\n[^1]: Source.\n"))
+	if err != nil {
+		t.Fatalf("Terminating test due to failed markdown rendering: %v", err)
+	}
+	if strings.Contains(string(got), "footnote") || !strings.Contains(string(got), "“Quoted”") {
+		t.Errorf(errFmt, given, "typographic quotes without footnotes", string(got))
+	}
+
+	if _, err := NewRenderer(MarkdownOptions{Engine: "pandoc"}); err == nil {
+		t.Errorf(errFmt, "pandoc", "unsupported engine error", err)
+	}
+}
@@ -0,0 +1,240 @@
+package udocs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// mathPrefixRegex matches what a line of a page is nested in: its indentation, and the markers of the
+// blockquotes it is in.
+var mathPrefixRegex = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*`)
+
+// listItemRegex matches the first line of a list item, whose indented lines that follow are not code.
+var listItemRegex = regexp.MustCompile(`^[ \t]*([-*+]|\d+[.)])[ \t]`)
+
+// preprocessMath rewrites the math of a markdown page into empty elements that carry the formulas, for
+// processMathElement to render, since the markdown renderer would take the TeX of formulas for markdown
+// otherwise. Inline math, as in $E = mc^2$, becomes a span, and display math, whose $$ delimiters start
+// and end its lines, becomes a div of its own.
+//
+// Code is left alone, and so are dollar signs escaped as \$. A dollar sign only opens a formula when it
+// is followed by a non-space, and only closes one when it follows a non-space and is not followed by a
+// digit, so that prices such as $5 and $10 read as they are written.
+func preprocessMath(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	var buf bytes.Buffer
+	fence := ""
+	code, blank, listed := false, true, false
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimRight(string(lines[i]), "\r\n")
+		prefix := mathPrefixRegex.FindString(text)
+		content := text[len(prefix):]
+
+		// fenced code, nested in blockquotes and list items or not
+		if fence != "" {
+			if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			buf.Write(lines[i])
+			continue
+		} else if matches := fenceRegex.FindStringSubmatch(strings.TrimLeft(content, " \t")); matches != nil {
+			fence = matches[1]
+			buf.Write(lines[i])
+			continue
+		}
+
+		// indented code, which follows a blank line, unless it is the content of a list item
+		switch indented := strings.HasPrefix(text, "    ") || strings.HasPrefix(text, "\t"); {
+		case strings.TrimSpace(text) == "":
+			blank = true
+			buf.Write(lines[i])
+			continue
+		case indented && (code || blank) && !listed:
+			code, blank = true, false
+			buf.Write(lines[i])
+			continue
+		case !indented:
+			listed = listItemRegex.MatchString(text)
+		}
+		code, blank = false, false
+
+		opening := strings.TrimRight(content, " \t")
+		if !strings.HasPrefix(opening, "$$") || strings.Contains(strings.TrimSuffix(opening[2:], "$$"), "$$") {
+			buf.WriteString(prefix + replaceInlineMath(content) + strings.TrimPrefix(string(lines[i]), text))
+			continue
+		}
+
+		// display math runs up to the line that ends with $$, within its paragraph
+		var tex []string
+		rest := content[2:]
+		end := i
+		for {
+			trimmed := strings.TrimRight(rest, " \t")
+			if strings.HasSuffix(trimmed, "$$") && (end > i || len(strings.TrimSpace(trimmed)) >= 2) {
+				tex = append(tex, strings.TrimSuffix(trimmed, "$$"))
+				break
+			}
+			tex = append(tex, rest)
+			if end++; end >= len(lines) {
+				break
+			}
+			rest = strings.TrimRight(string(lines[end]), "\r\n")
+			rest = rest[len(mathPrefixRegex.FindString(rest)):]
+			if strings.TrimSpace(rest) == "" {
+				end = len(lines)
+				break
+			}
+		}
+		if end >= len(lines) {
+			// unclosed display math is left as it is
+			buf.Write(lines[i])
+			continue
+		}
+
+		separator := strings.TrimRight(prefix, " \t") + "\n"
+		buf.WriteString(separator)
+		buf.WriteString(prefix + mathElement("div", "math-display", strings.Join(tex, "\n")) + "\n")
+		buf.WriteString(separator)
+		i = end
+	}
+	return buf.Bytes()
+}
+
+// replaceInlineMath replaces the formulas of a line between $ or $$ delimiters with the elements that
+// carry them, leaving code spans and escaped dollar signs alone.
+func replaceInlineMath(line string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			// escaped characters, \$ included, are kept as they are
+			if i+1 < len(line) {
+				buf.WriteString(line[i : i+2])
+				i++
+				continue
+			}
+		case '`':
+			// code spans run up to a run of as many backticks
+			n := len(line[i:]) - len(strings.TrimLeft(line[i:], "`"))
+			ticks := line[i : i+n]
+			if end := strings.Index(line[i+n:], ticks); end >= 0 {
+				buf.WriteString(line[i : i+n+end+n])
+				i += n + end + n - 1
+				continue
+			}
+			buf.WriteString(ticks)
+			i += n - 1
+			continue
+		case '$':
+			delim, class := "$", "math-inline"
+			if strings.HasPrefix(line[i:], "$$") {
+				delim, class = "$$", "math-display"
+			}
+			if end := closingDollar(line, i+len(delim), delim); end >= 0 {
+				buf.WriteString(mathElement("span", class, line[i+len(delim):end]))
+				i = end + len(delim) - 1
+				continue
+			}
+			buf.WriteString(delim)
+			i += len(delim) - 1
+			continue
+		}
+		buf.WriteByte(line[i])
+	}
+	return buf.String()
+}
+
+// closingDollar returns the index of the delimiter that closes the formula of line starting at start, or
+// -1 if there is none.
+func closingDollar(line string, start int, delim string) int {
+	if start >= len(line) || line[start] == ' ' || line[start] == '\t' || line[start] == '$' {
+		return -1
+	}
+	for i := start + 1; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '`': // formulas do not run into code spans
+			return -1
+		case strings.HasPrefix(line[i:], delim) && line[i-1] != ' ' && line[i-1] != '\t':
+			if after := i + len(delim); delim == "$" && after < len(line) && (line[after] >= '0' && line[after] <= '9' || line[after] == '$') {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// mathElement returns an empty element carrying a formula. The formula is hex encoded into a class, which
+// is the one attribute the sanitizer keeps on divs and spans, and the only characters it allows in it.
+func mathElement(tag, class, tex string) string {
+	return "<" + tag + ` class="math ` + class + " tex-" + hex.EncodeToString([]byte(strings.TrimSpace(tex))) + `"></` + tag + ">"
+}
+
+// processMathElement renders the formula carried by an element of preprocessMath into MathML. A formula
+// that fails to render is shown as TeX, with the reason it failed as its title.
+func processMathElement(node *html.Node) {
+	var tex string
+	for i, a := range node.Attr {
+		if a.Key != "class" {
+			continue
+		}
+		var classes []string
+		for _, class := range strings.Fields(a.Val) {
+			if encoded := strings.TrimPrefix(class, "tex-"); encoded != class {
+				decoded, err := hex.DecodeString(encoded)
+				if err != nil {
+					return
+				}
+				tex = string(decoded)
+				continue
+			}
+			classes = append(classes, class)
+		}
+		node.Attr[i].Val = strings.Join(classes, " ")
+	}
+	if tex == "" {
+		return
+	}
+
+	display := hasClass(node, "math-display")
+	mathML, err := texToMathML(tex, display)
+	if err == nil {
+		var nodes []*html.Node
+		if nodes, err = html.ParseFragment(strings.NewReader(mathML), node); err == nil {
+			for _, n := range nodes {
+				node.AppendChild(n)
+			}
+			return
+		}
+	}
+
+	for i, a := range node.Attr {
+		if a.Key == "class" {
+			node.Attr[i].Val += " math-error"
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "title", Val: "Failed to render math: " + err.Error()})
+	code := element("code", "language-tex")
+	code.AppendChild(&html.Node{Type: html.TextNode, Data: tex})
+	node.AppendChild(code)
+}
+
+// mathText returns the TeX of a MathML element rendered by processMathElement, which is what its formula
+// is indexed by for search, rather than the text of its elements.
+func mathText(node *html.Node) string {
+	if node.Type == html.ElementNode && node.Data == "annotation" {
+		return nodeText(node)
+	}
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if text := mathText(c); text != "" {
+			return text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package udocs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTexToMathML(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected string
+		err      string
+	}{
+		{
+			given:    `x^2 + y_i`,
+			expected: `<msup><mi>x</mi><mn>2</mn></msup><mo>+</mo><msub><mi>y</mi><mi>i</mi></msub>`,
+		},
+		{
+			given:    `\frac{a+1}{2}`,
+			expected: `<mfrac><mrow><mi>a</mi><mo>+</mo><mn>1</mn></mrow><mn>2</mn></mfrac>`,
+		},
+		{
+			given:    `\sum_{i=1}^{n} i`,
+			expected: `<munderover><mo movablelimits="true">∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><mi>i</mi>`,
+		},
+		{
+			given:    `\sqrt[3]{x} - \sqrt{2}`,
+			expected: `<mroot><mi>x</mi><mn>3</mn></mroot><mo>−</mo><msqrt><mn>2</mn></msqrt>`,
+		},
+		{
+			given:    `\alpha \leq \Omega`,
+			expected: `<mi>α</mi><mo>≤</mo><mi mathvariant="normal">Ω</mi>`,
+		},
+		{
+			given:    `\mathbb{R}^n \sin x`,
+			expected: `<msup><mi>ℝ</mi><mi>n</mi></msup><mi>sin</mi><mi>x</mi>`,
+		},
+		{
+			given:    `\text{if } x > 0`,
+			expected: `<mtext>if </mtext><mi>x</mi><mo>&gt;</mo><mn>0</mn>`,
+		},
+		{
+			given:    `\left( \frac{1}{2} \right)`,
+			expected: `<mrow><mo fence="true">(</mo><mfrac><mn>1</mn><mn>2</mn></mfrac><mo fence="true">)</mo></mrow>`,
+		},
+		{
+			given:    `\begin{pmatrix} a & b \\ c & d \end{pmatrix}`,
+			expected: `<mrow><mo fence="true">(</mo><mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable><mo fence="true">)</mo></mrow>`,
+		},
+		{
+			given: `\frobnicate{x}`,
+			err:   `unsupported command \frobnicate`,
+		},
+		{
+			given: `\frac{a}{b`,
+			err:   "unbalanced {",
+		},
+		{
+			given: `\begin{pmatrix} a \end{bmatrix}`,
+			err:   `\begin{pmatrix} ended by \end{bmatrix}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := texToMathML(tc.given, false)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf(errFmt, tc.given, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf(errFmt, tc.given, tc.expected, err)
+		} else if !strings.Contains(got, "<semantics><mrow>"+tc.expected+"</mrow><annotation") {
+			t.Errorf(errFmt, tc.given, tc.expected, got)
+		}
+	}
+}
+
+func TestMath(t *testing.T) {
+	testCases := []struct {
+		given    string
+		expected []string
+	}{
+		{
+			given: "Energy is $E = mc^2$.\n",
+			expected: []string{
+				`<p>Energy is <span class="math math-inline"><math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><mrow><mi>E</mi><mo>=</mo><mi>m</mi><msup><mi>c</mi><mn>2</mn></msup></mrow>`,
+				`<annotation encoding="application/x-tex">E = mc^2</annotation></semantics></math></span>.</p>`,
+			},
+		},
+		{
+			given: "The sum:\n$$\n\\sum_{i=1}^n i\n$$\nAfter.\n",
+			expected: []string{
+				"<p>The sum:</p>\n" + `<div class="math math-display"><math xmlns="http://www.w3.org/1998/Math/MathML" display="block">`,
+				`<annotation encoding="application/x-tex">\sum_{i=1}^n i</annotation></semantics></math></div>` + "\n<p>After.</p>",
+			},
+		},
+		{
+			given:    "> Quoted $a<b$.\n",
+			expected: []string{`<annotation encoding="application/x-tex">a&lt;b</annotation>`},
+		},
+		{
+			given:    "It costs $5 or $10, `$x$` in code, and \\$y$ escaped.\n",
+			expected: []string{"<p>It costs $5 or $10, <code class=\"language-default\">$x$</code> in code, and $y$ escaped.</p>"},
+		},
+		{
+			given:    "```text\n$x$\n```\n\n    $$x$$\n",
+			expected: []string{`<span class="line">$x$</span>`, "<pre><code class=\"language-default\">$$x$$\n</code></pre>"},
+		},
+		{
+			given:    "Broken $\\frobnicate{x}$ formula.\n",
+			expected: []string{`<span class="math math-inline math-error" title="Failed to render math: unsupported command \frobnicate"><code class="language-tex">\frobnicate{x}</code></span>`},
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := processMarkdown("test", []byte(tc.given), testRenderer, nil)
+		if err != nil {
+			t.Fatalf("Terminating test due to failed markdown processing: %v", err)
+		}
+		for _, expected := range tc.expected {
+			if !strings.Contains(string(got), expected) {
+				t.Errorf(errFmt, tc.given, expected, string(got))
+			}
+		}
+	}
+}
+
+func TestMathSearchable(t *testing.T) {
+	given := "# Mass $m$\n\nEnergy is $E = mc^2$, where\n\n$$\nc = 299792458\n$$\n"
+	got, err := processMarkdown("test", []byte(given), testRenderer, nil)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed markdown processing: %v", err)
+	}
+
+	expected := "Mass m Energy is E = mc^2 , where c = 299792458"
+	if text := extractText(got); text != expected {
+		t.Errorf(errFmt, given, expected, text)
+	}
+
+	sections, err := extractSections(got)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed section extraction: %v", err)
+	}
+	if len(sections) != 1 || sections[0].Heading != "Mass m" || sections[0].Body != "Energy is E = mc^2 , where c = 299792458" {
+		t.Errorf(errFmt, given, "a section headed Mass m", sections)
+	}
+}
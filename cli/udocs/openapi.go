@@ -0,0 +1,1021 @@
+package udocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shurcooL/sanitized_anchor_name"
+	"gopkg.in/yaml.v2"
+)
+
+// DEFAULT_API_TAG is the tag of the operations of an OpenAPI spec that have none, as in Swagger UI.
+const DEFAULT_API_TAG = "default"
+
+// openAPIExtensions are the extensions of the files that may hold an OpenAPI (or Swagger) spec.
+var openAPIExtensions = map[string]bool{".yaml": true, ".yml": true, ".json": true}
+
+// openAPIMethods are the methods of the operations of a path, in the order they are listed in.
+var openAPIMethods = []string{"get", "put", "post", "patch", "delete", "options", "head", "trace"}
+
+// apiSpec is the part of an OpenAPI 3 or Swagger 2 spec that reference pages are built from.
+type apiSpec struct {
+	OpenAPI  string        `yaml:"openapi"`
+	Swagger  string        `yaml:"swagger"`
+	Info     apiInfo       `yaml:"info"`
+	Servers  []apiServer   `yaml:"servers"`
+	Host     string        `yaml:"host"`
+	BasePath string        `yaml:"basePath"`
+	Schemes  []string      `yaml:"schemes"`
+	Tags     []apiTag      `yaml:"tags"`
+	Paths    yaml.MapSlice `yaml:"paths"`
+
+	Components struct {
+		Schemas       namedSchemas               `yaml:"schemas"`
+		Parameters    map[string]*apiParameter   `yaml:"parameters"`
+		RequestBodies map[string]*apiRequestBody `yaml:"requestBodies"`
+		Responses     map[string]*apiResponse    `yaml:"responses"`
+	} `yaml:"components"`
+
+	// the components of Swagger 2 specs
+	Definitions namedSchemas             `yaml:"definitions"`
+	Parameters  map[string]*apiParameter `yaml:"parameters"`
+	Responses   map[string]*apiResponse  `yaml:"responses"`
+
+	operations []*apiOperation
+	anchors    map[string]string // the anchors of the named schemas on the page being rendered
+}
+
+type apiInfo struct {
+	Title       string `yaml:"title"`
+	Summary     string `yaml:"summary"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+type apiServer struct {
+	URL         string `yaml:"url"`
+	Description string `yaml:"description"`
+}
+
+type apiTag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+type apiOperation struct {
+	Tags        []string        `yaml:"tags"`
+	Summary     string          `yaml:"summary"`
+	Description string          `yaml:"description"`
+	OperationID string          `yaml:"operationId"`
+	Deprecated  bool            `yaml:"deprecated"`
+	Parameters  []*apiParameter `yaml:"parameters"`
+	RequestBody *apiRequestBody `yaml:"requestBody"`
+	Responses   apiResponses    `yaml:"responses"`
+
+	method, path string
+	anchors      map[string]string // the anchors of the operation on the pages of its tags
+}
+
+type apiParameter struct {
+	Ref         string      `yaml:"$ref"`
+	Name        string      `yaml:"name"`
+	In          string      `yaml:"in"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Deprecated  bool        `yaml:"deprecated"`
+	Schema      *apiSchema  `yaml:"schema"`
+	Example     interface{} `yaml:"example"`
+
+	// the type of Swagger 2 parameters that are not in the body
+	Type    apiTypes      `yaml:"type"`
+	Format  string        `yaml:"format"`
+	Items   *apiSchema    `yaml:"items"`
+	Enum    []interface{} `yaml:"enum"`
+	Default interface{}   `yaml:"default"`
+}
+
+type apiRequestBody struct {
+	Ref         string      `yaml:"$ref"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Content     apiContents `yaml:"content"`
+}
+
+type apiResponse struct {
+	Ref         string      `yaml:"$ref"`
+	Description string      `yaml:"description"`
+	Content     apiContents `yaml:"content"`
+
+	// the content of Swagger 2 responses
+	Schema   *apiSchema             `yaml:"schema"`
+	Examples map[string]interface{} `yaml:"examples"`
+}
+
+type apiMediaType struct {
+	Schema   *apiSchema  `yaml:"schema"`
+	Example  interface{} `yaml:"example"`
+	Examples map[string]struct {
+		Value interface{} `yaml:"value"`
+	} `yaml:"examples"`
+}
+
+type apiSchema struct {
+	Ref                  string        `yaml:"$ref"`
+	Type                 apiTypes      `yaml:"type"`
+	Format               string        `yaml:"format"`
+	Description          string        `yaml:"description"`
+	Properties           namedSchemas  `yaml:"properties"`
+	Required             []string      `yaml:"required"`
+	Items                *apiSchema    `yaml:"items"`
+	AllOf                []*apiSchema  `yaml:"allOf"`
+	OneOf                []*apiSchema  `yaml:"oneOf"`
+	AnyOf                []*apiSchema  `yaml:"anyOf"`
+	AdditionalProperties interface{}   `yaml:"additionalProperties"`
+	Enum                 []interface{} `yaml:"enum"`
+	Default              interface{}   `yaml:"default"`
+	Example              interface{}   `yaml:"example"`
+	Nullable             bool          `yaml:"nullable"`
+	ReadOnly             bool          `yaml:"readOnly"`
+	WriteOnly            bool          `yaml:"writeOnly"`
+	Deprecated           bool          `yaml:"deprecated"`
+}
+
+// apiTypes is the type of a schema, which OpenAPI 3.1 allows to be a list of types.
+type apiTypes []string
+
+func (t *apiTypes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var typ string
+	if err := unmarshal(&typ); err == nil {
+		*t = apiTypes{typ}
+		return nil
+	}
+	var types []string
+	if err := unmarshal(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// namedSchemas are the schemas of the properties of an object, or the components of a spec, in the order
+// they are listed in.
+type namedSchemas []namedSchema
+
+type namedSchema struct {
+	Name   string
+	Schema *apiSchema
+}
+
+func (s *namedSchemas) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalOrdered(unmarshal, func(key string, value interface{}) error {
+		schema := new(apiSchema)
+		*s = append(*s, namedSchema{Name: key, Schema: schema})
+		return decodeYAML(value, schema)
+	})
+}
+
+// apiResponses are the responses of an operation, keyed by status code, in the order they are listed in.
+type apiResponses []apiStatusResponse
+
+type apiStatusResponse struct {
+	Status   string
+	Response *apiResponse
+}
+
+func (r *apiResponses) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalOrdered(unmarshal, func(key string, value interface{}) error {
+		response := new(apiResponse)
+		*r = append(*r, apiStatusResponse{Status: key, Response: response})
+		return decodeYAML(value, response)
+	})
+}
+
+// apiContents are the media types of a request body or response, in the order they are listed in.
+type apiContents []apiContent
+
+type apiContent struct {
+	MediaType string
+	Media     *apiMediaType
+}
+
+func (c *apiContents) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalOrdered(unmarshal, func(key string, value interface{}) error {
+		media := new(apiMediaType)
+		*c = append(*c, apiContent{MediaType: key, Media: media})
+		return decodeYAML(value, media)
+	})
+}
+
+// unmarshalOrdered calls fn with the keys and values of a mapping in the order they are listed in, which
+// the maps that yaml decodes mappings into do not keep.
+func unmarshalOrdered(unmarshal func(interface{}) error, fn func(key string, value interface{}) error) error {
+	var items yaml.MapSlice
+	if err := unmarshal(&items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := fn(fmt.Sprint(item.Key), item.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeYAML decodes a value of a yaml.MapSlice into out.
+func decodeYAML(value interface{}, out interface{}) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, out)
+}
+
+// parseOpenAPISpec parses an OpenAPI 3 or Swagger 2 spec, written in YAML or JSON. It returns nil when
+// data is not a spec, as for any other YAML or JSON file of a docs directory.
+func parseOpenAPISpec(data []byte) (*apiSpec, error) {
+	var version struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &version); err != nil || (version.OpenAPI == "" && version.Swagger == "") {
+		return nil, nil
+	}
+
+	spec := new(apiSpec)
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+
+	for _, path := range spec.Paths {
+		var item yaml.MapSlice
+		if err := decodeYAML(path.Value, &item); err != nil {
+			return nil, fmt.Errorf("path %v: %v", path.Key, err)
+		}
+
+		// the parameters of a path apply to each of its operations, unless the operation overrides them
+		var shared []*apiParameter
+		operations := make(map[string]*apiOperation)
+		for _, field := range item {
+			key := fmt.Sprint(field.Key)
+			var err error
+			if key == "parameters" {
+				err = decodeYAML(field.Value, &shared)
+			} else if isAPIMethod(key) {
+				op := &apiOperation{method: strings.ToUpper(key), path: fmt.Sprint(path.Key), anchors: make(map[string]string)}
+				operations[key] = op
+				err = decodeYAML(field.Value, op)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s %v: %v", key, path.Key, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			op, ok := operations[method]
+			if !ok {
+				continue
+			}
+			for i := len(shared) - 1; i >= 0; i-- {
+				if !spec.hasParameter(op, spec.parameter(shared[i])) {
+					op.Parameters = append([]*apiParameter{shared[i]}, op.Parameters...)
+				}
+			}
+			spec.operations = append(spec.operations, op)
+		}
+	}
+	return spec, nil
+}
+
+func isAPIMethod(key string) bool {
+	for _, method := range openAPIMethods {
+		if key == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *apiSpec) hasParameter(op *apiOperation, param *apiParameter) bool {
+	for _, p := range op.Parameters {
+		if p = s.parameter(p); p.Name == param.Name && p.In == param.In {
+			return true
+		}
+	}
+	return false
+}
+
+// title is the heading of the operation: its summary, or its method and path.
+func (op *apiOperation) title() string {
+	if op.Summary != "" {
+		return op.Summary
+	}
+	return op.method + " " + op.path
+}
+
+// refName returns the name of the component a reference points at, as in Pet for #/components/schemas/Pet.
+func refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
+
+// schema resolves a reference to a named schema, and returns the schema along with its name, if it has one.
+func (s *apiSpec) schema(schema *apiSchema) (*apiSchema, string) {
+	if schema == nil || schema.Ref == "" {
+		return schema, ""
+	}
+	name := refName(schema.Ref)
+	for _, named := range append(append(namedSchemas{}, s.Components.Schemas...), s.Definitions...) {
+		if named.Name == name {
+			return named.Schema, name
+		}
+	}
+	return &apiSchema{}, name
+}
+
+func (s *apiSpec) parameter(param *apiParameter) *apiParameter {
+	if param.Ref == "" {
+		return param
+	}
+	name := refName(param.Ref)
+	if p, ok := s.Components.Parameters[name]; ok {
+		return p
+	} else if p, ok := s.Parameters[name]; ok {
+		return p
+	}
+	return &apiParameter{Name: name}
+}
+
+func (s *apiSpec) requestBody(body *apiRequestBody) *apiRequestBody {
+	if body == nil || body.Ref == "" {
+		return body
+	}
+	if b, ok := s.Components.RequestBodies[refName(body.Ref)]; ok {
+		return b
+	}
+	return &apiRequestBody{}
+}
+
+func (s *apiSpec) response(response *apiResponse) *apiResponse {
+	if response.Ref == "" {
+		return response
+	}
+	name := refName(response.Ref)
+	if r, ok := s.Components.Responses[name]; ok {
+		return r
+	} else if r, ok := s.Responses[name]; ok {
+		return r
+	}
+	return &apiResponse{}
+}
+
+// content returns the media types of a request body or response, with the schema and examples of Swagger 2
+// responses given as JSON content.
+func (s *apiSpec) content(response *apiResponse) apiContents {
+	if len(response.Content) > 0 || (response.Schema == nil && len(response.Examples) == 0) {
+		return response.Content
+	}
+	media := &apiMediaType{Schema: response.Schema}
+	for mediaType, example := range response.Examples {
+		if media.Example == nil || strings.Contains(mediaType, "json") {
+			media.Example = example
+		}
+	}
+	return apiContents{{"application/json", media}}
+}
+
+// tags returns the tags of the spec that have operations, those it lists first, and the operations of
+// each of them.
+func (s *apiSpec) tags() ([]apiTag, map[string][]*apiOperation) {
+	var tags []apiTag
+	operations := make(map[string][]*apiOperation)
+	for _, op := range s.operations {
+		opTags := op.Tags
+		if len(opTags) == 0 {
+			opTags = []string{DEFAULT_API_TAG}
+		}
+		for _, tag := range opTags {
+			operations[tag] = append(operations[tag], op)
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, tag := range s.Tags {
+		if len(operations[tag.Name]) > 0 && !listed[tag.Name] {
+			tags, listed[tag.Name] = append(tags, tag), true
+		}
+	}
+	for _, op := range s.operations {
+		for _, tag := range op.Tags {
+			if !listed[tag] {
+				tags, listed[tag] = append(tags, apiTag{Name: tag}), true
+			}
+		}
+	}
+	if len(operations[DEFAULT_API_TAG]) > 0 && !listed[DEFAULT_API_TAG] {
+		tags = append(tags, apiTag{Name: DEFAULT_API_TAG})
+	}
+	return tags, operations
+}
+
+// apiPage is a page built from an OpenAPI spec: the overview of the spec, or the reference of the
+// operations of one of its tags.
+type apiPage struct {
+	Page
+	markdown []byte
+}
+
+// pages renders the spec into the markdown of its pages, for the spec whose file is stored as id: an
+// overview, as in /route/api/pets.html for /route/api/pets.yaml, followed by a page for each tag, as in
+// /route/api/pets/store.html. The file of the spec itself is linked to from the overview.
+func (s *apiSpec) pages(id string) []apiPage {
+	base := strings.TrimSuffix(id, filepath.Ext(id))
+	tags, operations := s.tags()
+
+	var pages []apiPage
+	slugs := make(map[string]bool)
+	for _, tag := range tags {
+		path := filepath.Join(base, uniqueSlug(sanitized_anchor_name.Create(tag.Name), slugs)+".html")
+		pages = append(pages, apiPage{
+			Page:     Page{Title: tag.Name, Path: path, Description: firstLine(tag.Description)},
+			markdown: s.tagMarkdown(tag, operations[tag.Name]),
+		})
+	}
+
+	title := s.Info.Title
+	if title == "" {
+		title = filepath.Base(base)
+	}
+	overview := apiPage{
+		Page:     Page{Title: title, Path: base + ".html", Description: s.Info.Summary},
+		markdown: s.overviewMarkdown(title, id, pages, operations),
+	}
+	return append([]apiPage{overview}, pages...)
+}
+
+func (s *apiSpec) overviewMarkdown(title, id string, pages []apiPage, operations map[string][]*apiOperation) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", escapeMarkdown(title))
+	if s.Info.Version != "" {
+		fmt.Fprintf(&buf, "Version %s of the API. ", escapeMarkdown(s.Info.Version))
+	}
+	fmt.Fprintf(&buf, "[Download the OpenAPI spec](%s)\n\n", id)
+	if s.Info.Description != "" {
+		buf.WriteString(strings.TrimSpace(s.Info.Description) + "\n\n")
+	}
+
+	servers := s.Servers
+	if len(servers) == 0 && s.Host != "" {
+		schemes := s.Schemes
+		if len(schemes) == 0 {
+			schemes = []string{"https"}
+		}
+		for _, scheme := range schemes {
+			servers = append(servers, apiServer{URL: scheme + "://" + s.Host + s.BasePath})
+		}
+	}
+	if len(servers) > 0 {
+		buf.WriteString("## Servers\n\n")
+		for _, server := range servers {
+			fmt.Fprintf(&buf, "* `%s`", server.URL)
+			if server.Description != "" {
+				buf.WriteString(": " + tableCell(server.Description))
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("## Operations\n\n")
+	for _, page := range pages {
+		fmt.Fprintf(&buf, "### [%s](%s)\n\n", escapeMarkdown(page.Title), page.Path)
+		if page.Description != "" {
+			buf.WriteString(page.Description + "\n\n")
+		}
+		buf.WriteString("| Operation | Summary |\n| --- | --- |\n")
+		for _, op := range operations[page.Title] {
+			fmt.Fprintf(&buf, "| [`%s %s`](%s#%s) | %s |\n", op.method, op.path, page.Path, op.anchors[page.Title], tableCell(op.Summary))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func (s *apiSpec) tagMarkdown(tag apiTag, operations []*apiOperation) []byte {
+	// the anchors of the headings are those processHeadingElement gives them, in the order of the page
+	slugs := map[string]bool{sanitized_anchor_name.Create(tag.Name): true}
+	schemas := make(map[string]bool) // the named schemas used by the operations
+	for _, op := range operations {
+		anchor := uniqueSlug(sanitized_anchor_name.Create(op.title()), slugs)
+		slugs[anchor], op.anchors[tag.Name] = true, anchor
+		s.operationSchemas(op, schemas)
+	}
+	var named []string
+	s.anchors = make(map[string]string)
+	if len(schemas) > 0 {
+		slugs[uniqueSlug("schemas", slugs)] = true
+	}
+	for _, schema := range append(append(namedSchemas{}, s.Components.Schemas...), s.Definitions...) {
+		if schemas[schema.Name] && s.anchors[schema.Name] == "" {
+			named = append(named, schema.Name)
+			s.anchors[schema.Name] = uniqueSlug(sanitized_anchor_name.Create(schema.Name), slugs)
+			slugs[s.anchors[schema.Name]] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n\n", escapeMarkdown(tag.Name))
+	if tag.Description != "" {
+		buf.WriteString(strings.TrimSpace(tag.Description) + "\n\n")
+	}
+	for _, op := range operations {
+		fmt.Fprintf(&buf, "## %s\n\n", escapeMarkdown(op.title()))
+		fmt.Fprintf(&buf, "<div class=\"api-endpoint\"><span class=\"api-method api-method-%s\">%s</span> <code>%s</code></div>\n\n",
+			strings.ToLower(op.method), op.method, html.EscapeString(op.path))
+		if op.Deprecated {
+			buf.WriteString("> [!WARNING] Deprecated\n> This operation is deprecated, and may be removed from the API.\n\n")
+		}
+		if op.Description != "" {
+			buf.WriteString(strings.TrimSpace(op.Description) + "\n\n")
+		}
+		s.writeParameters(&buf, op)
+		s.writeRequestBody(&buf, op)
+		s.writeResponses(&buf, op)
+	}
+	if len(named) == 0 {
+		return buf.Bytes()
+	}
+
+	buf.WriteString("## Schemas\n\n")
+	for _, name := range named {
+		ref := &apiSchema{Ref: "#/components/schemas/" + name}
+		schema, _ := s.schema(ref)
+		fmt.Fprintf(&buf, "### %s\n\n", escapeMarkdown(name))
+		if schema.Description != "" {
+			buf.WriteString(strings.TrimSpace(schema.Description) + "\n\n")
+		}
+		if properties := s.properties(schema, make(map[string]bool)); len(properties) > 0 {
+			buf.WriteString("| Property | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+			for _, property := range properties {
+				fmt.Fprintf(&buf, "| `%s` | %s | %s | %s |\n", property.Name, s.typeName(property.Schema), yesNo(property.required),
+					tableCell(schemaDescription(property.Schema)))
+			}
+			buf.WriteString("\n")
+		} else {
+			fmt.Fprintf(&buf, "Type: %s\n\n", s.typeName(schema))
+		}
+		writeExample(&buf, "", s.example(ref, make(map[string]bool)))
+	}
+	return buf.Bytes()
+}
+
+// operationSchemas adds the named schemas that the parameters, request body and responses of an operation
+// use to schemas.
+func (s *apiSpec) operationSchemas(op *apiOperation, schemas map[string]bool) {
+	for _, param := range op.Parameters {
+		s.collectSchemas(s.parameterSchema(s.parameter(param)), schemas)
+	}
+	if body := s.requestBody(op.RequestBody); body != nil {
+		for _, content := range body.Content {
+			s.collectSchemas(content.Media.Schema, schemas)
+		}
+	}
+	for _, r := range op.Responses {
+		for _, content := range s.content(s.response(r.Response)) {
+			s.collectSchemas(content.Media.Schema, schemas)
+		}
+	}
+}
+
+// parameterSchema returns the schema of a parameter, which Swagger 2 gives as fields of the parameter itself.
+func (s *apiSpec) parameterSchema(param *apiParameter) *apiSchema {
+	if param.Schema != nil {
+		return param.Schema
+	}
+	return &apiSchema{Type: param.Type, Format: param.Format, Items: param.Items, Enum: param.Enum, Default: param.Default}
+}
+
+func (s *apiSpec) writeParameters(buf *bytes.Buffer, op *apiOperation) {
+	var params []*apiParameter
+	for _, param := range op.Parameters {
+		if param = s.parameter(param); param.In != "body" {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return
+	}
+
+	buf.WriteString("### Parameters\n\n| Name | In | Type | Required | Description |\n| --- | --- | --- | --- | --- |\n")
+	for _, param := range params {
+		schema := s.parameterSchema(param)
+		description := param.Description
+		if param.Deprecated {
+			description = strings.TrimSpace("Deprecated. " + description)
+		}
+		if len(schema.Enum) > 0 {
+			description = strings.TrimSpace(description + " " + enumDescription(schema.Enum))
+		}
+		fmt.Fprintf(buf, "| `%s` | %s | %s | %s | %s |\n", param.Name, param.In, s.typeName(schema), yesNo(param.Required || param.In == "path"), tableCell(description))
+	}
+	buf.WriteString("\n")
+}
+
+func (s *apiSpec) writeRequestBody(buf *bytes.Buffer, op *apiOperation) {
+	body := s.requestBody(op.RequestBody)
+	if body == nil {
+		// the body of a Swagger 2 operation is one of its parameters
+		for _, param := range op.Parameters {
+			if param = s.parameter(param); param.In == "body" {
+				body = &apiRequestBody{
+					Description: param.Description,
+					Required:    param.Required,
+					Content:     apiContents{{"application/json", &apiMediaType{Schema: param.Schema, Example: param.Example}}},
+				}
+			}
+		}
+	}
+	if body == nil {
+		return
+	}
+
+	buf.WriteString("### Request body\n\n")
+	if body.Description != "" {
+		buf.WriteString(strings.TrimSpace(body.Description) + "\n\n")
+	}
+	required := "Optional"
+	if body.Required {
+		required = "Required"
+	}
+	for _, content := range body.Content {
+		fmt.Fprintf(buf, "%s `%s`: %s\n\n", required, content.MediaType, s.typeName(content.Media.Schema))
+		writeExample(buf, content.MediaType, s.mediaExample(content.Media))
+	}
+}
+
+func (s *apiSpec) writeResponses(buf *bytes.Buffer, op *apiOperation) {
+	if len(op.Responses) == 0 {
+		return
+	}
+
+	var examples bytes.Buffer
+	buf.WriteString("### Responses\n\n| Status | Description | Type |\n| --- | --- | --- |\n")
+	for _, r := range op.Responses {
+		response := s.response(r.Response)
+		var types []string
+		for _, content := range s.content(response) {
+			if content.Media.Schema != nil {
+				types = append(types, s.typeName(content.Media.Schema)+" (`"+content.MediaType+"`)")
+			}
+			if example := s.mediaExample(content.Media); example != nil && !strings.Contains(examples.String(), "`"+r.Status+"`") {
+				fmt.Fprintf(&examples, "Example `%s` response:\n\n", r.Status)
+				writeExample(&examples, content.MediaType, example)
+			}
+		}
+		fmt.Fprintf(buf, "| `%s` | %s | %s |\n", r.Status, tableCell(response.Description), strings.Join(types, ", "))
+	}
+	buf.WriteString("\n")
+	buf.Write(examples.Bytes())
+}
+
+// collectSchemas adds the named schemas that schema uses, directly or not, to schemas.
+func (s *apiSpec) collectSchemas(schema *apiSchema, schemas map[string]bool) {
+	if schema == nil {
+		return
+	}
+	if resolved, name := s.schema(schema); name != "" {
+		if schemas[name] {
+			return
+		}
+		schemas[name] = true
+		schema = resolved
+	}
+	for _, property := range schema.Properties {
+		s.collectSchemas(property.Schema, schemas)
+	}
+	for _, sub := range append(append(append([]*apiSchema{schema.Items}, schema.AllOf...), schema.OneOf...), schema.AnyOf...) {
+		s.collectSchemas(sub, schemas)
+	}
+	if additional, ok := schema.AdditionalProperties.(yaml.MapSlice); ok {
+		var sub apiSchema
+		if decodeYAML(additional, &sub) == nil {
+			s.collectSchemas(&sub, schemas)
+		}
+	}
+}
+
+type apiProperty struct {
+	namedSchema
+	required bool
+}
+
+// properties returns the properties of an object schema, including those of the schemas it is all of.
+func (s *apiSpec) properties(schema *apiSchema, seen map[string]bool) []apiProperty {
+	schema, name := s.schema(schema)
+	if name != "" {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+	}
+
+	var properties []apiProperty
+	for _, sub := range schema.AllOf {
+		properties = append(properties, s.properties(sub, seen)...)
+	}
+	for _, property := range schema.Properties {
+		required := false
+		for _, r := range schema.Required {
+			required = required || r == property.Name
+		}
+		properties = append(properties, apiProperty{property, required})
+	}
+	return properties
+}
+
+// typeName describes the type of a schema, with named schemas linked to their description on the page.
+func (s *apiSpec) typeName(schema *apiSchema) string {
+	if schema == nil {
+		return ""
+	}
+	if _, name := s.schema(schema); name != "" {
+		return fmt.Sprintf("[%s](#%s)", escapeMarkdown(name), s.anchors[name])
+	}
+
+	combine := func(op string, schemas []*apiSchema) string {
+		var names []string
+		for _, sub := range schemas {
+			names = append(names, s.typeName(sub))
+		}
+		return op + " " + strings.Join(names, ", ")
+	}
+	switch {
+	case len(schema.AllOf) == 1:
+		return s.typeName(schema.AllOf[0])
+	case len(schema.AllOf) > 0:
+		return combine("all of", schema.AllOf)
+	case len(schema.OneOf) > 0:
+		return combine("one of", schema.OneOf)
+	case len(schema.AnyOf) > 0:
+		return combine("any of", schema.AnyOf)
+	}
+
+	var types []string
+	for _, typ := range schema.Type {
+		switch {
+		case typ == "array" && schema.Items != nil:
+			typ = "array of " + s.typeName(schema.Items)
+		case schema.Format != "" && typ != "null":
+			typ += " (" + schema.Format + ")"
+		}
+		types = append(types, typ)
+	}
+	if len(types) == 0 {
+		if len(schema.Properties) > 0 {
+			return "object"
+		}
+		return "any"
+	}
+	if schema.Nullable {
+		types = append(types, "null")
+	}
+	return strings.Join(types, " or ")
+}
+
+// schemaDescription describes a property, along with the values and default it has, if any.
+func schemaDescription(schema *apiSchema) string {
+	var parts []string
+	if schema.Deprecated {
+		parts = append(parts, "Deprecated.")
+	}
+	if schema.ReadOnly {
+		parts = append(parts, "Read only.")
+	} else if schema.WriteOnly {
+		parts = append(parts, "Write only.")
+	}
+	if schema.Description != "" {
+		parts = append(parts, schema.Description)
+	}
+	if len(schema.Enum) > 0 {
+		parts = append(parts, enumDescription(schema.Enum))
+	}
+	if schema.Default != nil {
+		parts = append(parts, fmt.Sprintf("Defaults to `%s`.", exampleJSON(schema.Default, "")))
+	}
+	return strings.Join(parts, " ")
+}
+
+func enumDescription(values []interface{}) string {
+	var quoted []string
+	for _, value := range values {
+		quoted = append(quoted, "`"+exampleJSON(value, "")+"`")
+	}
+	return "One of " + strings.Join(quoted, ", ") + "."
+}
+
+// mediaExample returns the example of a request body or response: the one it gives, or one made up from
+// its schema.
+func (s *apiSpec) mediaExample(media *apiMediaType) interface{} {
+	if media.Example != nil {
+		return media.Example
+	}
+	names := make([]string, 0, len(media.Examples))
+	for name := range media.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := media.Examples[name].Value; value != nil {
+			return value
+		}
+	}
+	if media.Schema == nil {
+		return nil
+	}
+	return s.example(media.Schema, make(map[string]bool))
+}
+
+// example makes up an example of a schema, from the examples, defaults and values of its properties,
+// or values of their types otherwise. Named schemas are not expanded within themselves.
+func (s *apiSpec) example(schema *apiSchema, seen map[string]bool) interface{} {
+	if schema == nil {
+		return nil
+	}
+	schema, name := s.schema(schema)
+	if name != "" {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		defer delete(seen, name)
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.OneOf) > 0:
+		return s.example(schema.OneOf[0], seen)
+	case len(schema.AnyOf) > 0:
+		return s.example(schema.AnyOf[0], seen)
+	case len(schema.AllOf) > 0 || len(schema.Properties) > 0:
+		var object yaml.MapSlice
+		for _, property := range s.properties(&apiSchema{AllOf: schema.AllOf, Properties: schema.Properties}, make(map[string]bool)) {
+			object = append(object, yaml.MapItem{Key: property.Name, Value: s.example(property.Schema, seen)})
+		}
+		return object
+	}
+
+	typ := ""
+	if len(schema.Type) > 0 {
+		typ = schema.Type[0]
+	}
+	switch typ {
+	case "array":
+		if item := s.example(schema.Items, seen); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "object":
+		return yaml.MapSlice{}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date":
+			return "2006-01-02"
+		case "date-time":
+			return "2006-01-02T15:04:05Z"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// writeExample writes an example as a code block, in JSON unless it is a string of another media type.
+func writeExample(buf *bytes.Buffer, mediaType string, example interface{}) {
+	if example == nil {
+		return
+	}
+	if text, ok := example.(string); ok && mediaType != "" && !strings.Contains(mediaType, "json") {
+		fmt.Fprintf(buf, "```text\n%s\n```\n\n", strings.TrimRight(text, "\n"))
+		return
+	}
+	fmt.Fprintf(buf, "```json\n%s\n```\n\n", exampleJSON(example, ""))
+}
+
+// exampleJSON encodes an example decoded from YAML as indented JSON, keeping the order of the keys of
+// its objects.
+func exampleJSON(value interface{}, indent string) string {
+	var buf bytes.Buffer
+	switch value := value.(type) {
+	case yaml.MapSlice:
+		if len(value) == 0 {
+			return "{}"
+		}
+		buf.WriteString("{")
+		for i, item := range value {
+			key, _ := json.Marshal(fmt.Sprint(item.Key))
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			fmt.Fprintf(&buf, "\n%s  %s: %s", indent, key, exampleJSON(item.Value, indent+"  "))
+		}
+		buf.WriteString("\n" + indent + "}")
+	case map[interface{}]interface{}:
+		var object yaml.MapSlice
+		for key, v := range value {
+			object = append(object, yaml.MapItem{Key: fmt.Sprint(key), Value: v})
+		}
+		sort.Slice(object, func(i, j int) bool { return object[i].Key.(string) < object[j].Key.(string) })
+		return exampleJSON(object, indent)
+	case []interface{}:
+		if len(value) == 0 {
+			return "[]"
+		}
+		buf.WriteString("[")
+		for i, v := range value {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			fmt.Fprintf(&buf, "\n%s  %s", indent, exampleJSON(v, indent+"  "))
+		}
+		buf.WriteString("\n" + indent + "]")
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "null"
+		}
+		buf.Write(data)
+	}
+	return buf.String()
+}
+
+// applyAPIPages replaces the entries of the summary that link to OpenAPI specs with the overview of the
+// spec, and nests the pages of its tags under it. specs maps the IDs of the specs to their pages.
+func (s *Summary) applyAPIPages(specs map[string][]Page) {
+	var apply func(pages []Page)
+	apply = func(pages []Page) {
+		for i := range pages {
+			apply(pages[i].SubPages)
+			specPages, ok := specs[pages[i].Path]
+			if !ok {
+				continue
+			}
+			pages[i].Path = specPages[0].Path
+			for _, page := range specPages[1:] {
+				page.TreeLevel = pages[i].TreeLevel + 1
+				pages[i].SubPages = append(pages[i].SubPages, page)
+			}
+		}
+	}
+	apply(s.Pages)
+}
+
+// escapeMarkdown escapes the characters of text that markdown, or the math udocs adds to it, would
+// otherwise take for syntax.
+func escapeMarkdown(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		if strings.ContainsRune("\\`*_[]<>#|$~", r) {
+			buf.WriteRune('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// tableCell joins the lines of a description into a table cell.
+func tableCell(text string) string {
+	return strings.Replace(strings.Join(strings.Fields(text), " "), "|", "\\|", -1)
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexRune(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,229 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+const testOpenAPISpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.2.0
+tags:
+  - name: pets
+    description: Everything about pets.
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        description: The id of the pet.
+        schema:
+          type: integer
+          format: int64
+    get:
+      tags: [pets]
+      summary: Get a pet
+      responses:
+        '200':
+          description: The pet.
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        '404':
+          $ref: '#/components/responses/NotFound'
+  /health:
+    get:
+      deprecated: true
+      responses:
+        '200':
+          description: OK
+components:
+  responses:
+    NotFound:
+      description: No such pet.
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: Rex
+        status:
+          type: string
+          enum: [available, sold]
+        friends:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+`
+
+const testSwaggerSpec = `{
+	"swagger": "2.0",
+	"info": {"title": "Users", "version": "1"},
+	"paths": {
+		"/users": {
+			"post": {
+				"tags": ["users"],
+				"summary": "Add a user",
+				"parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/User"}}],
+				"responses": {"201": {"description": "Created", "examples": {"application/json": {"id": 7}}}}
+			}
+		}
+	},
+	"definitions": {"User": {"type": "object", "properties": {"id": {"type": "integer"}}}}
+}
+`
+
+func TestOpenAPIPages(t *testing.T) {
+	testCases := []struct {
+		given    string
+		pages    []Page
+		expected [][]string
+	}{
+		{
+			given: testOpenAPISpec,
+			pages: []Page{
+				{Title: "Pet Store", Path: "/route/api/pets.html"},
+				{Title: "pets", Path: "/route/api/pets/pets.html", Description: "Everything about pets."},
+				{Title: "default", Path: "/route/api/pets/default.html"},
+			},
+			expected: [][]string{
+				{
+					"# Pet Store\n\nVersion 1.2.0 of the API. [Download the OpenAPI spec](/route/api/pets.yaml)",
+					"| [`GET /pets/{petId}`](/route/api/pets/pets.html#get-a-pet) | Get a pet |",
+					"| [`GET /health`](/route/api/pets/default.html#get-health) |  |",
+				},
+				{
+					`<div class="api-endpoint"><span class="api-method api-method-get">GET</span> <code>/pets/{petId}</code></div>`,
+					"| `petId` | path | integer (int64) | yes | The id of the pet. |",
+					"| `200` | The pet. | [Pet](#pet) (`application/json`) |\n| `404` | No such pet. |  |",
+					"Example `200` response:\n\n```json\n{\n  \"name\": \"Rex\",\n  \"status\": \"available\",\n  \"friends\": []\n}\n```",
+					"| `status` | string | no | One of `\"available\"`, `\"sold\"`. |",
+					"| `friends` | array of [Pet](#pet) | no |  |",
+				},
+				{
+					"## GET /health",
+					"> [!WARNING] Deprecated",
+				},
+			},
+		},
+		{
+			given: testSwaggerSpec,
+			pages: []Page{
+				{Title: "Users", Path: "/route/api/pets.html"},
+				{Title: "users", Path: "/route/api/pets/users.html"},
+			},
+			expected: [][]string{
+				{"| [`POST /users`](/route/api/pets/users.html#add-a-user) | Add a user |"},
+				{
+					"### Request body\n\nRequired `application/json`: [User](#user)\n\n```json\n{\n  \"id\": 0\n}\n```",
+					"Example `201` response:\n\n```json\n{\n  \"id\": 7\n}\n```",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		spec, err := parseOpenAPISpec([]byte(tc.given))
+		if err != nil || spec == nil {
+			t.Fatalf("Terminating test due to failed spec parsing: %v", err)
+		}
+		pages := spec.pages("/route/api/pets.yaml")
+		if len(pages) != len(tc.pages) {
+			t.Errorf(errFmt, tc.given, tc.pages, pages)
+			continue
+		}
+		for i, page := range pages {
+			if page.Page.Title != tc.pages[i].Title || page.Path != tc.pages[i].Path || page.Description != tc.pages[i].Description {
+				t.Errorf(errFmt, tc.given, tc.pages[i], page.Page)
+			}
+			for _, expected := range tc.expected[i] {
+				if !strings.Contains(string(page.markdown), expected) {
+					t.Errorf(errFmt, tc.given, expected, string(page.markdown))
+				}
+			}
+		}
+	}
+
+	if spec, err := parseOpenAPISpec([]byte(`{"name": "not a spec"}`)); spec != nil || err != nil {
+		t.Errorf(errFmt, "a JSON file that is not a spec", nil, spec)
+	}
+}
+
+func TestBuildOpenAPI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		README_MD:        "# Test\nHello, world!",
+		SUMMARY_MD:       "# Test\n* [Overview](README.md)\n* [Pets API](api/pets.yaml)\n* [](api/users.json)",
+		"api/pets.yaml":  testOpenAPISpec,
+		"api/users.json": testSwaggerSpec,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Terminating test due to failed dir creation: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Terminating test due to failed file write: %v", err)
+		}
+	}
+
+	dao := storage.NewMockDao("/tmp")
+	if _, err := Build("test-route", dir, dao); err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+
+	sidebar, err := LoadSidebar(dao)
+	if err != nil || len(sidebar) != 1 || len(sidebar[0].Pages) != 3 {
+		t.Fatalf("Terminating test due to unexpected sidebar: %v (%v)", sidebar, err)
+	}
+	pets, users := sidebar[0].Pages[1], sidebar[0].Pages[2]
+	if pets.Title != "Pets API" || pets.Path != "/test-route/api/pets.html" || len(pets.SubPages) != 2 ||
+		pets.SubPages[0].Path != "/test-route/api/pets/pets.html" || pets.SubPages[0].TreeLevel != 2 {
+		t.Errorf(errFmt, files[SUMMARY_MD], "the pets and default pages nested under Pets API", pets)
+	}
+	if users.Title != "Users" || len(users.SubPages) != 1 {
+		t.Errorf(errFmt, files[SUMMARY_MD], "the users page nested under Users", users)
+	}
+
+	data, err := dao.Fetch("/test-route/api/pets/pets.html")
+	if err != nil {
+		t.Fatalf("Terminating test due to missing page: %v", err)
+	}
+	if expected := `<h2 id="get-a-pet">`; !strings.Contains(string(data), expected) {
+		t.Errorf(errFmt, testOpenAPISpec, expected, string(data))
+	}
+	if _, err := dao.Fetch("/test-route/api/pets.yaml"); err != nil {
+		t.Errorf("expected the spec to be kept for download, got: %v", err)
+	}
+
+	hashes, err := dao.IndexedHashes()
+	if err != nil {
+		t.Fatalf("Terminating test due to failed index lookup: %v", err)
+	}
+	for _, id := range []string{"/test-route/api/pets.html", "/test-route/api/pets/pets.html", "/test-route/api/users/users.html"} {
+		if _, ok := hashes[id]; !ok {
+			t.Errorf("expected %s to be indexed, got: %v", id, hashes)
+		}
+	}
+
+	report, err := Build("test-route", dir, dao)
+	if err != nil {
+		t.Fatalf("Build(test-route, %s, *MockDao) => %v", dir, err)
+	}
+	if len(report.Added) != 0 || len(report.Updated) != 0 || len(report.Removed) != 0 {
+		t.Errorf("unchanged rebuild -> expected: no changes, got: %s", report)
+	}
+}
@@ -9,23 +9,35 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/seanawilliams/udocs/cli/storage"
 )
 
 type Sidebar []Summary
 
+// Summary is the table of contents of a guide. Version is set for versioned guides, in which case
+// Versions lists every published version of the guide, from the latest to the earliest.
 type Summary struct {
-	Route  string `json:"route"`
-	Header string `json:"header"`
-	Pages  []Page `json:"pages"`
+	Route    string   `json:"route"`
+	Version  string   `json:"version,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+	Header   string   `json:"header"`
+	Pages    []Page   `json:"pages"`
 }
 
+// Page is an entry of the table of contents of a guide. Path is empty for entries that only group the
+// entries nested under them. Section marks the start of a new section of the table of contents, titled
+// by Title (or untitled, for a plain separator); sections have no path and no sub pages. Description and
+// Draft come from the front matter of the page.
 type Page struct {
-	Title     string `json:"title"`
-	Path      string `json:"path"`
-	TreeLevel int    `json:"tree_level"`
-	SubPages  []Page `json:"sub_pages"`
+	Title       string `json:"title"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Draft       bool   `json:"draft,omitempty"`
+	Section     bool   `json:"section,omitempty"`
+	TreeLevel   int    `json:"tree_level"`
+	SubPages    []Page `json:"sub_pages"`
 }
 
 func LoadSidebar(dao storage.Dao) (Sidebar, error) {
@@ -42,6 +54,26 @@ func LoadSidebar(dao storage.Dao) (Sidebar, error) {
 	return sidebar, nil
 }
 
+// sidebarLock serializes updates to the sidebar, which is shared by every route.
+var sidebarLock sync.Mutex
+
+// UpdateSidebar loads the sidebar of dao, applies fn to it and saves it, while holding a lock that keeps
+// concurrent publications of different routes from losing each other's updates. An empty sidebar is
+// passed to fn when there is none yet.
+func UpdateSidebar(dao storage.Dao, fn func(Sidebar) (Sidebar, error)) error {
+	sidebarLock.Lock()
+	defer sidebarLock.Unlock()
+
+	sidebar, err := LoadSidebar(dao)
+	if err != nil && !storage.IsNotFound(err) {
+		return fmt.Errorf("udocs.UpdateSidebar: %v", err)
+	}
+	if sidebar, err = fn(sidebar); err != nil {
+		return err
+	}
+	return sidebar.Save(dao)
+}
+
 func (s Sidebar) Save(dao storage.Dao) error {
 	data, err := json.Marshal(s)
 	if err != nil {
@@ -50,14 +82,36 @@ func (s Sidebar) Save(dao storage.Dao) error {
 	return dao.Insert(SIDEBAR_JSON, data)
 }
 
+// Merge adds the summary of a guide to the sidebar, replacing the previous summary of the same route.
+// The sidebar always shows the latest version of a versioned guide, so merging the summary of an earlier
+// version only adds that version to the versions of the guide.
 func (s Sidebar) Merge(summary Summary) Sidebar {
 	for i, item := range s {
 		if item.Route == summary.Route {
+			if summary.Version == "" {
+				summary.Versions = item.Versions
+				s[i] = summary
+				return s
+			}
+
+			summary.Versions = append([]string{summary.Version}, item.Versions...)
+			if item.HasVersion(summary.Version) {
+				summary.Versions = item.Versions
+			}
+			sortVersions(summary.Versions)
+			if item.Version != "" && summary.Latest() != summary.Version {
+				item.Versions = summary.Versions
+				summary = item
+			}
 			s[i] = summary
 			return s
 		}
 	}
 
+	if summary.Version != "" {
+		summary.Versions = []string{summary.Version}
+	}
+
 	if len(s) == 0 {
 		s = make([]Summary, 0)
 	}
@@ -82,49 +136,86 @@ func ParseSummaryHeader(scanner *bufio.Scanner) string {
 	return ""
 }
 
+// SummaryError is an error in SUMMARY.md, at the given line (counting from 1).
+type SummaryError struct {
+	Line    int
+	Message string
+}
+
+func (e *SummaryError) Error() string {
+	return fmt.Sprintf("udocs.ParseSummary failed to parse line %d: %s", e.Line, e.Message)
+}
+
+var (
+	summaryHeaderRegex  = regexp.MustCompile(`^#\s+(.*)$`)
+	summarySectionRegex = regexp.MustCompile(`^#{2,6}\s+(.*?)\s*#*$`)
+	summaryDividerRegex = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	summaryItemRegex    = regexp.MustCompile(`^([ \t]*)(?:[*+-]|\d+[.)])[ \t]+(.*)$`)
+	summaryLinkRegex    = regexp.MustCompile(`^\[(.*)\]\(([^()]*)\)$`)
+)
+
+// ParseSummary parses the nested list of SUMMARY.md into the table of contents of a guide. Items may be
+// nested to any depth, with any consistent indentation of spaces or tabs. An item without a link, as in
+// "* Part One", groups the items nested under it. A "## Heading" starts a new titled section of the
+// table of contents, and a thematic break ("---") separates two sections; both are returned as pages
+// with Section set.
 func ParseSummary(route string, data []byte) (Summary, error) {
 	summary := Summary{Route: route, Pages: make([]Page, 0)}
 	scanner := bufio.NewScanner(bytes.NewReader(data))
 
-	// parse the header
-	if summary.Header = ParseSummaryHeader(scanner); summary.Header == "" {
-		return summary, errors.New("udocs.ParseSummary did not find the H1 (header) line (i.e. '# My Guide')")
+	// the items that are open at the current line, from the outermost to the innermost, with the
+	// indentation of their bullets
+	type openItem struct {
+		page   *Page
+		indent int
 	}
+	var stack []openItem
 
-	lineRegex := regexp.MustCompile(`\* \[(.*)\]\((.*)\)$`)
-
-	// parse the remaining summary tree
+	lineNum := 0
 	for scanner.Scan() {
-		line := scanner.Text()
-		indices := lineRegex.FindStringSubmatchIndex(line)
-		// methods from package 'regexp' return slices with len() == 2 * (# of regex sub-expressions + 1),
-		// and lineRegex has 2 sub-expressions, hence the magic number 6 below
-		if len(indices) != 6 {
-			// lineRegex did not match the 2 sub-expressions on this line, so we skip to next loop iteration
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), " \t")
+
+		if summary.Header == "" {
+			if matches := summaryHeaderRegex.FindStringSubmatch(line); matches != nil {
+				summary.Header = strings.TrimSpace(matches[1])
+			}
 			continue
 		}
 
-		uri := line[indices[4]:indices[5]]
-		page := Page{
-			Title:     line[indices[2]:indices[3]],
-			Path:      getHTMLPath(getPageID(route, uri)),
-			TreeLevel: (indices[0] % 3) + 1,
-		}
+		matches := summaryItemRegex.FindStringSubmatch(line)
+		switch {
+		case summaryDividerRegex.MatchString(line):
+			summary.Pages = append(summary.Pages, Page{Section: true, TreeLevel: 1})
+			stack = nil
+		case summarySectionRegex.MatchString(line):
+			title := summarySectionRegex.FindStringSubmatch(line)[1]
+			summary.Pages = append(summary.Pages, Page{Title: title, Section: true, TreeLevel: 1})
+			stack = nil
+		case matches != nil:
+			page, err := parseSummaryItem(route, matches[2])
+			if err != nil {
+				return summary, &SummaryError{Line: lineNum, Message: err.Error()}
+			}
 
-		i := len(summary.Pages) - 1
-		switch page.TreeLevel {
-		case 1:
-			summary.Pages = append(summary.Pages, page)
-		case 2:
-			summary.Pages[i].SubPages = append(summary.Pages[i].SubPages, page)
-		case 3:
-			j := len(summary.Pages[i].SubPages) - 1
-			if j < 0 {
-				return summary, fmt.Errorf("udocs.ParseSummary failed to parse line: %s\n", line)
+			// close the items this one is not nested under, and its previous sibling if it has one
+			indent, dedented := indentWidth(matches[1]), false
+			for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+				stack, dedented = stack[:len(stack)-1], true
+			}
+			if len(stack) > 0 && stack[len(stack)-1].indent == indent {
+				stack = stack[:len(stack)-1]
+			} else if dedented {
+				return summary, &SummaryError{Line: lineNum, Message: "indentation does not line up with any enclosing item"}
 			}
-			summary.Pages[i].SubPages[j].SubPages = append(summary.Pages[i].SubPages[j].SubPages, page)
-		default:
-			return summary, fmt.Errorf("udocs.ParseSummary failed to parse line %s (only 3 levels of pages are supported)\n", line)
+
+			page.TreeLevel = len(stack) + 1
+			siblings := &summary.Pages
+			if len(stack) > 0 {
+				siblings = &stack[len(stack)-1].page.SubPages
+			}
+			*siblings = append(*siblings, page)
+			stack = append(stack, openItem{page: &(*siblings)[len(*siblings)-1], indent: indent})
 		}
 	}
 
@@ -132,9 +223,48 @@ func ParseSummary(route string, data []byte) (Summary, error) {
 		return summary, fmt.Errorf("udocs.ParseSummary had a scanner error: %v\n", err)
 	}
 
+	if summary.Header == "" {
+		return summary, errors.New("udocs.ParseSummary did not find the H1 (header) line (i.e. '# My Guide')")
+	}
+
 	return summary, nil
 }
 
+// parseSummaryItem parses the text of a list item, which is either a link to a page, or the title of
+// a grouping node. Links without a title, as in [](page.md), are titled by the front matter of the page.
+func parseSummaryItem(route, text string) (Page, error) {
+	text = strings.TrimSpace(text)
+	if matches := summaryLinkRegex.FindStringSubmatch(text); matches != nil {
+		page := Page{Title: strings.TrimSpace(matches[1])}
+		if uri := strings.TrimSpace(matches[2]); uri != "" {
+			page.Path = getHTMLPath(getPageID(route, uri))
+		}
+		if page.Title == "" && page.Path == "" {
+			return page, errors.New("entry has neither a title nor a link")
+		}
+		return page, nil
+	}
+
+	if strings.HasPrefix(text, "[") {
+		return Page{}, fmt.Errorf("malformed link %q (expected [Title](path/to/page.md))", text)
+	}
+	return Page{Title: text}, nil
+}
+
+// indentWidth returns the width of the leading whitespace of a line, with tabs advancing to the next
+// multiple of 4 columns.
+func indentWidth(whitespace string) int {
+	width := 0
+	for _, r := range whitespace {
+		if r == '\t' {
+			width += 4 - width%4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
 func IsSummaryFile(filename string) bool {
 	return filepath.Base(filename) == SUMMARY_MD
 }
@@ -1,6 +1,8 @@
 package udocs
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -85,3 +87,107 @@ func comparePages(t *testing.T, expectedPage Page, gotPage Page) {
 		comparePages(t, expectedSubPage, gotSubPage)
 	}
 }
+
+func TestParseSummaryNesting(t *testing.T) {
+	route := "test"
+	given := []byte(`# Test Summary
+
+* [Overview](README.md)
+
+## Part One
+
+- Basics
+  - [Install](basics/install.md)
+    - [Linux](basics/linux.md)
+      - [Debian](basics/debian.md)
+        - [Stretch](basics/stretch.md)
+  - [Configure](basics/configure.md)
+
+---
+
+1. [Reference](reference.md)
+` + "\t1. [API](api.md)\n")
+
+	got, err := ParseSummary(route, given)
+	if err != nil {
+		t.Fatalf("Terminating test due to unexpected error: %v", err)
+	}
+
+	expected := []Page{
+		{Title: "Overview", Path: "/test/index.html", TreeLevel: 1},
+		{Title: "Part One", Section: true, TreeLevel: 1},
+		{Title: "Basics", TreeLevel: 1, SubPages: []Page{
+			{Title: "Install", Path: "/test/basics/install.html", TreeLevel: 2, SubPages: []Page{
+				{Title: "Linux", Path: "/test/basics/linux.html", TreeLevel: 3, SubPages: []Page{
+					{Title: "Debian", Path: "/test/basics/debian.html", TreeLevel: 4, SubPages: []Page{
+						{Title: "Stretch", Path: "/test/basics/stretch.html", TreeLevel: 5}}}}}}},
+			{Title: "Configure", Path: "/test/basics/configure.html", TreeLevel: 2}}},
+		{Section: true, TreeLevel: 1},
+		{Title: "Reference", Path: "/test/reference.html", TreeLevel: 1, SubPages: []Page{
+			{Title: "API", Path: "/test/api.html", TreeLevel: 2}}},
+	}
+
+	if len(expected) != len(got.Pages) {
+		t.Fatalf(errFmt, string(given), expected, got.Pages)
+	}
+	for i := range expected {
+		if expected[i].Section != got.Pages[i].Section {
+			t.Errorf("Page.Section -> expected: %v got: %v", expected[i].Section, got.Pages[i].Section)
+		}
+		if len(expected[i].SubPages) != len(got.Pages[i].SubPages) {
+			t.Errorf(errFmt, expected[i].Title, expected[i].SubPages, got.Pages[i].SubPages)
+			continue
+		}
+		comparePages(t, expected[i], got.Pages[i])
+	}
+}
+
+func TestParseSummaryErrors(t *testing.T) {
+	testCases := []struct {
+		given string
+		line  int
+	}{
+		{given: "# Test\n* [Overview](README.md)\n* [Broken](broken.md\n", line: 3},
+		{given: "# Test\n\n* [A](a.md)\n    * [B](b.md)\n  * [C](c.md)\n", line: 5},
+		{given: "# Test\n* [Overview](README.md)\n* []()\n", line: 3},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseSummary("test", []byte(tc.given))
+		serr, ok := err.(*SummaryError)
+		if !ok {
+			t.Errorf(errFmt, tc.given, "*SummaryError", err)
+			continue
+		}
+		if serr.Line != tc.line {
+			t.Errorf(errFmt, tc.given, tc.line, serr.Line)
+		}
+	}
+}
+
+func TestSidebarTemplate(t *testing.T) {
+	summary, err := ParseSummary("test", []byte("# Test\n* [A](a.md)\n  * B\n    * [C](c.md)\n      * [D](d.md)\n## Part Two\n* [E](e.md)\n"))
+	if err != nil {
+		t.Fatalf("Terminating test due to unexpected error: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	tmpl := MustParseTemplate(nil, DefaultTemplateFiles()...).WithParameter("sidebar", Sidebar{summary})
+	if err := tmpl.Execute(buf, "sidebar", nil); err != nil {
+		t.Fatalf("Terminating test due to failed template execution: %v", err)
+	}
+
+	got := buf.String()
+	for _, expected := range []string{
+		`href='/test/a.html'`,
+		`<span class="nav-docs-group">B</span>`,
+		`<ul class="sub-items level-4">`,
+		`href='/test/d.html'`,
+		`<li class="nav-docs-section">Part Two</li>`,
+		`href='/test/e.html'`,
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf(errFmt, "sidebar template", expected, got)
+		}
+	}
+}
@@ -21,6 +21,7 @@ func DefaultTemplateFiles() []string {
 		"navbar.html",
 		"sidebar.html",
 		"inner.html",
+		"toc.html",
 		"search.html",
 	}
 }
@@ -62,6 +63,11 @@ func (t *Template) WithParameter(k string, v interface{}) *Template {
 	return t
 }
 
+// Has reports whether the template defines a template with the given name, which can be executed.
+func (t *Template) Has(name string) bool {
+	return t.tmpl.Lookup(name) != nil
+}
+
 func (t *Template) Execute(w io.Writer, name string, b []byte) error {
 	html := template.HTML(string(b))
 	data := struct {
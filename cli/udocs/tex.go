@@ -0,0 +1,559 @@
+package udocs
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// texSymbols maps the TeX commands of symbols to the MathML elements they are rendered as, and the
+// characters of those elements.
+var texSymbols = map[string][2]string{
+	// lowercase Greek letters
+	"alpha": {"mi", "α"}, "beta": {"mi", "β"}, "gamma": {"mi", "γ"}, "delta": {"mi", "δ"},
+	"epsilon": {"mi", "ϵ"}, "varepsilon": {"mi", "ε"}, "zeta": {"mi", "ζ"}, "eta": {"mi", "η"},
+	"theta": {"mi", "θ"}, "vartheta": {"mi", "ϑ"}, "iota": {"mi", "ι"}, "kappa": {"mi", "κ"},
+	"lambda": {"mi", "λ"}, "mu": {"mi", "μ"}, "nu": {"mi", "ν"}, "xi": {"mi", "ξ"}, "pi": {"mi", "π"},
+	"varpi": {"mi", "ϖ"}, "rho": {"mi", "ρ"}, "varrho": {"mi", "ϱ"}, "sigma": {"mi", "σ"},
+	"varsigma": {"mi", "ς"}, "tau": {"mi", "τ"}, "upsilon": {"mi", "υ"}, "phi": {"mi", "ϕ"},
+	"varphi": {"mi", "φ"}, "chi": {"mi", "χ"}, "psi": {"mi", "ψ"}, "omega": {"mi", "ω"},
+
+	// uppercase Greek letters, which are upright
+	"Gamma": {"mi", "Γ"}, "Delta": {"mi", "Δ"}, "Theta": {"mi", "Θ"}, "Lambda": {"mi", "Λ"},
+	"Xi": {"mi", "Ξ"}, "Pi": {"mi", "Π"}, "Sigma": {"mi", "Σ"}, "Upsilon": {"mi", "Υ"},
+	"Phi": {"mi", "Φ"}, "Psi": {"mi", "Ψ"}, "Omega": {"mi", "Ω"},
+
+	// other identifiers
+	"infty": {"mi", "∞"}, "partial": {"mi", "∂"}, "nabla": {"mi", "∇"}, "emptyset": {"mi", "∅"},
+	"hbar": {"mi", "ℏ"}, "ell": {"mi", "ℓ"}, "Re": {"mi", "ℜ"}, "Im": {"mi", "ℑ"}, "aleph": {"mi", "ℵ"},
+	"angle": {"mi", "∠"}, "triangle": {"mi", "△"},
+
+	// operators and relations
+	"cdot": {"mo", "⋅"}, "times": {"mo", "×"}, "div": {"mo", "÷"}, "pm": {"mo", "±"}, "mp": {"mo", "∓"},
+	"ast": {"mo", "∗"}, "circ": {"mo", "∘"}, "bullet": {"mo", "∙"}, "oplus": {"mo", "⊕"}, "otimes": {"mo", "⊗"},
+	"leq": {"mo", "≤"}, "le": {"mo", "≤"}, "geq": {"mo", "≥"}, "ge": {"mo", "≥"}, "neq": {"mo", "≠"},
+	"ne": {"mo", "≠"}, "ll": {"mo", "≪"}, "gg": {"mo", "≫"}, "approx": {"mo", "≈"}, "equiv": {"mo", "≡"},
+	"sim": {"mo", "∼"}, "simeq": {"mo", "≃"}, "cong": {"mo", "≅"}, "propto": {"mo", "∝"},
+	"in": {"mo", "∈"}, "notin": {"mo", "∉"}, "ni": {"mo", "∋"}, "subset": {"mo", "⊂"}, "supset": {"mo", "⊃"},
+	"subseteq": {"mo", "⊆"}, "supseteq": {"mo", "⊇"}, "cup": {"mo", "∪"}, "cap": {"mo", "∩"},
+	"setminus": {"mo", "∖"}, "forall": {"mo", "∀"}, "exists": {"mo", "∃"}, "neg": {"mo", "¬"},
+	"land": {"mo", "∧"}, "wedge": {"mo", "∧"}, "lor": {"mo", "∨"}, "vee": {"mo", "∨"},
+	"to": {"mo", "→"}, "rightarrow": {"mo", "→"}, "leftarrow": {"mo", "←"}, "gets": {"mo", "←"},
+	"leftrightarrow": {"mo", "↔"}, "Rightarrow": {"mo", "⇒"}, "Leftarrow": {"mo", "⇐"},
+	"Leftrightarrow": {"mo", "⇔"}, "implies": {"mo", "⟹"}, "iff": {"mo", "⟺"}, "mapsto": {"mo", "↦"},
+	"perp": {"mo", "⊥"}, "parallel": {"mo", "∥"}, "mid": {"mo", "∣"}, "colon": {"mo", ":"},
+	"ldots": {"mo", "…"}, "dots": {"mo", "…"}, "cdots": {"mo", "⋯"}, "vdots": {"mo", "⋮"}, "ddots": {"mo", "⋱"},
+	"prime": {"mo", "′"}, "langle": {"mo", "⟨"}, "rangle": {"mo", "⟩"}, "lfloor": {"mo", "⌊"},
+	"rfloor": {"mo", "⌋"}, "lceil": {"mo", "⌈"}, "rceil": {"mo", "⌉"}, "vert": {"mo", "|"}, "Vert": {"mo", "‖"},
+	"{": {"mo", "{"}, "}": {"mo", "}"}, "|": {"mo", "‖"}, "%": {"mo", "%"}, "$": {"mo", "$"},
+	"#": {"mo", "#"}, "&": {"mo", "&"}, "_": {"mo", "_"},
+}
+
+// texLargeOperators are the operators whose limits are set under and over them.
+var texLargeOperators = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂", "bigoplus": "⨁", "bigotimes": "⨂",
+}
+
+// texIntegrals are the operators whose limits are set as subscripts and superscripts.
+var texIntegrals = map[string]string{
+	"int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+}
+
+// texFunctions are the named functions, set upright. The ones with limits take them under themselves.
+var texFunctions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false, "arcsin": false,
+	"arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false, "log": false, "ln": false,
+	"lg": false, "exp": false, "deg": false, "dim": false, "ker": false, "arg": false, "hom": false,
+	"lim": true, "max": true, "min": true, "sup": true, "inf": true, "det": true, "gcd": true,
+	"Pr": true, "liminf": true, "limsup": true, "argmax": true, "argmin": true,
+}
+
+// texAccents maps the accent commands to the characters set over (or under) their argument.
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "¯", "vec": "→", "overrightarrow": "→",
+	"tilde": "~", "widetilde": "~", "dot": "˙", "ddot": "¨", "underline": "_",
+}
+
+// texFonts maps the font commands to the MathML variants they set their argument in.
+var texFonts = map[string]string{
+	"mathbf": "bold", "boldsymbol": "bold-italic", "mathit": "italic", "mathrm": "normal",
+	"mathbb": "double-struck", "mathcal": "script", "mathfrak": "fraktur", "mathsf": "sans-serif",
+	"mathtt": "monospace",
+}
+
+// texSpaces maps the spacing commands to the widths of the spaces they insert.
+var texSpaces = map[string]string{
+	",": "0.1667em", ":": "0.2222em", ">": "0.2222em", ";": "0.2778em", " ": "0.25em",
+	"quad": "1em", "qquad": "2em", "!": "-0.1667em",
+}
+
+// texMatrices maps the matrix environments to the fences around them.
+var texMatrices = map[string][2]string{
+	"matrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"}, "Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "cases": {"{", ""}, "aligned": {"", ""},
+	"align": {"", ""}, "align*": {"", ""}, "array": {"", ""}, "gathered": {"", ""},
+}
+
+// texToMathML converts a TeX formula into a MathML math element, with the formula itself as an annotation.
+// It supports the commonly used subset of TeX math: scripts, fractions, roots, Greek letters and symbols,
+// functions, accents, fonts, text, spacing, delimiters and matrices.
+func texToMathML(tex string, display bool) (string, error) {
+	p := &texParser{tokens: tokenizeTeX(tex)}
+	body, err := p.parseRow(nil)
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		buf.WriteString(` display="block"`)
+	}
+	buf.WriteString(`><semantics><mrow>`)
+	buf.WriteString(body)
+	buf.WriteString(`</mrow><annotation encoding="application/x-tex">`)
+	buf.WriteString(html.EscapeString(tex))
+	buf.WriteString(`</annotation></semantics></math>`)
+	return buf.String(), nil
+}
+
+// tokenizeTeX splits a TeX formula into commands, as in \frac or \{, and single characters. Whitespace is
+// dropped, except inside the arguments of \text, which the parser reads from the tokens as written.
+func tokenizeTeX(tex string) []string {
+	var tokens []string
+	runes := []rune(tex)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && unicode.IsLetter(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			if j < len(runes) && runes[j] == '*' { // starred environments and commands
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j - 1
+		case r == '\\' && i+1 < len(runes):
+			tokens = append(tokens, string(runes[i:i+2]))
+			i++
+		case unicode.IsSpace(r):
+			tokens = append(tokens, " ")
+			for i+1 < len(runes) && unicode.IsSpace(runes[i+1]) {
+				i++
+			}
+		default:
+			tokens = append(tokens, string(r))
+		}
+	}
+	return tokens
+}
+
+type texParser struct {
+	tokens  []string
+	pos     int
+	variant string // the mathvariant of identifiers, as set by a font command
+}
+
+func (p *texParser) peek() string {
+	p.skipSpace()
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *texParser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *texParser) skipSpace() {
+	for p.pos < len(p.tokens) && p.tokens[p.pos] == " " {
+		p.pos++
+	}
+}
+
+// parseRow parses elements up to the end of the formula, or the first token for which stop returns true,
+// which is not consumed.
+func (p *texParser) parseRow(stop func(string) bool) (string, error) {
+	var buf bytes.Buffer
+	for {
+		t := p.peek()
+		if t == "" || (stop != nil && stop(t)) {
+			return buf.String(), nil
+		}
+		if t == "}" {
+			return "", fmt.Errorf("unbalanced }")
+		}
+		elem, err := p.parseScripted()
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(elem)
+	}
+}
+
+// parseScripted parses an element along with its subscript and superscript, if any.
+func (p *texParser) parseScripted() (string, error) {
+	t := p.peek()
+	base, err := p.parseAtom()
+	if err != nil {
+		return "", err
+	}
+	_, large := texLargeOperators[strings.TrimPrefix(t, "\\")]
+	if limits, ok := texFunctions[strings.TrimPrefix(t, "\\")]; ok && limits {
+		large = true
+	}
+
+	var sub, sup string
+	for {
+		switch p.peek() {
+		case "_":
+			p.next()
+			if sub, err = p.parseArgument(); err != nil {
+				return "", err
+			}
+			continue
+		case "^":
+			p.next()
+			if sup, err = p.parseArgument(); err != nil {
+				return "", err
+			}
+			continue
+		case "'":
+			p.next()
+			sup += "<mo>′</mo>"
+			continue
+		}
+		break
+	}
+
+	under, over, both := "msub", "msup", "msubsup"
+	if large {
+		under, over, both = "munder", "mover", "munderover"
+	}
+	switch {
+	case sub != "" && sup != "":
+		return fmt.Sprintf("<%s>%s%s%s</%s>", both, base, wrapRow(sub), wrapRow(sup), both), nil
+	case sub != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", under, base, wrapRow(sub), under), nil
+	case sup != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", over, base, wrapRow(sup), over), nil
+	}
+	return base, nil
+}
+
+// parseArgument parses the argument of a command or script: a braced group, or a single element.
+func (p *texParser) parseArgument() (string, error) {
+	switch t := p.peek(); t {
+	case "":
+		return "", fmt.Errorf("missing argument")
+	case "{":
+		p.next()
+		row, err := p.parseRow(func(t string) bool { return t == "}" })
+		if err != nil {
+			return "", err
+		}
+		if p.next() != "}" {
+			return "", fmt.Errorf("unbalanced {")
+		}
+		return row, nil
+	}
+	return p.parseAtom()
+}
+
+// parseText reads the braced argument of \text as written, spaces included.
+func (p *texParser) parseText() (string, error) {
+	if p.next() != "{" {
+		return "", fmt.Errorf("missing argument of \\text")
+	}
+	var buf bytes.Buffer
+	for depth := 0; p.pos < len(p.tokens); p.pos++ {
+		t := p.tokens[p.pos]
+		switch {
+		case t == "{":
+			depth++
+		case t == "}" && depth == 0:
+			p.pos++
+			return buf.String(), nil
+		case t == "}":
+			depth--
+		case len(t) == 2 && t[0] == '\\': // escaped characters, as in \{ or \$
+			t = t[1:]
+		}
+		buf.WriteString(t)
+	}
+	return "", fmt.Errorf("unbalanced {")
+}
+
+func (p *texParser) parseAtom() (string, error) {
+	t := p.next()
+	switch {
+	case t == "{":
+		p.pos--
+		row, err := p.parseArgument()
+		return wrapRow(row), err
+	case t == "&" || t == "\\\\":
+		return "", fmt.Errorf("%s outside of a matrix", t)
+	case len(t) == 1 && unicode.IsDigit(rune(t[0])) || t == ".":
+		// numbers run on over their digits and decimal point
+		number := t
+		for p.pos < len(p.tokens) && (p.tokens[p.pos] == "." || len(p.tokens[p.pos]) == 1 && unicode.IsDigit(rune(p.tokens[p.pos][0]))) {
+			number += p.tokens[p.pos]
+			p.pos++
+		}
+		return "<mn>" + number + "</mn>", nil
+	case !strings.HasPrefix(t, "\\"):
+		r := []rune(t)[0]
+		if unicode.IsLetter(r) {
+			return p.identifier(t), nil
+		}
+		if t == "-" {
+			t = "−"
+		}
+		return "<mo>" + html.EscapeString(t) + "</mo>", nil
+	}
+
+	name := t[1:]
+	if sym, ok := texSymbols[name]; ok {
+		if sym[0] == "mi" {
+			return p.identifier(sym[1]), nil
+		}
+		return "<mo>" + html.EscapeString(sym[1]) + "</mo>", nil
+	}
+	if op, ok := texLargeOperators[name]; ok {
+		return `<mo movablelimits="true">` + op + "</mo>", nil
+	}
+	if op, ok := texIntegrals[name]; ok {
+		return "<mo>" + op + "</mo>", nil
+	}
+	if _, ok := texFunctions[name]; ok {
+		return "<mi>" + name + "</mi>", nil
+	}
+	if width, ok := texSpaces[name]; ok {
+		return `<mspace width="` + width + `"></mspace>`, nil
+	}
+	if accent, ok := texAccents[name]; ok {
+		arg, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		if name == "underline" {
+			return `<munder accentunder="true">` + wrapRow(arg) + "<mo>" + accent + "</mo></munder>", nil
+		}
+		return `<mover accent="true">` + wrapRow(arg) + "<mo>" + accent + "</mo></mover>", nil
+	}
+	if variant, ok := texFonts[name]; ok {
+		outer := p.variant
+		p.variant = variant
+		arg, err := p.parseArgument()
+		p.variant = outer
+		return wrapRow(arg), err
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "binom":
+		num, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		den, err := p.parseArgument()
+		if err != nil {
+			return "", err
+		}
+		if name == "binom" {
+			return `<mrow><mo>(</mo><mfrac linethickness="0">` + wrapRow(num) + wrapRow(den) + `</mfrac><mo>)</mo></mrow>`, nil
+		}
+		return "<mfrac>" + wrapRow(num) + wrapRow(den) + "</mfrac>", nil
+	case "sqrt":
+		if p.peek() == "[" {
+			p.next()
+			index, err := p.parseRow(func(t string) bool { return t == "]" })
+			if err != nil {
+				return "", err
+			}
+			p.next()
+			radicand, err := p.parseArgument()
+			if err != nil {
+				return "", err
+			}
+			return "<mroot>" + wrapRow(radicand) + wrapRow(index) + "</mroot>", nil
+		}
+		radicand, err := p.parseArgument()
+		return "<msqrt>" + radicand + "</msqrt>", err
+	case "text", "textrm", "textit", "textbf", "mbox", "operatorname":
+		text, err := p.parseText()
+		if err != nil {
+			return "", err
+		}
+		if name == "operatorname" {
+			return "<mi>" + html.EscapeString(text) + "</mi>", nil
+		}
+		return "<mtext>" + html.EscapeString(text) + "</mtext>", nil
+	case "left":
+		open := p.delimiter()
+		row, err := p.parseRow(func(t string) bool { return t == "\\right" })
+		if err != nil {
+			return "", err
+		}
+		if p.next() != "\\right" {
+			return "", fmt.Errorf("\\left without \\right")
+		}
+		return "<mrow>" + fence(open) + row + fence(p.delimiter()) + "</mrow>", nil
+	case "right":
+		return "", fmt.Errorf("\\right without \\left")
+	case "big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr":
+		return fence(p.delimiter()), nil
+	case "begin":
+		return p.parseEnvironment()
+	}
+	return "", fmt.Errorf("unsupported command %s", t)
+}
+
+// parseEnvironment parses a matrix environment, as in \begin{pmatrix} a & b \\ c & d \end{pmatrix}.
+func (p *texParser) parseEnvironment() (string, error) {
+	env, err := p.parseText()
+	if err != nil {
+		return "", err
+	}
+	fences, ok := texMatrices[env]
+	if !ok {
+		return "", fmt.Errorf("unsupported environment %s", env)
+	}
+	if env == "array" && p.peek() == "{" { // column alignments are ignored
+		if _, err := p.parseText(); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<mtable>")
+	for done := false; !done; {
+		buf.WriteString("<mtr>")
+		for {
+			cell, err := p.parseRow(func(t string) bool { return t == "&" || t == "\\\\" || t == "\\end" })
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString("<mtd>" + wrapRow(cell) + "</mtd>")
+			t := p.next()
+			if t == "&" {
+				continue
+			}
+			if t == "\\end" {
+				if end, err := p.parseText(); err != nil || end != env {
+					return "", fmt.Errorf("\\begin{%s} ended by \\end{%s}", env, end)
+				}
+				done = true
+			} else if t == "" {
+				return "", fmt.Errorf("\\begin{%s} without \\end{%s}", env, env)
+			}
+			break
+		}
+		buf.WriteString("</mtr>")
+	}
+	buf.WriteString("</mtable>")
+
+	if fences[0] == "" && fences[1] == "" {
+		return buf.String(), nil
+	}
+	return "<mrow>" + fence(fences[0]) + buf.String() + fence(fences[1]) + "</mrow>", nil
+}
+
+// delimiter reads the delimiter after \left, \right or \big, where "." is no delimiter.
+func (p *texParser) delimiter() string {
+	t := p.next()
+	if t == "." {
+		return ""
+	}
+	if sym, ok := texSymbols[strings.TrimPrefix(t, "\\")]; ok && strings.HasPrefix(t, "\\") {
+		return sym[1]
+	}
+	return t
+}
+
+func (p *texParser) identifier(name string) string {
+	r := []rune(name)
+	switch {
+	case p.variant == "normal" || len(r) == 1 && unicode.IsUpper(r[0]) && r[0] > unicode.MaxASCII:
+		// upright identifiers, such as uppercase Greek letters
+		return `<mi mathvariant="normal">` + html.EscapeString(name) + "</mi>"
+	case p.variant != "" && len(r) == 1:
+		if styled, ok := styledLetter(p.variant, r[0]); ok {
+			return "<mi>" + styled + "</mi>"
+		}
+	}
+	return "<mi>" + html.EscapeString(name) + "</mi>"
+}
+
+// mathAlphabets maps the variants of the font commands to the code points of their A and a in the
+// Mathematical Alphanumeric Symbols block, and the letters of the variant that are found elsewhere.
+var mathAlphabets = map[string]struct {
+	upper, lower rune
+	exceptions   map[rune]rune
+}{
+	"bold":          {0x1D400, 0x1D41A, nil},
+	"bold-italic":   {0x1D468, 0x1D482, nil},
+	"script":        {0x1D49C, 0x1D4B6, map[rune]rune{'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ', 'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ'}},
+	"fraktur":       {0x1D504, 0x1D51E, map[rune]rune{'C': 'ℭ', 'H': 'ℌ', 'I': 'ℑ', 'R': 'ℜ', 'Z': 'ℨ'}},
+	"double-struck": {0x1D538, 0x1D552, map[rune]rune{'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ'}},
+	"sans-serif":    {0x1D5A0, 0x1D5BA, nil},
+	"monospace":     {0x1D670, 0x1D68A, nil},
+}
+
+// styledLetter returns a Latin letter in the given variant, since browsers ignore the mathvariant
+// attribute of identifiers for all but upright ones.
+func styledLetter(variant string, r rune) (string, bool) {
+	alphabet, ok := mathAlphabets[variant]
+	if !ok {
+		return "", false
+	}
+	if styled, ok := alphabet.exceptions[r]; ok {
+		return string(styled), true
+	}
+	switch {
+	case r >= 'A' && r <= 'Z':
+		return string(alphabet.upper + r - 'A'), true
+	case r >= 'a' && r <= 'z':
+		return string(alphabet.lower + r - 'a'), true
+	}
+	return "", false
+}
+
+func fence(delimiter string) string {
+	if delimiter == "" {
+		return ""
+	}
+	return `<mo fence="true">` + html.EscapeString(delimiter) + "</mo>"
+}
+
+// wrapRow wraps the elements of a script, fraction or root in an mrow, unless it is a single element.
+func wrapRow(elems string) string {
+	depth, top := 0, 0
+	for i := 0; i < len(elems); i++ {
+		switch {
+		case elems[i] != '<':
+		case strings.HasPrefix(elems[i:], "</"):
+			depth--
+		default:
+			if depth == 0 {
+				top++
+			}
+			depth++
+		}
+	}
+	if top == 1 {
+		return elems
+	}
+	return "<mrow>" + elems + "</mrow>"
+}
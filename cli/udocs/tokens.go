@@ -0,0 +1,116 @@
+package udocs
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+// ALL_ROUTES scopes an API token to every route on the server.
+const ALL_ROUTES = "*"
+
+// APIToken authorizes publishing and destroying the guides of its routes through the /api endpoints.
+// Only a hash of the secret is stored; the secret itself is shown once, when the token is minted.
+type APIToken struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Hash    string    `json:"hash"`
+	Routes  []string  `json:"routes"`
+	Created time.Time `json:"created"`
+}
+
+type Tokens []APIToken
+
+// LoadTokens returns the tokens stored in dao. No tokens are returned until the first one is minted, but
+// failing to read them, as when the backend is unreachable, is an error.
+func LoadTokens(dao storage.Dao) (Tokens, error) {
+	tokens := make(Tokens, 0)
+
+	data, err := dao.Fetch(TOKENS_JSON)
+	if storage.IsNotFound(err) {
+		return tokens, nil
+	} else if err != nil {
+		return tokens, fmt.Errorf("udocs.LoadTokens: %v", err)
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return tokens, fmt.Errorf("udocs.LoadTokens: %v", err)
+	}
+
+	return tokens, nil
+}
+
+func (t Tokens) Save(dao storage.Dao) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return dao.Insert(TOKENS_JSON, data)
+}
+
+// Mint adds a new token scoped to routes, and returns it along with its secret.
+func (t Tokens) Mint(name string, routes []string) (Tokens, string, error) {
+	if len(routes) == 0 {
+		return t, "", fmt.Errorf("udocs.Mint: a token must be scoped to at least one route")
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return t, "", fmt.Errorf("udocs.Mint: %v", err)
+	}
+	key, err := randomHex(24)
+	if err != nil {
+		return t, "", fmt.Errorf("udocs.Mint: %v", err)
+	}
+	secret := id + "." + key
+
+	token := APIToken{
+		ID:      id,
+		Name:    name,
+		Hash:    hashContent([]byte(secret)),
+		Routes:  routes,
+		Created: time.Now().UTC(),
+	}
+	return append(t, token), secret, nil
+}
+
+// Revoke removes the token with the given id.
+func (t Tokens) Revoke(id string) (Tokens, error) {
+	for i, token := range t {
+		if token.ID == id {
+			return append(t[:i], t[i+1:]...), nil
+		}
+	}
+	return t, fmt.Errorf("udocs.Revoke: no token with id %q", id)
+}
+
+// Authorize reports whether secret is a token scoped to route.
+func (t Tokens) Authorize(secret, route string) bool {
+	sum := sha256.Sum256([]byte(secret))
+	for _, token := range t {
+		hash, err := hex.DecodeString(token.Hash)
+		if err != nil || subtle.ConstantTimeCompare(hash, sum[:]) != 1 {
+			continue
+		}
+		for _, r := range token.Routes {
+			if r == ALL_ROUTES || strings.Trim(r, "/") == route {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,84 @@
+package udocs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+func TestTokens(t *testing.T) {
+	dao := storage.NewMockDao("")
+
+	tokens, err := LoadTokens(dao)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed token load: %v", err)
+	}
+	if len(tokens) != 0 {
+		t.Fatalf("expected no tokens before minting, got %d", len(tokens))
+	}
+
+	if _, _, err := tokens.Mint("nobody", nil); err == nil {
+		t.Error("expected an error minting a token without routes")
+	}
+
+	tokens, alpha, err := tokens.Mint("alpha team", []string{"alpha", "/beta"})
+	if err != nil {
+		t.Fatalf("Terminating test due to failed mint: %v", err)
+	}
+	tokens, admin, err := tokens.Mint("admin", []string{ALL_ROUTES})
+	if err != nil {
+		t.Fatalf("Terminating test due to failed mint: %v", err)
+	}
+	if err := tokens.Save(dao); err != nil {
+		t.Fatalf("Terminating test due to failed save: %v", err)
+	}
+
+	if tokens, err = LoadTokens(dao); err != nil {
+		t.Fatalf("Terminating test due to failed token load: %v", err)
+	}
+	if _, err := LoadTokens(unreachableDao{dao}); err == nil {
+		t.Error("expected an error loading tokens from an unreachable dao")
+	}
+	for _, token := range tokens {
+		if token.Hash == alpha || token.Hash == admin {
+			t.Errorf("token %s stored its secret in plain text", token.ID)
+		}
+	}
+
+	cases := []struct {
+		secret, route string
+		expected      bool
+	}{
+		{alpha, "alpha", true},
+		{alpha, "beta", true},
+		{alpha, "gamma", false},
+		{admin, "gamma", true},
+		{alpha + "x", "alpha", false},
+		{"", "alpha", false},
+	}
+	for _, c := range cases {
+		if got := tokens.Authorize(c.secret, c.route); got != c.expected {
+			t.Errorf(errFmt, c.secret+" "+c.route, c.expected, got)
+		}
+	}
+
+	if tokens, err = tokens.Revoke(tokens[0].ID); err != nil {
+		t.Fatalf("Terminating test due to failed revoke: %v", err)
+	}
+	if tokens.Authorize(alpha, "alpha") {
+		t.Error("expected a revoked token to be rejected")
+	}
+	if _, err := tokens.Revoke("missing"); err == nil {
+		t.Error("expected an error revoking an unknown token")
+	}
+}
+
+// unreachableDao fails every Fetch, as a backend that is down does.
+type unreachableDao struct {
+	storage.Dao
+}
+
+func (unreachableDao) Fetch(id string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
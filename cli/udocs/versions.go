@@ -0,0 +1,203 @@
+package udocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+const (
+	// LATEST_VERSION aliases the latest version of a versioned guide, as in /:route/latest/*.
+	LATEST_VERSION = "latest"
+	// SUMMARY_JSON holds the parsed summary of a single version of a guide, as in /:route/:version/summary.json.
+	SUMMARY_JSON = "summary.json"
+)
+
+var versionRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateVersion checks that version can be used as a path segment of the routes of a guide.
+func ValidateVersion(version string) error {
+	if version == LATEST_VERSION {
+		return fmt.Errorf("version %q is reserved", LATEST_VERSION)
+	}
+	if !versionRegex.MatchString(version) {
+		return fmt.Errorf("version %q may only contain letters, digits, '.', '-' and '_'", version)
+	}
+	return nil
+}
+
+// LoadVersionSummary loads the summary of a single version of a guide.
+func LoadVersionSummary(route, version string, dao storage.Dao) (Summary, error) {
+	var summary Summary
+
+	data, err := dao.Fetch(getVersionSummaryID(route, version))
+	if err != nil {
+		return summary, err
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return summary, err
+	}
+
+	return summary, nil
+}
+
+func (s Summary) saveVersion(dao storage.Store) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return dao.Insert(getVersionSummaryID(s.Route, s.Version), data)
+}
+
+// Prefix returns the path under which the pages of the summary are stored: the route of the guide,
+// followed by its version for versioned guides.
+func (s Summary) Prefix() string {
+	return filepath.Join(s.Route, s.Version)
+}
+
+// HasVersion reports whether version is one of the published versions of the guide.
+func (s Summary) HasVersion(version string) bool {
+	for _, v := range s.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Latest returns the latest published version of the guide, or "" if the guide is not versioned.
+func (s Summary) Latest() string {
+	if len(s.Versions) == 0 {
+		return ""
+	}
+	return s.Versions[0]
+}
+
+// ResolveVersion returns the path of the given page, with the "latest" alias of a versioned guide
+// replaced by its latest version, along with the route and the version of the page. The version is
+// empty for pages of guides that are not versioned.
+func (s Sidebar) ResolveVersion(path string) (string, string, string) {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(segments) < 2 {
+		return path, segments[0], ""
+	}
+
+	for _, summary := range s {
+		if summary.Route != segments[0] || len(summary.Versions) == 0 {
+			continue
+		}
+		if segments[1] == LATEST_VERSION {
+			segments[1] = summary.Latest()
+		} else if !summary.HasVersion(segments[1]) {
+			break
+		}
+		return "/" + strings.Join(segments, "/"), segments[0], segments[1]
+	}
+	return path, segments[0], ""
+}
+
+// WithVersion returns a copy of the sidebar in which the entry of the given route shows the pages of
+// the given version of the guide, rather than those of its latest version.
+func (s Sidebar) WithVersion(route, version string, dao storage.Dao) (Sidebar, error) {
+	sidebar := make(Sidebar, len(s))
+	copy(sidebar, s)
+
+	for i, summary := range sidebar {
+		if summary.Route != route || summary.Version == version || version == "" {
+			continue
+		}
+		versioned, err := LoadVersionSummary(route, version, dao)
+		if err != nil {
+			return s, err
+		}
+		versioned.Versions = summary.Versions
+		sidebar[i] = versioned
+	}
+	return sidebar, nil
+}
+
+// Guide returns the summary of the versioned guide served from route, or nil when the guide is not
+// versioned, for use by the version switcher of the navbar.
+func (s Sidebar) Guide(route string) *Summary {
+	for i := range s {
+		if s[i].Route == route && len(s[i].Versions) > 0 {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+// Summaries returns the summaries of every guide in the sidebar, including every published version of
+// versioned guides.
+func (s Sidebar) Summaries(dao storage.Dao) []Summary {
+	summaries := make([]Summary, 0, len(s))
+	for _, summary := range s {
+		if summary.Version == "" {
+			summaries = append(summaries, summary)
+		}
+		for _, version := range summary.Versions {
+			if version == summary.Version {
+				summaries = append(summaries, summary)
+			} else if versioned, err := LoadVersionSummary(summary.Route, version, dao); err == nil {
+				summaries = append(summaries, versioned)
+			}
+		}
+	}
+	return summaries
+}
+
+// sortVersions sorts versions from the latest to the earliest. Versions are compared segment by
+// segment, numerically where both segments are numbers, so that "v1.10" comes after "v1.9".
+func sortVersions(versions []string) {
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+}
+
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(strings.TrimPrefix(strings.ToLower(v), "v"), func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		switch {
+		case aerr == nil && berr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aerr != nil || berr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+
+	// a trailing label marks a pre-release, as in "1.0-rc1", which comes before the release itself
+	switch {
+	case len(as) > len(bs):
+		if _, err := strconv.Atoi(as[len(bs)]); err != nil {
+			return -1
+		}
+		return 1
+	case len(as) < len(bs):
+		if _, err := strconv.Atoi(bs[len(as)]); err != nil {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+func getVersionSummaryID(route, version string) string {
+	return filepath.Join("/", route, version, SUMMARY_JSON)
+}
@@ -0,0 +1,171 @@
+package udocs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/seanawilliams/udocs/cli/storage"
+)
+
+func TestSortVersions(t *testing.T) {
+	versions := []string{"v1.9", "1.0-rc1", "v1.10", "1.0", "v2"}
+	expected := []string{"v2", "v1.10", "v1.9", "1.0", "1.0-rc1"}
+
+	sortVersions(versions)
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf(errFmt, "v1.9, 1.0-rc1, v1.10, 1.0, v2", expected, versions)
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	testCases := map[string]bool{
+		"v1.2.3":       true,
+		"2017_06-beta": true,
+		LATEST_VERSION: false,
+		"../v1":        false,
+		"v1/v2":        false,
+		"":             false,
+	}
+	for version, valid := range testCases {
+		if err := ValidateVersion(version); (err == nil) != valid {
+			t.Errorf(errFmt, version, valid, err)
+		}
+	}
+}
+
+func TestBuildVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dao := storage.NewMockDao("/tmp")
+	for _, version := range []string{"v2", "v1"} {
+		files := map[string]string{
+			README_MD:  "# Test\nVersion " + version,
+			SUMMARY_MD: "# Test\n* [Overview](README.md)",
+		}
+		for name, content := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Terminating test due to failed file write: %v", err)
+			}
+		}
+		if _, err := BuildVersion("test-route", version, dir, dao); err != nil {
+			t.Fatalf("BuildVersion(test-route, %s, %s, *MockDao) => %v", version, dir, err)
+		}
+	}
+
+	sidebar, err := LoadSidebar(dao)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed sidebar load: %v", err)
+	}
+	if len(sidebar) != 1 || sidebar[0].Version != "v2" || !reflect.DeepEqual(sidebar[0].Versions, []string{"v2", "v1"}) {
+		t.Fatalf("expected the sidebar to show v2 of versions [v2 v1], got: %+v", sidebar)
+	}
+	if path := sidebar[0].Pages[0].Path; path != "/test-route/v2/index.html" {
+		t.Errorf(errFmt, "sidebar path", "/test-route/v2/index.html", path)
+	}
+
+	testCases := []struct {
+		path, expected, version string
+	}{
+		{"/test-route/latest/index.html", "/test-route/v2/index.html", "v2"},
+		{"/test-route/v1/index.html", "/test-route/v1/index.html", "v1"},
+		{"/test-route/v3/index.html", "/test-route/v3/index.html", ""},
+		{"/other/latest/index.html", "/other/latest/index.html", ""},
+	}
+	for _, tc := range testCases {
+		if path, _, version := sidebar.ResolveVersion(tc.path); path != tc.expected || version != tc.version {
+			t.Errorf(errFmt, tc.path, tc.expected+" "+tc.version, path+" "+version)
+		}
+	}
+
+	versioned, err := sidebar.WithVersion("test-route", "v1", dao)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed sidebar load: %v", err)
+	}
+	if path := versioned[0].Pages[0].Path; path != "/test-route/v1/index.html" {
+		t.Errorf(errFmt, "v1 sidebar path", "/test-route/v1/index.html", path)
+	}
+	if sidebar[0].Version != "v2" {
+		t.Error("expected WithVersion to leave the original sidebar unchanged")
+	}
+
+	if summaries := sidebar.Summaries(dao); len(summaries) != 2 {
+		t.Errorf(errFmt, "Summaries", 2, len(summaries))
+	}
+}
+
+func TestBuildVersionsConcurrently(t *testing.T) {
+	dir, err := ioutil.TempDir("", "udocs")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed temp dir creation: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dao, err := storage.NewFileSystemDao(filepath.Join(dir, "data"), 0755, "")
+	if err != nil {
+		t.Fatalf("Terminating test due to failed dao creation: %v", err)
+	}
+
+	// rebuilds of the guide itself carry its versions over, so they must not drop the versions built meanwhile
+	type build struct {
+		route, version string
+	}
+	builds := []build{{"other-route", ""}}
+	var versions []string
+	for i := 1; i <= 8; i++ {
+		versions = append([]string{"v" + strconv.Itoa(i)}, versions...)
+		builds = append(builds, build{"test-route", ""}, build{"test-route", versions[0]})
+	}
+	var wg sync.WaitGroup
+	for i, build := range builds {
+		src := filepath.Join(dir, "src", strconv.Itoa(i))
+		files := map[string]string{
+			README_MD:  "# Test\nBuild " + strconv.Itoa(i),
+			SUMMARY_MD: "# Test\n* [Overview](README.md)",
+		}
+		for name, content := range files {
+			if err := os.MkdirAll(src, 0755); err != nil {
+				t.Fatalf("Terminating test due to failed dir creation: %v", err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Terminating test due to failed file write: %v", err)
+			}
+		}
+
+		wg.Add(1)
+		go func(route, version, src string) {
+			defer wg.Done()
+			if _, err := BuildVersion(route, version, src, dao); err != nil {
+				t.Errorf("BuildVersion(%s, %s, %s, *FileSystemDao) => %v", route, version, src, err)
+			}
+		}(build.route, build.version, src)
+	}
+	wg.Wait()
+
+	sidebar, err := LoadSidebar(dao)
+	if err != nil {
+		t.Fatalf("Terminating test due to failed sidebar load: %v", err)
+	}
+	if len(sidebar) != 2 {
+		t.Fatalf(errFmt, "sidebar routes", 2, len(sidebar))
+	}
+	for _, summary := range sidebar {
+		if summary.Route == "test-route" && !reflect.DeepEqual(summary.Versions, versions) {
+			t.Errorf(errFmt, "test-route versions", versions, summary.Versions)
+		}
+	}
+	for _, build := range builds {
+		id := "/" + filepath.Join(build.route, build.version, "index.html")
+		if _, err := dao.Fetch(id); err != nil {
+			t.Errorf(errFmt, id, nil, err)
+		}
+	}
+}
@@ -13,8 +13,11 @@ func main() {
 		cmd.Destroy(),
 		cmd.Env(),
 		cmd.Publish(),
+		cmd.Reindex(),
+		cmd.Rollback(),
 		cmd.Serve(),
 		cmd.Tar(),
+		cmd.Token(),
 		cmd.Validate(),
 		cmd.Version(),
 	)
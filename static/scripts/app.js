@@ -4,6 +4,7 @@ $(document).ready(function() {
     listenOnSidebarClick();
     listenOnAnchorClick();
     listenOnSearchSubmit();
+    listenOnCopyClick();
     listenOnPopstate();
     goToHash();
 });
@@ -45,7 +46,12 @@ function listenOnAnchorClick() {
             path = url.replace(window.location.origin, ""),
             title = '';
 
-        if (isRemoteURL(url.toLowerCase()) || isMediaURL(url.toLowerCase())) {
+        if (isStatic() || isRemoteURL(url.toLowerCase()) || isMediaURL(url.toLowerCase()) || isSearchURL(path)) {
+            return true;
+        }
+
+        // switching versions reloads the whole page, since the sidebar differs between versions
+        if ($(this).closest('.version-switcher').length) {
             return true;
         }
 
@@ -63,6 +69,9 @@ function listenOnAnchorClick() {
 
 function listenOnSearchSubmit() {
     $('#navbar-search').submit(function(event) {
+        if (isStatic()) {
+            return true; // statically exported guides search on their own search page
+        }
         var path = '/search?q=' + document.getElementById('search-input').value;
         history.pushState({title: 'Search', path: path}, 'Search', path);
         search(path, false);
@@ -70,6 +79,29 @@ function listenOnSearchSubmit() {
     });
 }
 
+function listenOnCopyClick() {
+    $(document).on('click', '.code-copy', function() {
+        var button = $(this);
+        var code = button.siblings('pre').find('code').clone();
+        code.find('.ln').remove(); // leave line numbers out of the copy
+        copyText(code.text()).then(function() {
+            button.addClass('is-copied');
+            setTimeout(function() { button.removeClass('is-copied'); }, 1500);
+        });
+    });
+}
+
+function copyText(text) {
+    if (navigator.clipboard && window.isSecureContext) {
+        return navigator.clipboard.writeText(text);
+    }
+    var textarea = $('<textarea>').val(text).css({position: 'fixed', opacity: 0}).appendTo('body');
+    textarea.select();
+    document.execCommand('copy');
+    textarea.remove();
+    return $.Deferred().resolve().promise();
+}
+
 function listenOnPopstate() {
     $(window).on('popstate', function (e) {
         var initialPop = !window.popped && location.href == window.initialURL;
@@ -95,6 +127,9 @@ function listenOnPopstate() {
 }
 
 function goToHash() {
+    if (isStatic()) {
+        return false;
+    }
     if (location.hash) {
         if (navigator.userAgent.indexOf('AppleWebKit') == -1) {
             window.location.hash = location.hash;
@@ -120,6 +155,10 @@ function goToHash() {
     return false;
 };
 
+function isStatic() {
+    return $('body').data('static') === true;
+}
+
 function isRemoteURL(url) {
     return url.indexOf(window.location.origin.toLowerCase()) == -1;
 }
@@ -189,14 +228,15 @@ function reportData(title, path) {
         dataType: 'html',
         success: function(res) {
             $('#inner').html(res);
-            Prism.highlightAll();
-            if (isAnchorTagURL(path)) {
-                $('.anchor[name=' + path.split('#')[1] + ']')[0].scrollIntoView();
+            var heading = isAnchorTagURL(path) ? document.getElementById(path.split('#')[1]) : null;
+            if (heading) {
+                heading.scrollIntoView();
             } else {
                 $('#inner').scrollTop(0);
             }
         }
     });
+    updateTOC(path);
     if (title === undefined) {
         document.title = "UDocs"
     } else {
@@ -205,6 +245,26 @@ function reportData(title, path) {
     setSidebar(path.split('#')[0]);
 }
 
+function updateTOC(path) {
+    $.ajax({
+        method: "GET",
+        url: path.split('#')[0] + '?toc=json',
+        dataType: 'json',
+        success: function(toc) {
+            var list = $('#page-toc ul').empty();
+            (toc || []).forEach(function(entry) {
+                var link = $('<a>').attr('href', '#' + entry.id).attr('title', entry.title).text(entry.title);
+                list.append($('<li>').addClass('toc-level-' + entry.level).append(link));
+            });
+            $('#page-toc').toggleClass('is-empty', !toc || toc.length === 0);
+        },
+        error: function() {
+            $('#page-toc ul').empty();
+            $('#page-toc').addClass('is-empty');
+        }
+    });
+}
+
 var isOpera = (!!window.opr && !!opr.addons) || !!window.opera || navigator.userAgent.indexOf(' OPR/') >= 0;
 var isFirefox = typeof InstallTrigger !== 'undefined';
 var isSafari = Object.prototype.toString.call(window.HTMLElement).indexOf('Constructor') > 0;
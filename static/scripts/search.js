@@ -0,0 +1,71 @@
+// search.js implements client-side search for statically exported guides (see `udocs build --static`).
+$(document).ready(function() {
+    var results = $('#static-search-results');
+    var phrase = getQueryParameter('q');
+    $('#search-input').val(phrase);
+
+    $.getJSON(results.data('index'), function(index) {
+        var matches = searchIndex(index, phrase);
+        results.append($('<div class="row"><h5>' + matches.length + ' matches</h5><hr></div>'));
+        matches.forEach(function(match) {
+            var row = $('<div class="row"></div>');
+            row.append($('<h4 style="margin-bottom: 0.25em;"></h4>').append(
+                $('<a></a>').attr('href', match.entry.url).attr('title', match.entry.title).text(match.entry.title)));
+            row.append($('<p style="font-size: 13px;"></p>').text(snippet(match.entry.body, match.terms)));
+            results.append(row);
+        });
+    });
+});
+
+function getQueryParameter(name) {
+    var params = window.location.search.substring(1).split('&');
+    for (var i = 0; i < params.length; i++) {
+        var pair = params[i].split('=');
+        if (decodeURIComponent(pair[0]) === name && pair.length > 1) {
+            return decodeURIComponent(pair[1].replace(/\+/g, ' '));
+        }
+    }
+    return '';
+}
+
+function searchIndex(index, phrase) {
+    var terms = phrase.toLowerCase().split(/\s+/).filter(function(term) { return term.length > 0; });
+    if (terms.length === 0) {
+        return [];
+    }
+
+    var matches = [];
+    index.forEach(function(entry) {
+        var title = entry.title.toLowerCase(), body = entry.body.toLowerCase(), score = 0;
+        for (var i = 0; i < terms.length; i++) {
+            var found = false;
+            if (title.indexOf(terms[i]) >= 0) {
+                score += 10;
+                found = true;
+            }
+            var count = body.split(terms[i]).length - 1;
+            if (count > 0) {
+                score += count;
+                found = true;
+            }
+            if (!found) {
+                return; // every term must match
+            }
+        }
+        matches.push({entry: entry, score: score, terms: terms});
+    });
+
+    return matches.sort(function(a, b) { return b.score - a.score; });
+}
+
+function snippet(body, terms) {
+    var lower = body.toLowerCase(), start = 0;
+    for (var i = 0; i < terms.length; i++) {
+        var at = lower.indexOf(terms[i]);
+        if (at >= 0) {
+            start = Math.max(0, at - 80);
+            break;
+        }
+    }
+    return (start > 0 ? '...' : '') + body.substring(start, start + 240) + '...';
+}